@@ -4,19 +4,31 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"github.com/serozhenka/shary/internal/config"
 	"github.com/serozhenka/shary/internal/database"
 	"github.com/serozhenka/shary/internal/http/middlewares"
+	"github.com/serozhenka/shary/internal/http/routes/attachments"
 	"github.com/serozhenka/shary/internal/http/routes/auth"
 	"github.com/serozhenka/shary/internal/http/routes/ping"
+	"github.com/serozhenka/shary/internal/http/routes/recordings"
 	"github.com/serozhenka/shary/internal/http/routes/rooms"
+	"github.com/serozhenka/shary/internal/http/routes/rtc"
 	"github.com/serozhenka/shary/internal/http/routes/ws"
+	rattachments "github.com/serozhenka/shary/internal/repository/attachments"
+	rchat "github.com/serozhenka/shary/internal/repository/chat"
+	rpolls "github.com/serozhenka/shary/internal/repository/polls"
+	rrecordings "github.com/serozhenka/shary/internal/repository/recordings"
 	rrooms "github.com/serozhenka/shary/internal/repository/rooms"
+	rsessions "github.com/serozhenka/shary/internal/repository/sessions"
 	rusers "github.com/serozhenka/shary/internal/repository/users"
 	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/sfu"
+	"github.com/serozhenka/shary/internal/storage"
+	"github.com/serozhenka/shary/internal/transcription"
 )
 
 func main() {
@@ -36,9 +48,49 @@ func main() {
 	// Initialize repositories
 	usersRepo := rusers.NewPostgresRepository(database.GetDB())
 	roomsRepo := rrooms.NewPostgresRepository(database.GetDB())
+	chatRepo := rchat.NewPostgresRepository(database.GetDB())
+	attachmentsRepo := rattachments.NewPostgresRepository(database.GetDB())
+	pollsRepo := rpolls.NewPostgresRepository(database.GetDB())
+	recordingsRepo := rrecordings.NewPostgresRepository(database.GetDB())
+	sessionsRepo := rsessions.NewPostgresRepository(database.GetDB())
+
+	// Initialize attachment and recording storage
+	blobStore, err := newBlobStore(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
 
 	// Initialize services
 	authService := services.NewAuthService(cfg.JWTSecret, usersRepo)
+	chatService := services.NewChatService(chatRepo, roomsRepo, attachmentsRepo)
+	pollService := services.NewPollService(pollsRepo, roomsRepo)
+	attachmentService := services.NewAttachmentService(
+		blobStore,
+		attachmentsRepo,
+		roomsRepo,
+		cfg.JWTSecret,
+		services.AttachmentLimits{
+			MaxSize:      int64(cfg.MaxAttachmentSize),
+			AllowedTypes: cfg.AttachmentTypes,
+		},
+	)
+	recordingService := services.NewRecordingService(blobStore, recordingsRepo, roomsRepo, cfg.JWTSecret)
+	sessionService := services.NewSessionService(sessionsRepo, roomsRepo)
+	iceService := services.NewICEService(services.ICEConfig{
+		STUNURLs:      cfg.STUNURLs,
+		TURNURLs:      cfg.TURNURLs,
+		TURNSecret:    cfg.TURNSecret,
+		CredentialTTL: time.Duration(cfg.TURNCredentialTTL) * time.Second,
+	})
+
+	forwarder, err := sfu.New(sfu.Config{
+		PublicIP: cfg.SFUPublicIP,
+		PortMin:  uint16(cfg.SFUPortMin),
+		PortMax:  uint16(cfg.SFUPortMax),
+	})
+	if err != nil {
+		log.Fatal("Failed to initialize SFU:", err)
+	}
 
 	r := gin.Default()
 	r.Use(middlewares.CORSMiddleware())
@@ -48,14 +100,25 @@ func main() {
 	// Public routes
 	ping.SetupRouter(r.Group("/ping"), &ping.RouterCtx{})
 	auth.SetupRouter(r.Group("/auth"), &auth.RouterCtx{AuthService: authService})
+	attachments.SetupRouter(r.Group("/attachments"), &attachments.RouterCtx{AttachmentService: attachmentService})
+	recordings.SetupRouter(r.Group("/recordings"), &recordings.RouterCtx{RecordingService: recordingService})
 
 	// WebSocket route (handles auth via query params)
 	ws.SetupRouter(
 		r.Group("/ws"),
 		&ws.RouterCtx{
-			RoomsRepo:      roomsRepo,
-			MeetingManager: meetingManager,
-			AuthService:    authService,
+			RoomsRepo:        roomsRepo,
+			ChatService:      chatService,
+			PollService:      pollService,
+			RecordingService: recordingService,
+			SessionService:   sessionService,
+			MeetingManager:   meetingManager,
+			AuthService:      authService,
+			ICEService:       iceService,
+			MaxParticipants:  cfg.DefaultMaxParticipants,
+			SFU:              forwarder,
+			// Plug a speech-to-text service in here to caption meetings
+			Transcriber: transcription.Noop{},
 			Upgrader: &websocket.Upgrader{
 				ReadBufferSize:  1024,
 				WriteBufferSize: 1024,
@@ -71,9 +134,38 @@ func main() {
 	protected.Use(middlewares.AuthMiddleware(authService))
 
 	auth.SetupProtectedRouter(protected.Group("/auth"), &auth.RouterCtx{AuthService: authService})
-	rooms.SetupRouter(protected.Group("/rooms"), &rooms.RouterCtx{Repo: roomsRepo})
+	rtc.SetupRouter(protected.Group("/rtc"), &rtc.RouterCtx{ICEService: iceService})
+	rooms.SetupRouter(
+		protected.Group("/rooms"),
+		&rooms.RouterCtx{
+			Repo:              roomsRepo,
+			ChatService:       chatService,
+			AttachmentService: attachmentService,
+			PollService:       pollService,
+			RecordingService:  recordingService,
+			SessionService:    sessionService,
+			MeetingManager:    meetingManager,
+		},
+	)
 
 	// Run the server
 	fmt.Printf("Starting server on 0.0.0.0:%s\n", cfg.Port)
 	r.Run("0.0.0.0:" + cfg.Port)
 }
+
+func newBlobStore(cfg *config.Config) (storage.BlobStore, error) {
+	switch cfg.StorageDriver {
+	case "local":
+		return storage.NewLocalStore(cfg.StoragePath)
+	case "s3":
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.StorageDriver)
+	}
+}
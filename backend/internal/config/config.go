@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +13,33 @@ type Config struct {
 	DatabaseURL string
 	JWTSecret   string
 	Port        string
+
+	// Default limit of publishing participants per meeting, rooms may override it
+	DefaultMaxParticipants int
+
+	// Attachment storage, "local" keeps files under StoragePath and "s3"
+	// uploads them to an S3-compatible bucket
+	StorageDriver     string
+	StoragePath       string
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKey       string
+	S3SecretKey       string
+	MaxAttachmentSize int
+	AttachmentTypes   []string
+
+	// Media forwarding for rooms with the SFU enabled
+	SFUPublicIP string
+	SFUPortMin  int
+	SFUPortMax  int
+
+	// ICE servers handed to clients, TURN credentials are derived from
+	// TURNSecret so the TURN server can check them without a user database
+	STUNURLs          []string
+	TURNURLs          []string
+	TURNSecret        string
+	TURNCredentialTTL int // Seconds
 }
 
 func Load() *Config {
@@ -23,6 +52,33 @@ func Load() *Config {
 		DatabaseURL: getEnv("DB_URL"),
 		JWTSecret:   getEnv("JWT_SECRET"),
 		Port:        getEnv("PORT"),
+
+		DefaultMaxParticipants: getEnvInt("DEFAULT_MAX_PARTICIPANTS", 8),
+
+		StorageDriver:     getEnvDefault("STORAGE_DRIVER", "local"),
+		StoragePath:       getEnvDefault("STORAGE_PATH", "uploads"),
+		MaxAttachmentSize: getEnvInt("MAX_ATTACHMENT_SIZE", 10<<20),
+		AttachmentTypes: strings.Split(
+			getEnvDefault("ATTACHMENT_TYPES", "application/pdf,image/png,image/jpeg,image/gif,image/webp,text/plain"),
+			",",
+		),
+
+		SFUPublicIP: os.Getenv("SFU_PUBLIC_IP"),
+		SFUPortMin:  getEnvInt("SFU_UDP_PORT_MIN", 0),
+		SFUPortMax:  getEnvInt("SFU_UDP_PORT_MAX", 0),
+
+		STUNURLs:          getEnvList("STUN_URLS", "stun:stun.l.google.com:19302"),
+		TURNURLs:          getEnvList("TURN_URLS", ""),
+		TURNSecret:        os.Getenv("TURN_SECRET"),
+		TURNCredentialTTL: getEnvInt("TURN_CREDENTIAL_TTL", 12*60*60),
+	}
+
+	if config.StorageDriver == "s3" {
+		config.S3Endpoint = getEnv("S3_ENDPOINT")
+		config.S3Region = getEnvDefault("S3_REGION", "us-east-1")
+		config.S3Bucket = getEnv("S3_BUCKET")
+		config.S3AccessKey = getEnv("S3_ACCESS_KEY")
+		config.S3SecretKey = getEnv("S3_SECRET_KEY")
 	}
 
 	return config
@@ -35,3 +91,34 @@ func getEnv(key string) string {
 	log.Panicf("Missing environment variable: %s", key)
 	return ""
 }
+
+func getEnvDefault(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Panicf("Invalid integer environment variable %s: %s", key, value)
+	}
+	return parsed
+}
+
+// getEnvList splits a comma-separated variable, skipping empty entries
+func getEnvList(key string, fallback string) []string {
+	values := make([]string, 0)
+	for _, value := range strings.Split(getEnvDefault(key, fallback), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
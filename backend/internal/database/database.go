@@ -22,7 +22,34 @@ func Connect(databaseURL string) error {
 }
 
 func Migrate() error {
-	err := DB.AutoMigrate(&models.User{}, &models.Room{}, &models.Participant{})
+	err := DB.AutoMigrate(
+		&models.User{},
+		&models.Room{},
+		&models.Participant{},
+		&models.Attachment{},
+		&models.ChatMessage{},
+		&models.ChatReaction{},
+		&models.ChatReadMarker{},
+		&models.Poll{},
+		&models.PollOption{},
+		&models.PollVote{},
+		&models.Recording{},
+		&models.RecordingTrack{},
+		&models.MeetingSession{},
+		&models.QualitySummary{},
+		&models.TranscriptSegment{},
+		&models.Whiteboard{},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Promote owners of rooms created before roles existed, whose rows
+	// defaulted to member
+	err = DB.Exec(
+		"UPDATE participants SET role = ? FROM rooms WHERE rooms.id = participants.room_id AND rooms.owner_id = participants.user_id AND participants.role <> ?",
+		models.RoleOwner, models.RoleOwner,
+	).Error
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,16 @@
+package attachments
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/services"
+)
+
+type RouterCtx struct {
+	AttachmentService *services.AttachmentService
+}
+
+// SetupRouter registers the download route, which is authorized by the
+// signed link from GET /rooms/:id/attachments/:attachmentId instead of a token
+func SetupRouter(rg *gin.RouterGroup, ctx *RouterCtx) {
+	rg.GET("/:id", ctx.download)
+}
@@ -0,0 +1,11 @@
+package attachments
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/http/routes/downloads"
+	"github.com/serozhenka/shary/internal/services"
+)
+
+func (r *RouterCtx) download(c *gin.Context) {
+	downloads.Serve(c, "attachment", services.ErrAttachmentNotFound, r.AttachmentService.Open)
+}
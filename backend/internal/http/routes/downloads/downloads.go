@@ -0,0 +1,68 @@
+// Package downloads serves files through the signed links handed out by the
+// services, rather than authorizing requests with a token
+package downloads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/services"
+)
+
+type Query struct {
+	User      uint   `form:"user" binding:"required"`
+	Expires   int64  `form:"expires" binding:"required"`
+	Signature string `form:"signature" binding:"required"`
+}
+
+// Opener checks a signed link to the file with the given ID and opens it
+type Opener func(ctx context.Context, id uint, userID uint, expires int64, signature string) (*services.Download, error)
+
+// Serve streams the file the link in the request points to as an
+// attachment. notFound is the error open fails with for missing files and
+// kind names the file in logs and error messages.
+func Serve(c *gin.Context, kind string, notFound error, open Opener) {
+	var query Query
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound.Error()})
+		return
+	}
+
+	download, err := open(c.Request.Context(), uint(id), query.User, query.Expires, query.Signature)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidDownloadLink):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, notFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			log.Printf("Failed to open %s: %v", kind, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to download %s", kind)})
+		}
+		return
+	}
+	defer download.Body.Close()
+
+	c.Header("Content-Type", download.ContentType)
+	c.Header("Content-Length", strconv.FormatInt(download.Size, 10))
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": download.FileName}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+
+	if _, err := io.Copy(c.Writer, download.Body); err != nil {
+		log.Printf("Failed to send %s: %v", kind, err)
+	}
+}
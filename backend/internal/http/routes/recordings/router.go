@@ -0,0 +1,16 @@
+package recordings
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/services"
+)
+
+type RouterCtx struct {
+	RecordingService *services.RecordingService
+}
+
+// SetupRouter registers the download route, which is authorized by the signed
+// link from GET /rooms/:id/recordings/:recordingId/tracks/:trackId instead of a token
+func SetupRouter(rg *gin.RouterGroup, ctx *RouterCtx) {
+	rg.GET("/tracks/:id", ctx.downloadTrack)
+}
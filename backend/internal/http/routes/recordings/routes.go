@@ -0,0 +1,11 @@
+package recordings
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/http/routes/downloads"
+	"github.com/serozhenka/shary/internal/services"
+)
+
+func (r *RouterCtx) downloadTrack(c *gin.Context) {
+	downloads.Serve(c, "recording", services.ErrRecordingNotFound, r.RecordingService.Open)
+}
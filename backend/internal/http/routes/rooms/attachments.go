@@ -0,0 +1,118 @@
+package rooms
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/services"
+)
+
+// multipartOverhead leaves room for the multipart headers around an upload
+const multipartOverhead = 64 << 10
+
+func (r *RouterCtx) uploadAttachment(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+
+	// Reject oversized uploads before they are spooled to disk
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, r.AttachmentService.MaxSize()+multipartOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrAttachmentTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := r.AttachmentService.Upload(
+		c.Request.Context(), userID.(uint), roomID, fileHeader.Filename, fileHeader.Size, file,
+	)
+	if err != nil {
+		status := attachmentErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Printf("Failed to upload attachment: %v", err)
+			c.JSON(status, gin.H{"error": "Failed to upload attachment"})
+			return
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": serializeAttachment(attachment)})
+}
+
+func (r *RouterCtx) getAttachment(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+
+	attachmentID, err := parseID(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return
+	}
+
+	url, expiresAt, err := r.AttachmentService.DownloadURL(userID.(uint), roomID, attachmentID)
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"url": url, "expires_at": expiresAt}})
+}
+
+// attachmentErrorStatus maps attachment service errors onto HTTP status codes
+func attachmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrAttachmentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, services.ErrAttachmentType):
+		return http.StatusUnsupportedMediaType
+	default:
+		return errorStatus(err)
+	}
+}
+
+// serializeAttachment converts an attachment into its API representation
+func serializeAttachment(attachment *models.Attachment) gin.H {
+	return gin.H{
+		"id":           attachment.ID,
+		"file_name":    attachment.FileName,
+		"content_type": attachment.ContentType,
+		"size":         attachment.Size,
+		"created_at":   attachment.CreatedAt,
+	}
+}
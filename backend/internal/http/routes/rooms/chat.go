@@ -0,0 +1,216 @@
+package rooms
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/http/routes/ws"
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/utils"
+)
+
+func (r *RouterCtx) listMessages(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var query ListMessagesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	id := c.Param("id")
+	room, err := r.Repo.GetRoomByStringID(userID.(uint), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+
+	chatMessages, err := r.ChatService.History(userID.(uint), room.ID, query.Before, query.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
+		return
+	}
+
+	// Older pages are requested with the ID of the oldest message returned
+	var nextCursor *string
+	if len(chatMessages) == query.Limit {
+		cursor := strconv.FormatUint(uint64(chatMessages[0].ID), 10)
+		nextCursor = &cursor
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        utils.Map(chatMessages, serializeChatMessage),
+		"next_cursor": nextCursor,
+	})
+}
+
+func (r *RouterCtx) editMessage(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req EditMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	roomID, messageID, ok := parseMessageParams(c)
+	if !ok {
+		return
+	}
+
+	message, err := r.ChatService.EditMessage(userID.(uint), roomID, messageID, req.Body)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	r.broadcastChatUpdate(roomID, messages.OutboundChatEdited, message)
+
+	c.JSON(http.StatusOK, gin.H{"data": serializeChatMessage(message)})
+}
+
+func (r *RouterCtx) deleteMessage(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, messageID, ok := parseMessageParams(c)
+	if !ok {
+		return
+	}
+
+	message, err := r.ChatService.DeleteMessage(userID.(uint), roomID, messageID)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	r.broadcastChatUpdate(roomID, messages.OutboundChatDeleted, message)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message deleted successfully"})
+}
+
+func (r *RouterCtx) addReaction(c *gin.Context) {
+	r.react(c, false)
+}
+
+func (r *RouterCtx) removeReaction(c *gin.Context) {
+	r.react(c, true)
+}
+
+func (r *RouterCtx) react(c *gin.Context, remove bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, messageID, ok := parseMessageParams(c)
+	if !ok {
+		return
+	}
+
+	message, err := r.ChatService.React(userID.(uint), roomID, messageID, c.Param("emoji"), remove)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	r.broadcastChatUpdate(roomID, messages.OutboundChatReacted, message)
+
+	c.JSON(http.StatusOK, gin.H{"data": serializeChatMessage(message)})
+}
+
+// broadcastChatUpdate relays a chat change made over REST to the room's live
+// meeting and its breakouts, if any
+func (r *RouterCtx) broadcastChatUpdate(roomID uint, messageType messages.OutboundMessageType, message *models.ChatMessage) {
+	meet := r.MeetingManager.GetMeeting(strconv.FormatUint(uint64(roomID), 10))
+	if meet == nil {
+		return
+	}
+
+	outbound := ws.ChatMessagePayload(message)
+	meet.BroadcastChatWithBreakouts(
+		message,
+		&messages.OutboundWsMessage{
+			Type:    messageType,
+			Payload: &outbound,
+		},
+	)
+}
+
+func parseMessageParams(c *gin.Context) (uint, uint, bool) {
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return 0, 0, false
+	}
+
+	messageID, err := parseID(c.Param("messageId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+		return 0, 0, false
+	}
+
+	return roomID, messageID, true
+}
+
+// chatErrorStatus maps chat service errors onto HTTP status codes
+func chatErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrMessageNotFound), errors.Is(err, services.ErrAttachmentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrMessageForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidMessage), errors.Is(err, services.ErrInvalidEmoji),
+		errors.Is(err, services.ErrInvalidRecipient):
+		return http.StatusBadRequest
+	default:
+		return errorStatus(err)
+	}
+}
+
+// serializeChatMessage converts a chat message into its API representation
+func serializeChatMessage(message *models.ChatMessage) gin.H {
+	body := message.Body
+	reactions := make([]gin.H, 0)
+	var attachment gin.H
+	if message.IsDeleted() {
+		body = ""
+	} else {
+		for _, reaction := range ws.ChatMessagePayload(message).Reactions {
+			reactions = append(reactions, gin.H{"emoji": reaction.Emoji, "user_ids": reaction.UserIds})
+		}
+		if message.Attachment != nil {
+			attachment = serializeAttachment(message.Attachment)
+		}
+	}
+
+	return gin.H{
+		"id":           message.ID,
+		"author_id":    message.AuthorID,
+		"username":     message.Author.Username,
+		"body":         body,
+		"reply_to_id":  message.ReplyToID,
+		"recipient_id": message.RecipientID,
+		"created_at":   message.CreatedAt,
+		"edited_at":    message.EditedAt,
+		"deleted":      message.IsDeleted(),
+		"reactions":    reactions,
+		"attachment":   attachment,
+	}
+}
@@ -0,0 +1,64 @@
+package rooms
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/utils"
+)
+
+func (r *RouterCtx) listPolls(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+
+	results, err := r.PollService.ListPolls(userID.(uint), roomID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": utils.Map(results, serializePoll)})
+}
+
+// serializePoll converts a poll and its tally into its API representation
+func serializePoll(result *services.PollResult) gin.H {
+	poll := result.Poll
+
+	return gin.H{
+		"id":           poll.ID,
+		"creator_id":   poll.CreatorID,
+		"question":     poll.Question,
+		"anonymous":    poll.Anonymous,
+		"multi_choice": poll.MultiChoice,
+		"closes_at":    poll.ClosesAt,
+		"closed":       result.Closed,
+		"created_at":   poll.CreatedAt,
+		"total_voters": result.TotalVoters,
+		"options": utils.Map(poll.Options, func(option models.PollOption) gin.H {
+			serialized := gin.H{
+				"id":    option.ID,
+				"text":  option.Text,
+				"votes": result.Votes[option.ID],
+			}
+			if !poll.Anonymous {
+				voterIDs := result.Voters[option.ID]
+				if voterIDs == nil {
+					voterIDs = []uint{}
+				}
+				serialized["voter_ids"] = voterIDs
+			}
+			return serialized
+		}),
+	}
+}
@@ -0,0 +1,92 @@
+package rooms
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/utils"
+)
+
+func (r *RouterCtx) listRecordings(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+
+	recordings, err := r.RecordingService.ListRecordings(userID.(uint), roomID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": utils.Map(recordings, serializeRecording)})
+}
+
+func (r *RouterCtx) getRecordingTrack(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+
+	recordingID, err := parseID(c.Param("recordingId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrRecordingNotFound.Error()})
+		return
+	}
+
+	trackID, err := parseID(c.Param("trackId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrRecordingNotFound.Error()})
+		return
+	}
+
+	url, expiresAt, err := r.RecordingService.DownloadURL(userID.(uint), roomID, recordingID, trackID)
+	if err != nil {
+		status := errorStatus(err)
+		if errors.Is(err, services.ErrRecordingNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"url": url, "expires_at": expiresAt}})
+}
+
+// serializeRecording converts a recording and its tracks into its API representation
+func serializeRecording(recording *models.Recording) gin.H {
+	return gin.H{
+		"id":            recording.ID,
+		"started_by_id": recording.StartedByID,
+		"status":        recording.Status,
+		"started_at":    recording.StartedAt,
+		"stopped_at":    recording.StoppedAt,
+		"tracks": utils.Map(recording.Tracks, func(track models.RecordingTrack) gin.H {
+			return gin.H{
+				"id":           track.ID,
+				"user_id":      track.UserID,
+				"kind":         track.Kind,
+				"content_type": track.ContentType,
+				"size":         track.Size,
+				"created_at":   track.CreatedAt,
+			}
+		}),
+	}
+}
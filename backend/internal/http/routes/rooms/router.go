@@ -2,11 +2,19 @@ package rooms
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/http/routes/ws"
 	"github.com/serozhenka/shary/internal/repository/rooms"
+	"github.com/serozhenka/shary/internal/services"
 )
 
 type RouterCtx struct {
-	Repo rooms.Repository
+	Repo              rooms.Repository
+	ChatService       *services.ChatService
+	AttachmentService *services.AttachmentService
+	PollService       *services.PollService
+	RecordingService  *services.RecordingService
+	SessionService    *services.SessionService
+	MeetingManager    ws.MeetingManager
 }
 
 func SetupRouter(rg *gin.RouterGroup, ctx *RouterCtx) {
@@ -14,6 +22,28 @@ func SetupRouter(rg *gin.RouterGroup, ctx *RouterCtx) {
 	rg.GET("/:id", ctx.getRoom)
 	rg.POST("", ctx.createRoom)
 	rg.PUT("/:id", ctx.updateRoom)
+	rg.PATCH("/:id/settings", ctx.updateRoomSettings)
 	rg.DELETE("/:id", ctx.deleteRoom)
+	rg.GET("/:id/participants", ctx.listParticipants)
 	rg.POST("/:id/users", ctx.addUserToRoom)
+	rg.DELETE("/:id/users/:userId", ctx.removeUserFromRoom)
+	rg.POST("/:id/leave", ctx.leaveRoom)
+	rg.PUT("/:id/users/:userId/role", ctx.updateParticipantRole)
+	rg.PUT("/:id/owner", ctx.transferOwnership)
+	rg.GET("/:id/messages", ctx.listMessages)
+	rg.PUT("/:id/messages/:messageId", ctx.editMessage)
+	rg.DELETE("/:id/messages/:messageId", ctx.deleteMessage)
+	rg.PUT("/:id/messages/:messageId/reactions/:emoji", ctx.addReaction)
+	rg.DELETE("/:id/messages/:messageId/reactions/:emoji", ctx.removeReaction)
+	rg.POST("/:id/attachments", ctx.uploadAttachment)
+	rg.GET("/:id/attachments/:attachmentId", ctx.getAttachment)
+	rg.GET("/:id/polls", ctx.listPolls)
+	rg.GET("/:id/recordings", ctx.listRecordings)
+	rg.GET("/:id/recordings/:recordingId/tracks/:trackId", ctx.getRecordingTrack)
+	rg.GET("/:id/sessions", ctx.listSessions)
+	rg.GET("/:id/sessions/:sessionId/quality", ctx.getSessionQuality)
+	rg.GET("/:id/sessions/:sessionId/transcript", ctx.getSessionTranscript)
+	rg.GET("/:id/sessions/:sessionId/whiteboards", ctx.listWhiteboards)
+	rg.GET("/:id/whiteboards/:whiteboard", ctx.exportWhiteboard)
+	rg.GET("/:id/live/quality", ctx.getLiveQuality)
 }
@@ -1,10 +1,15 @@
 package rooms
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/repository/rooms"
+	"github.com/serozhenka/shary/internal/utils"
 )
 
 func (r *RouterCtx) listRooms(c *gin.Context) {
@@ -20,19 +25,22 @@ func (r *RouterCtx) listRooms(c *gin.Context) {
 		return
 	}
 
-	// Serialize rooms to avoid React rendering issues
-	serializedRooms := make([]gin.H, len(rooms))
-	for i, room := range rooms {
-		serializedRooms[i] = gin.H{
-			"id":           strconv.FormatUint(uint64(room.ID), 10),
-			"name":         room.Name,
-			"created_at":   room.CreatedAt,
-			"owner_id":     room.OwnerID,
-			"is_owner":     room.IsOwner,
-			"participants": len(room.Participants),
-		}
+	unreadCounts, err := r.ChatService.UnreadCounts(
+		userID.(uint),
+		utils.Map(rooms, func(room *models.Room) uint { return room.ID }),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rooms"})
+		return
 	}
 
+	// Serialize rooms to avoid React rendering issues
+	serializedRooms := utils.Map(rooms, func(room *models.Room) gin.H {
+		serialized := serializeRoom(room)
+		serialized["unread_count"] = unreadCounts[room.ID]
+		return serialized
+	})
+
 	c.JSON(http.StatusOK, gin.H{"data": serializedRooms})
 }
 
@@ -51,14 +59,7 @@ func (r *RouterCtx) getRoom(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": gin.H{
-			"id":           strconv.FormatUint(uint64(room.ID), 10),
-			"name":         room.Name,
-			"created_at":   room.CreatedAt,
-			"owner_id":     room.OwnerID,
-			"is_owner":     room.IsOwner,
-			"participants": len(room.Participants),
-		},
+		"data": serializeRoom(room),
 	})
 }
 
@@ -82,14 +83,7 @@ func (r *RouterCtx) createRoom(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"data": gin.H{
-			"id":           strconv.FormatUint(uint64(roomModel.ID), 10),
-			"name":         roomModel.Name,
-			"created_at":   roomModel.CreatedAt,
-			"owner_id":     roomModel.OwnerID,
-			"is_owner":     roomModel.IsOwner,
-			"participants": len(roomModel.Participants),
-		},
+		"data": serializeRoom(roomModel),
 	})
 }
 
@@ -109,20 +103,56 @@ func (r *RouterCtx) updateRoom(c *gin.Context) {
 
 	roomModel, err := r.Repo.UpdateRoomByStringID(userID.(uint), id, req.Name)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found or permission denied"})
+		c.JSON(errorStatus(err), gin.H{"error": "Room not found or permission denied"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": gin.H{
-			"id":           strconv.FormatUint(uint64(roomModel.ID), 10),
-			"name":         roomModel.Name,
-			"created_at":   roomModel.CreatedAt,
-			"owner_id":     roomModel.OwnerID,
-			"is_owner":     roomModel.IsOwner,
-			"participants": len(roomModel.Participants),
-		},
+		"data": serializeRoom(roomModel),
+	})
+}
+
+func (r *RouterCtx) updateRoomSettings(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	var req UpdateRoomSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	var allowedCodecs *string
+	if req.AllowedCodecs != nil {
+		joined := strings.Join(*req.AllowedCodecs, ",")
+		allowedCodecs = &joined
+	}
+
+	roomModel, err := r.Repo.UpdateRoomSettings(userID.(uint), roomID, rooms.RoomSettings{
+		LobbyEnabled:      req.LobbyEnabled,
+		MaxParticipants:   req.MaxParticipants,
+		OverflowAsViewers: req.OverflowAsViewers,
+		SFUEnabled:        req.SFUEnabled,
+		AllowedCodecs:     allowedCodecs,
+		MaxVideoBitrate:   req.MaxVideoBitrate,
+		SimulcastEnabled:  req.SimulcastEnabled,
+		Mode:              req.Mode,
 	})
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": "Room not found or permission denied"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": serializeRoom(roomModel)})
 }
 
 func (r *RouterCtx) deleteRoom(c *gin.Context) {
@@ -135,7 +165,7 @@ func (r *RouterCtx) deleteRoom(c *gin.Context) {
 	id := c.Param("id")
 	err := r.Repo.DeleteRoomByStringID(userID.(uint), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found or permission denied"})
+		c.JSON(errorStatus(err), gin.H{"error": "Room not found or permission denied"})
 		return
 	}
 
@@ -164,3 +194,229 @@ func (r *RouterCtx) addUserToRoom(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": "User added to room successfully"})
 }
+
+func (r *RouterCtx) listParticipants(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	id := c.Param("id")
+	room, err := r.Repo.GetRoomByStringID(userID.(uint), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+
+	meet := r.MeetingManager.GetMeeting(strconv.FormatUint(uint64(room.ID), 10))
+
+	serializedParticipants := make([]gin.H, len(room.Participants))
+	for i, participant := range room.Participants {
+		clients := 0
+		if meet != nil {
+			clients = meet.UserClientCount(participant.UserID)
+		}
+
+		serializedParticipants[i] = gin.H{
+			"user_id":   participant.UserID,
+			"username":  participant.User.Username,
+			"email":     participant.User.Email,
+			"role":      participant.Role,
+			"joined_at": participant.JoinedAt,
+			"online":    clients > 0,
+			"clients":   clients,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": serializedParticipants})
+}
+
+func (r *RouterCtx) removeUserFromRoom(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	participantID, err := parseID(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := r.Repo.RemoveUserFromRoom(userID.(uint), roomID, participantID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	r.kickFromMeeting(roomID, participantID, "removed")
+
+	c.JSON(http.StatusOK, gin.H{"data": "User removed from room successfully"})
+}
+
+func (r *RouterCtx) leaveRoom(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	if err := r.Repo.LeaveRoom(userID.(uint), roomID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	r.kickFromMeeting(roomID, userID.(uint), "left")
+
+	c.JSON(http.StatusOK, gin.H{"data": "Left room successfully"})
+}
+
+func (r *RouterCtx) updateParticipantRole(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	participantID, err := parseID(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UpdateParticipantRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	participant, err := r.Repo.UpdateParticipantRole(userID.(uint), roomID, participantID, models.Role(req.Role))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	r.notifyRoleChange(roomID, participant.UserID, participant.Role)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"user_id":   participant.UserID,
+			"username":  participant.User.Username,
+			"role":      participant.Role,
+			"joined_at": participant.JoinedAt,
+		},
+	})
+}
+
+func (r *RouterCtx) transferOwnership(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return
+	}
+
+	var req TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	roomModel, err := r.Repo.TransferOwnership(userID.(uint), roomID, req.UserID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	r.notifyRoleChange(roomID, req.UserID, models.RoleOwner)
+	r.notifyRoleChange(roomID, userID.(uint), models.RoleModerator)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": serializeRoom(roomModel),
+	})
+}
+
+// notifyRoleChange propagates a role change to the room's live meeting, if any
+func (r *RouterCtx) notifyRoleChange(roomID uint, userID uint, role models.Role) {
+	meet := r.MeetingManager.GetMeeting(strconv.FormatUint(uint64(roomID), 10))
+	if meet == nil {
+		return
+	}
+
+	meet.SetUserRole(userID, role)
+}
+
+// kickFromMeeting disconnects the user's live clients from the room's meeting, if any
+func (r *RouterCtx) kickFromMeeting(roomID uint, userID uint, reason string) {
+	meet := r.MeetingManager.GetMeeting(strconv.FormatUint(uint64(roomID), 10))
+	if meet == nil {
+		return
+	}
+
+	meet.Kick(userID, reason)
+}
+
+// serializeRoom converts a room into its API representation
+func serializeRoom(room *models.Room) gin.H {
+	return gin.H{
+		"id":                  strconv.FormatUint(uint64(room.ID), 10),
+		"name":                room.Name,
+		"created_at":          room.CreatedAt,
+		"owner_id":            room.OwnerID,
+		"is_owner":            room.IsOwner,
+		"participants":        len(room.Participants),
+		"lobby_enabled":       room.LobbyEnabled,
+		"max_participants":    room.MaxParticipants,
+		"overflow_as_viewers": room.OverflowAsViewers,
+		"sfu_enabled":         room.SFUEnabled,
+		"mode":                room.Mode,
+		"allowed_codecs":      room.CodecList(),
+		"max_video_bitrate":   room.MaxVideoBitrate,
+		"simulcast_enabled":   room.SimulcastEnabled,
+	}
+}
+
+func parseID(value string) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 32)
+	return uint(id), err
+}
+
+// errorStatus maps repository errors onto HTTP status codes
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, rooms.ErrRoomNotFound), errors.Is(err, rooms.ErrParticipantNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, rooms.ErrPermissionDenied):
+		return http.StatusForbidden
+	case errors.Is(err, rooms.ErrOwnerCannotLeave):
+		return http.StatusConflict
+	case errors.Is(err, rooms.ErrInvalidRole):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
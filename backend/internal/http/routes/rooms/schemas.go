@@ -1,5 +1,7 @@
 package rooms
 
+import "github.com/serozhenka/shary/internal/models"
+
 type CreateRoomRequest struct {
 	Name string `json:"name" binding:"required"`
 }
@@ -8,6 +10,36 @@ type UpdateRoomRequest struct {
 	Name string `json:"name" binding:"required"`
 }
 
+type UpdateRoomSettingsRequest struct {
+	LobbyEnabled      *bool `json:"lobby_enabled"`
+	MaxParticipants   *int  `json:"max_participants" binding:"omitempty,min=0"`
+	OverflowAsViewers *bool `json:"overflow_as_viewers"`
+	SFUEnabled        *bool `json:"sfu_enabled"`
+
+	Mode *models.RoomMode `json:"mode" binding:"omitempty,oneof=meeting audio_only webinar"`
+
+	AllowedCodecs    *[]string `json:"allowed_codecs" binding:"omitempty,dive,oneof=opus G722 PCMU PCMA VP8 VP9 H264 AV1"`
+	MaxVideoBitrate  *int      `json:"max_video_bitrate" binding:"omitempty,min=0"` // In kbps
+	SimulcastEnabled *bool     `json:"simulcast_enabled"`
+}
+
 type AddUserToRoomRequest struct {
 	Email string `json:"email" binding:"required,email"`
 }
+
+type UpdateParticipantRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type TransferOwnershipRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+type ListMessagesQuery struct {
+	Before uint `form:"before"`
+	Limit  int  `form:"limit,default=50" binding:"min=1,max=100"`
+}
+
+type EditMessageRequest struct {
+	Body string `json:"body" binding:"required"`
+}
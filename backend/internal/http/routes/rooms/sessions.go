@@ -0,0 +1,187 @@
+package rooms
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/http/routes/ws"
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/repository/rooms"
+	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/transcription"
+	"github.com/serozhenka/shary/internal/utils"
+)
+
+func (r *RouterCtx) listSessions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+
+	sessions, err := r.SessionService.ListSessions(userID.(uint), roomID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+func (r *RouterCtx) getSessionQuality(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+
+	sessionID, err := parseID(c.Param("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrSessionNotFound.Error()})
+		return
+	}
+
+	summaries, err := r.SessionService.QualitySummaries(userID.(uint), roomID, sessionID)
+	if err != nil {
+		status := errorStatus(err)
+		if errors.Is(err, services.ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summaries})
+}
+
+// getLiveQuality shows moderators how the connections of the room's live
+// meeting perform
+func (r *RouterCtx) getLiveQuality(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+
+	participant, err := r.Repo.GetParticipant(roomID, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+	if !participant.Role.Can(models.PermissionModerate) {
+		c.JSON(http.StatusForbidden, gin.H{"error": rooms.ErrPermissionDenied.Error()})
+		return
+	}
+
+	var sessionID uint
+	clients := []ws.ClientQuality{}
+	if meet := r.MeetingManager.GetMeeting(strconv.FormatUint(uint64(roomID), 10)); meet != nil {
+		sessionID, clients = meet.LiveQuality()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"session_id": sessionID,
+		"clients":    utils.Map(clients, serializeClientQuality),
+	}})
+}
+
+// serializeClientQuality converts a client's latest stats into their API representation
+func serializeClientQuality(quality ws.ClientQuality) gin.H {
+	return gin.H{
+		"client_id":  quality.ClientId,
+		"user_id":    quality.UserId,
+		"username":   quality.Username,
+		"poor":       quality.Poor,
+		"updated_at": quality.UpdatedAt,
+		"peers": utils.Map(quality.Peers, func(stats messages.PeerStats) gin.H {
+			return gin.H{
+				"client_id":   stats.ClientId,
+				"rtt":         stats.Rtt,
+				"packet_loss": stats.PacketLoss,
+				"jitter":      stats.Jitter,
+				"bitrate":     stats.Bitrate,
+			}
+		}),
+	}
+}
+
+// getSessionTranscript returns what was said during a session as JSON, or as
+// subtitles with ?format=srt or ?format=vtt
+func (r *RouterCtx) getSessionTranscript(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+
+	sessionID, err := parseID(c.Param("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrSessionNotFound.Error()})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "srt" && format != "vtt" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be json, srt or vtt"})
+		return
+	}
+
+	session, segments, err := r.SessionService.Transcript(userID.(uint), roomID, sessionID)
+	if err != nil {
+		status := errorStatus(err)
+		if errors.Is(err, services.ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	cues := utils.Map(segments, func(segment models.TranscriptSegment) transcription.Cue {
+		return transcription.Cue{
+			Start:   segment.StartedAt.Sub(session.StartedAt),
+			End:     segment.EndedAt.Sub(session.StartedAt),
+			Speaker: segment.Speaker,
+			Text:    segment.Text,
+		}
+	})
+
+	switch format {
+	case "srt":
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fmt.Sprintf("session-%d.srt", session.ID)}))
+		c.Data(http.StatusOK, "application/x-subrip; charset=utf-8", []byte(transcription.SRT(cues)))
+	case "vtt":
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fmt.Sprintf("session-%d.vtt", session.ID)}))
+		c.Data(http.StatusOK, "text/vtt; charset=utf-8", []byte(transcription.WebVTT(cues)))
+	default:
+		c.JSON(http.StatusOK, gin.H{"data": segments})
+	}
+}
@@ -0,0 +1,84 @@
+package rooms
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/services"
+)
+
+func (r *RouterCtx) listWhiteboards(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+
+	sessionID, err := parseID(c.Param("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrSessionNotFound.Error()})
+		return
+	}
+
+	whiteboards, err := r.SessionService.ListWhiteboards(userID.(uint), roomID, sessionID)
+	if err != nil {
+		status := errorStatus(err)
+		if errors.Is(err, services.ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": whiteboards})
+}
+
+// exportWhiteboard draws a board left at the end of a session, the path ends
+// in the board's ID followed by .svg
+func (r *RouterCtx) exportWhiteboard(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	roomID, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return
+	}
+
+	id, ok := strings.CutSuffix(c.Param("whiteboard"), ".svg")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrWhiteboardNotFound.Error()})
+		return
+	}
+	whiteboardID, err := parseID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrWhiteboardNotFound.Error()})
+		return
+	}
+
+	svg, err := r.SessionService.WhiteboardSVG(userID.(uint), roomID, whiteboardID)
+	if err != nil {
+		status := errorStatus(err)
+		if errors.Is(err, services.ErrWhiteboardNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": fmt.Sprintf("whiteboard-%d.svg", whiteboardID)}))
+	c.Data(http.StatusOK, "image/svg+xml", svg)
+}
@@ -0,0 +1,14 @@
+package rtc
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/serozhenka/shary/internal/services"
+)
+
+type RouterCtx struct {
+	ICEService *services.ICEService
+}
+
+func SetupRouter(rg *gin.RouterGroup, ctx *RouterCtx) {
+	rg.GET("/config", ctx.getConfig)
+}
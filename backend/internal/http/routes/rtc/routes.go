@@ -0,0 +1,20 @@
+package rtc
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getConfig issues ICE servers for connections made outside a meeting's
+// WebSocket, e.g. to test the network before joining
+func (r *RouterCtx) getConfig(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	servers, expiresAt := r.ICEService.Servers(userID.(uint))
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"ice_servers": servers, "expires_at": expiresAt}})
+}
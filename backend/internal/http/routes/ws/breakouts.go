@@ -0,0 +1,288 @@
+package ws
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/segmentio/ksuid"
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/utils"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	maxBreakouts        = 50
+	maxBreakoutDuration = 24 * time.Hour
+)
+
+// breakoutSession holds the breakouts a main meeting is split into
+type breakoutSession struct {
+	rooms  []*breakoutRoom
+	endsAt *time.Time
+	timer  *time.Timer // Brings everyone back once the breakouts are over
+}
+
+type breakoutRoom struct {
+	id      string // Key of the meeting in the MeetingManager
+	name    string
+	meeting *Meeting
+}
+
+func (c *Client) createBreakouts(ctx *RouterCtx, m *Meeting, payload *messages.InboundBreakoutCreatePayload) {
+	if m.parent != nil {
+		c.SendError("invalid_breakout", "Breakouts can only be started from the main meeting")
+		return
+	}
+
+	if payload.Count < 1 || payload.Count > maxBreakouts {
+		c.SendError("invalid_breakout", fmt.Sprintf("Between 1 and %d breakouts can be created", maxBreakouts))
+		return
+	}
+
+	duration := time.Duration(payload.DurationSeconds) * time.Second
+	if duration < 0 || duration > maxBreakoutDuration {
+		c.SendError("invalid_breakout", "Breakouts can last up to 24 hours")
+		return
+	}
+
+	assignments := make(map[*Client]int, len(payload.Assignments))
+	for clientId, index := range payload.Assignments {
+		target := m.GetClient(clientId)
+		if target == nil {
+			c.SendError("not_found", "Participant is not in the meeting")
+			return
+		}
+		if index < 0 || index >= payload.Count {
+			c.SendError("invalid_breakout", "Participant assigned to an unknown breakout")
+			return
+		}
+		assignments[target] = index
+	}
+
+	if payload.Random {
+		assignRandomly(m, assignments, payload.Count)
+	}
+
+	session := &breakoutSession{}
+	if duration > 0 {
+		endsAt := time.Now().Add(duration)
+		session.endsAt = &endsAt
+		session.timer = time.AfterFunc(duration, func() {
+			ctx.closeBreakouts(m, nil)
+		})
+	}
+
+	for i := range payload.Count {
+		id := ksuid.New().String()
+		meeting := ctx.MeetingManager.GetOrCreateMeeting(id, m.currentRoom())
+		meeting.parent = m
+		meeting.UseSFU(ctx.SFU, m.mediaSession() != nil)
+		meeting.SetSDPPolicy(m.sdpPolicy())
+		meeting.SetMode(m.roomMode())
+		meeting.UseTranscriber(m.currentTranscriber())
+		meeting.UseICE(ctx.ICEService)
+
+		session.rooms = append(session.rooms, &breakoutRoom{
+			id:      id,
+			name:    fmt.Sprintf("Breakout %d", i+1),
+			meeting: meeting,
+		})
+	}
+
+	m.mu.Lock()
+	running := m.breakouts != nil
+	if !running {
+		m.breakouts = session
+	}
+	m.mu.Unlock()
+
+	if running {
+		if session.timer != nil {
+			session.timer.Stop()
+		}
+		for _, room := range session.rooms {
+			ctx.MeetingManager.DeleteMeeting(room.id)
+		}
+
+		c.SendError("breakouts_running", "Breakouts are already running")
+		return
+	}
+
+	for client, index := range assignments {
+		room := session.rooms[index]
+		client.moveTo(m, room.meeting, &messages.OutboundMoveToBreakoutPayload{
+			BreakoutId: room.id,
+			Name:       room.name,
+			EndsAt:     session.endsAt,
+		})
+	}
+
+	m.broadcastBreakouts(c)
+}
+
+// assignRandomly spreads the participants without an assignment over the
+// breakouts, filling the emptiest breakout first. Moderators stay in the
+// main meeting unless assigned explicitly.
+func assignRandomly(m *Meeting, assignments map[*Client]int, count int) {
+	m.mu.RLock()
+	remaining := utils.Filter(
+		maps.Keys(m.Clients),
+		func(client *Client) bool {
+			_, assigned := assignments[client]
+			return !assigned && !client.Can(models.PermissionModerate)
+		},
+	)
+	m.mu.RUnlock()
+
+	rand.Shuffle(len(remaining), func(i, j int) {
+		remaining[i], remaining[j] = remaining[j], remaining[i]
+	})
+
+	sizes := make([]int, count)
+	for _, index := range assignments {
+		sizes[index]++
+	}
+
+	for _, client := range remaining {
+		index := slices.Index(sizes, slices.Min(sizes))
+		assignments[client] = index
+		sizes[index]++
+	}
+}
+
+func (c *Client) endBreakouts(ctx *RouterCtx, m *Meeting) {
+	if !ctx.closeBreakouts(m.mainMeeting(), c) {
+		c.SendError("not_found", "No breakouts are running")
+	}
+}
+
+// closeBreakouts brings everyone back to the main meeting, reporting whether
+// any breakouts were running
+func (ctx *RouterCtx) closeBreakouts(m *Meeting, c *Client) bool {
+	m.mu.Lock()
+	session := m.breakouts
+	m.breakouts = nil
+	m.mu.Unlock()
+
+	if session == nil {
+		return false
+	}
+
+	if session.timer != nil {
+		session.timer.Stop()
+	}
+
+	for _, room := range session.rooms {
+		room.meeting.mu.RLock()
+		clients := maps.Keys(room.meeting.Clients)
+		room.meeting.mu.RUnlock()
+
+		for _, client := range clients {
+			client.moveTo(room.meeting, m, &messages.OutboundMoveToBreakoutPayload{
+				Name: m.currentRoom().Name,
+			})
+		}
+
+		room.meeting.release()
+		m.keepBoard(room.meeting.takeBoard(room.name))
+		ctx.MeetingManager.DeleteMeeting(room.id)
+	}
+
+	m.broadcastBreakouts(c)
+	return true
+}
+
+func (c *Client) broadcastToBreakouts(m *Meeting, payload *messages.InboundBreakoutBroadcastPayload) {
+	message := strings.TrimSpace(payload.Message)
+	if message == "" || utf8.RuneCountInString(message) > services.MaxChatMessageLength {
+		c.SendError("invalid_message", "Message must not be empty or too long")
+		return
+	}
+
+	meetings := m.mainMeeting().withBreakouts()
+	if len(meetings) == 1 {
+		c.SendError("not_found", "No breakouts are running")
+		return
+	}
+
+	msg := &messages.OutboundWsMessage{
+		Type: messages.OutboundBreakoutBroadcast,
+		Payload: &messages.OutboundBreakoutBroadcastPayload{
+			ClientId: c.Id,
+			Username: c.Username,
+			Message:  message,
+		},
+	}
+	for _, meeting := range meetings {
+		meeting.Broadcast(msg)
+	}
+}
+
+// broadcastBreakouts shares who is in which breakout with the moderators of
+// the main meeting and of every breakout
+func (m *Meeting) broadcastBreakouts(c *Client) {
+	m.mu.RLock()
+	session := m.breakouts
+	m.mu.RUnlock()
+
+	payload := &messages.OutboundBreakoutsPayload{Breakouts: []messages.Breakout{}}
+	if c != nil {
+		payload.ClientId = c.Id
+	}
+
+	if session != nil {
+		payload.EndsAt = session.endsAt
+		payload.Breakouts = utils.Map(session.rooms, func(room *breakoutRoom) messages.Breakout {
+			room.meeting.mu.RLock()
+			defer room.meeting.mu.RUnlock()
+
+			clientIds := utils.Map(maps.Keys(room.meeting.Clients), func(client *Client) string {
+				return client.Id
+			})
+			slices.Sort(clientIds)
+
+			return messages.Breakout{
+				Id:        room.id,
+				Name:      room.name,
+				ClientIds: clientIds,
+			}
+		})
+	}
+
+	msg := &messages.OutboundWsMessage{
+		Type:    messages.OutboundBreakouts,
+		Payload: payload,
+	}
+	for _, meeting := range m.withBreakouts() {
+		meeting.BroadcastToModerators(msg)
+	}
+}
+
+// mainMeeting returns the meeting a breakout was split from, or the meeting
+// itself
+func (m *Meeting) mainMeeting() *Meeting {
+	if m.parent != nil {
+		return m.parent
+	}
+	return m
+}
+
+// withBreakouts returns the meeting followed by its running breakouts
+func (m *Meeting) withBreakouts() []*Meeting {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	meetings := []*Meeting{m}
+	if m.breakouts != nil {
+		for _, room := range m.breakouts.rooms {
+			meetings = append(meetings, room.meeting)
+		}
+	}
+	return meetings
+}
@@ -0,0 +1,47 @@
+package ws
+
+import (
+	"errors"
+
+	"github.com/serozhenka/shary/internal/models"
+)
+
+var ErrMeetingFull = errors.New("room_full")
+
+// SetCapacity configures how many publishing participants the meeting admits
+// and whether late arrivals should join as viewers once it's full
+func (m *Meeting) SetCapacity(capacity int, overflowAsViewers bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.capacity = capacity
+	m.overflow = overflowAsViewers
+}
+
+// checkCapacity decides whether the client fits into the meeting, downgrading
+// it to a receive-only viewer when overflow is enabled. Only clients that are
+// allowed to publish media count towards the capacity. Must be called with the
+// meeting lock held.
+func (m *Meeting) checkCapacity(c *Client) error {
+	if m.capacity <= 0 || !c.Can(models.PermissionPublishMedia) {
+		return nil
+	}
+
+	publishers := 0
+	for client := range m.Clients {
+		if client.Can(models.PermissionPublishMedia) {
+			publishers++
+		}
+	}
+
+	if publishers < m.capacity {
+		return nil
+	}
+
+	if m.overflow {
+		c.SetRole(models.RoleViewer)
+		return nil
+	}
+
+	return ErrMeetingFull
+}
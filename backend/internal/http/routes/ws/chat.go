@@ -0,0 +1,253 @@
+package ws
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/utils"
+)
+
+const (
+	chatHistoryLimit = 50
+	// typingInterval is the shortest delay between two relayed typing
+	// notifications of a client, clients repeat them while the user types
+	typingInterval = 2 * time.Second
+)
+
+func (c *Client) sendChatMessage(ctx *RouterCtx, m *Meeting, payload *messages.InboundDataPayload) {
+	// Private messages may target a specific client, which is resolved to its user
+	recipientId := payload.UserId
+	if payload.ClientId != "" {
+		recipient := m.GetClient(payload.ClientId)
+		if recipient == nil {
+			c.SendError("not_found", "Client not found")
+			return
+		}
+		recipientId = &recipient.UserId
+	}
+
+	message, err := ctx.ChatService.PostMessage(c.UserId, m.currentRoom().ID, services.MessageDraft{
+		Body:         payload.Message,
+		ReplyTo:      payload.ReplyTo,
+		RecipientID:  recipientId,
+		AttachmentID: payload.AttachmentId,
+	})
+	if err != nil {
+		c.sendChatError(err)
+		return
+	}
+
+	// The sender gets the message back too, to learn its ID and timestamp
+	outbound := ChatMessagePayload(message)
+	outbound.ClientId = c.Id
+	m.BroadcastChat(
+		message,
+		&messages.OutboundWsMessage{
+			Type:    messages.OutboudData,
+			Payload: &outbound,
+		},
+		nil,
+	)
+}
+
+func (c *Client) editChatMessage(ctx *RouterCtx, m *Meeting, payload *messages.InboundChatEditPayload) {
+	message, err := ctx.ChatService.EditMessage(c.UserId, m.currentRoom().ID, payload.MessageId, payload.Message)
+	if err != nil {
+		c.sendChatError(err)
+		return
+	}
+
+	c.broadcastChatUpdate(m, messages.OutboundChatEdited, message)
+}
+
+func (c *Client) deleteChatMessage(ctx *RouterCtx, m *Meeting, payload *messages.InboundChatDeletePayload) {
+	message, err := ctx.ChatService.DeleteMessage(c.UserId, m.currentRoom().ID, payload.MessageId)
+	if err != nil {
+		c.sendChatError(err)
+		return
+	}
+
+	c.broadcastChatUpdate(m, messages.OutboundChatDeleted, message)
+}
+
+func (c *Client) reactToChatMessage(ctx *RouterCtx, m *Meeting, payload *messages.InboundChatReactPayload) {
+	message, err := ctx.ChatService.React(c.UserId, m.currentRoom().ID, payload.MessageId, payload.Emoji, payload.Remove)
+	if err != nil {
+		c.sendChatError(err)
+		return
+	}
+
+	c.broadcastChatUpdate(m, messages.OutboundChatReacted, message)
+}
+
+// broadcastChatUpdate tells the other clients about a change the client made
+// to a chat message
+func (c *Client) broadcastChatUpdate(m *Meeting, messageType messages.OutboundMessageType, message *models.ChatMessage) {
+	outbound := ChatMessagePayload(message)
+	outbound.ClientId = c.Id
+	m.BroadcastChat(
+		message,
+		&messages.OutboundWsMessage{
+			Type:    messageType,
+			Payload: &outbound,
+		},
+		c,
+	)
+}
+
+// BroadcastChat sends a chat event to every client that can see the message,
+// except the given one
+func (m *Meeting) BroadcastChat(message *models.ChatMessage, msg *messages.OutboundWsMessage, except *Client) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for client := range m.Clients {
+		if client != except && message.VisibleTo(client.UserId) {
+			client.Messages <- msg
+		}
+	}
+}
+
+// BroadcastChatWithBreakouts sends a chat event to the meeting and its
+// running breakouts, as everyone in them may have the message in their
+// history
+func (m *Meeting) BroadcastChatWithBreakouts(message *models.ChatMessage, msg *messages.OutboundWsMessage) {
+	for _, meeting := range m.withBreakouts() {
+		meeting.BroadcastChat(message, msg, nil)
+	}
+}
+
+// sendTyping relays the client's typing state to the meeting, it's never persisted
+func (c *Client) sendTyping(m *Meeting, payload *messages.InboundTypingPayload) {
+	now := time.Now()
+	if payload.Typing {
+		if c.typing && now.Sub(c.lastTyping) < typingInterval {
+			return
+		}
+		c.lastTyping = now
+	} else if !c.typing {
+		return
+	}
+	c.typing = payload.Typing
+
+	m.broadcastAbout(
+		c,
+		&messages.OutboundWsMessage{
+			Type: messages.OutboundTyping,
+			Payload: &messages.OutboundTypingPayload{
+				ClientId: c.Id,
+				UserId:   c.UserId,
+				Username: c.Username,
+				Typing:   payload.Typing,
+			},
+		},
+		c,
+	)
+}
+
+func (c *Client) markChatRead(ctx *RouterCtx, m *Meeting, payload *messages.InboundChatReadPayload) {
+	message, err := ctx.ChatService.MarkRead(c.UserId, m.currentRoom().ID, payload.MessageId)
+	if err != nil {
+		c.sendChatError(err)
+		return
+	}
+
+	// Read receipts are only shown to the clients that can see the message
+	m.BroadcastChat(
+		message,
+		&messages.OutboundWsMessage{
+			Type: messages.OutboundChatRead,
+			Payload: &messages.OutboundChatReadPayload{
+				ClientId:  c.Id,
+				UserId:    c.UserId,
+				MessageId: message.ID,
+			},
+		},
+		c,
+	)
+}
+
+func (c *Client) sendChatError(err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidMessage):
+		c.SendError("invalid_message", "Message must be between 1 and 4000 characters")
+	case errors.Is(err, services.ErrInvalidEmoji):
+		c.SendError("invalid_emoji", "Invalid emoji")
+	case errors.Is(err, services.ErrMessageNotFound):
+		c.SendError("not_found", "Message not found")
+	case errors.Is(err, services.ErrMessageForbidden):
+		c.SendError("forbidden", "You can't change this message")
+	case errors.Is(err, services.ErrAttachmentNotFound):
+		c.SendError("not_found", "Attachment not found")
+	case errors.Is(err, services.ErrInvalidRecipient):
+		c.SendError("invalid_recipient", "Recipient is not a participant of the room")
+	default:
+		log.Printf("Failed to update chat: %v", err)
+		c.SendError("internal", "Failed to update chat")
+	}
+}
+
+// sendChatHistory delivers the room's most recent chat messages to the client
+func (ctx *RouterCtx) sendChatHistory(c *Client, roomID uint) {
+	history, err := ctx.ChatService.History(c.UserId, roomID, 0, chatHistoryLimit)
+	if err != nil {
+		log.Printf("Failed to load chat history: %v", err)
+		return
+	}
+
+	c.Messages <- &messages.OutboundWsMessage{
+		Type: messages.OutboundChatHistory,
+		Payload: &messages.OutboundChatHistoryPayload{
+			Messages: utils.Map(history, ChatMessagePayload),
+			HasMore:  len(history) == chatHistoryLimit,
+		},
+	}
+}
+
+// ChatMessagePayload converts a chat message into its WebSocket representation
+func ChatMessagePayload(message *models.ChatMessage) messages.OutboundDataPayload {
+	payload := messages.OutboundDataPayload{
+		Id:        message.ID,
+		UserId:    message.AuthorID,
+		Username:  message.Author.Username,
+		Message:   message.Body,
+		ReplyTo:   message.ReplyToID,
+		Recipient: message.RecipientID,
+		CreatedAt: message.CreatedAt,
+		EditedAt:  message.EditedAt,
+		Reactions: make([]messages.ChatReaction, 0),
+	}
+
+	if message.IsDeleted() {
+		payload.Message = ""
+		payload.Deleted = true
+		return payload
+	}
+
+	if message.Attachment != nil {
+		payload.Attachment = &messages.ChatAttachment{
+			Id:          message.Attachment.ID,
+			FileName:    message.Attachment.FileName,
+			ContentType: message.Attachment.ContentType,
+			Size:        message.Attachment.Size,
+		}
+	}
+
+	// Group reactions by emoji, keeping the order they were first used in
+	index := make(map[string]int)
+	for _, reaction := range message.Reactions {
+		i, ok := index[reaction.Emoji]
+		if !ok {
+			i = len(payload.Reactions)
+			index[reaction.Emoji] = i
+			payload.Reactions = append(payload.Reactions, messages.ChatReaction{Emoji: reaction.Emoji})
+		}
+		payload.Reactions[i].UserIds = append(payload.Reactions[i].UserIds, reaction.UserID)
+	}
+
+	return payload
+}
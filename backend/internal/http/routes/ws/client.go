@@ -4,10 +4,14 @@ import (
 	"encoding/json"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/pion/webrtc/v4"
 	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/sfu"
 )
 
 const (
@@ -15,14 +19,152 @@ const (
 	pingPeriod = (pongWait * 9) / 10
 )
 
+// inboundPermissions lists the room permission required to send a message type.
+// Types missing from the map (signaling) are allowed for every participant.
+var inboundPermissions = map[messages.InboundMessageType]models.Permission{
+	messages.InboundData:               models.PermissionSendData,
+	messages.InboundTrackMuted:         models.PermissionPublishMedia,
+	messages.InboundTrackUnmuted:       models.PermissionPublishMedia,
+	messages.InboundStreamMetadata:     models.PermissionPublishMedia,
+	messages.InboundScreenShareStarted: models.PermissionPublishMedia,
+	messages.InboundScreenShareStopped: models.PermissionPublishMedia,
+	messages.InboundMuteParticipant:    models.PermissionModerate,
+	messages.InboundKickParticipant:    models.PermissionModerate,
+	messages.InboundStopScreenShare:    models.PermissionModerate,
+	messages.InboundLockMeeting:        models.PermissionModerate,
+	messages.InboundAdmit:              models.PermissionModerate,
+	messages.InboundDeny:               models.PermissionModerate,
+	messages.InboundChatEdit:           models.PermissionSendData,
+	messages.InboundChatDelete:         models.PermissionSendData,
+	messages.InboundChatReact:          models.PermissionSendData,
+	messages.InboundTyping:             models.PermissionSendData,
+	messages.InboundRaiseHand:          models.PermissionSendData,
+	messages.InboundLowerHand:          models.PermissionSendData,
+	messages.InboundClearHands:         models.PermissionModerate,
+	messages.InboundMoveHand:           models.PermissionModerate,
+	messages.InboundPollCreate:         models.PermissionModerate,
+	messages.InboundPollVote:           models.PermissionSendData,
+	messages.InboundPollClose:          models.PermissionModerate,
+	messages.InboundBreakoutCreate:     models.PermissionModerate,
+	messages.InboundBreakoutEnd:        models.PermissionModerate,
+	messages.InboundBreakoutBroadcast:  models.PermissionModerate,
+	messages.InboundStartRecording:     models.PermissionModerate,
+	messages.InboundStopRecording:      models.PermissionModerate,
+	messages.InboundAudioLevel:         models.PermissionPublishMedia,
+	messages.InboundCaptionAudio:       models.PermissionPublishMedia,
+	messages.InboundWbOp:               models.PermissionSendData,
+}
+
 type Client struct {
 	Id       string
+	UserId   uint
 	Username string
+	Role     models.Role
 	Conn     *websocket.Conn
 	Messages chan *messages.OutboundWsMessage
+	mu       sync.RWMutex
+	done     chan struct{}
+	doneOnce sync.Once
+
+	// Meeting the client is in, changes when it's moved to a breakout
+	meeting   *Meeting
+	meetingMu sync.Mutex
+	left      bool
+
+	// Typing indicator state, only touched by the Reader goroutine
+	typing     bool
+	lastTyping time.Time
+}
+
+func NewClient(id string, userId uint, username string, role models.Role, conn *websocket.Conn) *Client {
+	return &Client{
+		Id:       id,
+		UserId:   userId,
+		Username: username,
+		Role:     role,
+		Conn:     conn,
+		Messages: make(chan *messages.OutboundWsMessage, 1024),
+		done:     make(chan struct{}),
+	}
+}
+
+// Disconnect asks the writer to flush pending messages and close the connection
+func (c *Client) Disconnect() {
+	c.doneOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *Client) GetRole() models.Role {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Role
+}
+
+func (c *Client) SetRole(role models.Role) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Role = role
+}
+
+// Can reports whether the client's current role grants the permission
+func (c *Client) Can(permission models.Permission) bool {
+	return c.GetRole().Can(permission)
+}
+
+// Meeting returns the meeting the client is currently in
+func (c *Client) Meeting() *Meeting {
+	c.meetingMu.Lock()
+	defer c.meetingMu.Unlock()
+	return c.meeting
+}
+
+// moveTo moves the client from one meeting to another over the same
+// connection. Nothing happens if the client has disconnected or was moved
+// elsewhere in the meantime.
+func (c *Client) moveTo(from *Meeting, to *Meeting, payload *messages.OutboundMoveToBreakoutPayload) {
+	c.meetingMu.Lock()
+	defer c.meetingMu.Unlock()
+
+	if c.left || c.meeting != from {
+		return
+	}
+
+	from.Leave(c)
+	c.meeting = to
+	c.Messages <- &messages.OutboundWsMessage{
+		Type:    messages.OutboundMoveToBreakout,
+		Payload: payload,
+	}
+
+	if err := to.Join(c); err != nil {
+		c.SendError(err.Error(), "The meeting is full")
+		c.Disconnect()
+	}
+}
+
+// leaveMeeting removes the client from its current meeting once it disconnects
+func (c *Client) leaveMeeting() {
+	c.meetingMu.Lock()
+	c.left = true
+	m := c.meeting
+	c.meetingMu.Unlock()
+
+	m.Leave(c)
+
+	// The session is over once the last client is gone, breakouts included
+	if main := m.mainMeeting(); main.idle() {
+		for _, meeting := range main.withBreakouts() {
+			meeting.release()
+		}
+		go main.endSession()
+	}
 }
 
 func (c *Client) Broadcast(m *Meeting, msg *messages.OutboundWsMessage) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	for peer := range m.Clients {
 		if c != peer {
 			peer.Messages <- msg
@@ -31,6 +173,9 @@ func (c *Client) Broadcast(m *Meeting, msg *messages.OutboundWsMessage) {
 }
 
 func (c *Client) Send(m *Meeting, receiverId string, msg *messages.OutboundWsMessage) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	for peer := range m.Clients {
 		if peer.Id == receiverId {
 			peer.Messages <- msg
@@ -38,10 +183,21 @@ func (c *Client) Send(m *Meeting, receiverId string, msg *messages.OutboundWsMes
 	}
 }
 
-func (c *Client) Reader(m *Meeting) {
+// SendError notifies the client that one of its messages was rejected
+func (c *Client) SendError(code string, message string) {
+	c.Messages <- &messages.OutboundWsMessage{
+		Type: messages.OutboundError,
+		Payload: &messages.OutboundErrorPayload{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+func (c *Client) Reader(ctx *RouterCtx) {
 	defer func() {
 		c.Conn.Close()
-		m.Leave(c)
+		c.leaveMeeting()
 	}()
 
 	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
@@ -70,19 +226,38 @@ func (c *Client) Reader(m *Meeting) {
 			continue
 		}
 
+		// Moderators may move the client between breakouts at any time
+		m := c.Meeting()
+
+		if m.IsPending(c) {
+			c.SendError("lobby", "Waiting to be admitted to the meeting")
+			continue
+		}
+
+		if permission, ok := inboundPermissions[wsMessage.Type]; ok && !c.Can(permission) {
+			c.SendError("forbidden", "Your role does not allow '"+string(wsMessage.Type)+"' messages")
+			continue
+		}
+
 		payload := payloadFunc()
 		json.Unmarshal(wsMessage.Payload, payload)
 
 		switch payload := payload.(type) {
 		case *messages.InboundDataPayload:
-			c.Broadcast(
-				m,
-				&messages.OutboundWsMessage{
-					Type:    messages.OutboudData,
-					Payload: wsMessage.Payload,
-				},
-			)
+			c.sendChatMessage(ctx, m, payload)
 		case *messages.InboundOfferPayload:
+			description, ok := c.applySDPPolicy(m, payload.Value.Sdp)
+			if !ok {
+				break
+			}
+			payload.Value.Sdp = description
+			c.unmuteSentTracks(m, description)
+
+			if sfu.IsServerID(payload.ClientId) {
+				c.signalSFU(m, sfu.Signal{Target: payload.ClientId, Description: sessionDescription(webrtc.SDPTypeOffer, payload.Value.Sdp)})
+				break
+			}
+
 			c.Send(
 				m,
 				payload.ClientId,
@@ -96,6 +271,18 @@ func (c *Client) Reader(m *Meeting) {
 				},
 			)
 		case *messages.InboundAnswerPayload:
+			description, ok := c.applySDPPolicy(m, payload.Value.Sdp)
+			if !ok {
+				break
+			}
+			payload.Value.Sdp = description
+			c.unmuteSentTracks(m, description)
+
+			if sfu.IsServerID(payload.ClientId) {
+				c.signalSFU(m, sfu.Signal{Target: payload.ClientId, Description: sessionDescription(webrtc.SDPTypeAnswer, payload.Value.Sdp)})
+				break
+			}
+
 			c.Send(
 				m,
 				payload.ClientId,
@@ -109,6 +296,11 @@ func (c *Client) Reader(m *Meeting) {
 				},
 			)
 		case *messages.InboundIceCandidatePayload:
+			if sfu.IsServerID(payload.ClientId) {
+				c.signalSFU(m, sfu.Signal{Target: payload.ClientId, Candidate: iceCandidate(payload.Value)})
+				break
+			}
+
 			c.Send(
 				m,
 				payload.ClientId,
@@ -122,6 +314,7 @@ func (c *Client) Reader(m *Meeting) {
 				},
 			)
 		case *messages.InboundTrackMutedPayload:
+			m.setTrackMuted(c, payload.TrackKind, true)
 			c.Broadcast(
 				m,
 				&messages.OutboundWsMessage{
@@ -132,7 +325,29 @@ func (c *Client) Reader(m *Meeting) {
 					},
 				},
 			)
+		case *messages.InboundTrackUnmutedPayload:
+			if payload.TrackKind == "video" && !c.videoAllowed(m) {
+				break
+			}
+
+			m.setTrackMuted(c, payload.TrackKind, false)
+			c.Broadcast(
+				m,
+				&messages.OutboundWsMessage{
+					Type: messages.OutboundTrackUnmuted,
+					Payload: &messages.OutboundTrackUnmutedPayload{
+						ClientId:  c.Id,
+						TrackKind: payload.TrackKind,
+					},
+				},
+			)
 		case *messages.InboundStreamMetadataPayload:
+			// Screens are shared as video
+			if payload.StreamType == "screen" && !c.videoAllowed(m) {
+				break
+			}
+
+			m.setStreamType(c, payload.StreamId, payload.StreamType)
 			c.Broadcast(
 				m,
 				&messages.OutboundWsMessage{
@@ -145,6 +360,11 @@ func (c *Client) Reader(m *Meeting) {
 				},
 			)
 		case *messages.InboundScreenShareStartedPayload:
+			if !c.videoAllowed(m) {
+				break
+			}
+
+			m.setScreenSharing(c, true)
 			c.Broadcast(
 				m,
 				&messages.OutboundWsMessage{
@@ -155,6 +375,7 @@ func (c *Client) Reader(m *Meeting) {
 				},
 			)
 		case *messages.InboundScreenShareStoppedPayload:
+			m.setScreenSharing(c, false)
 			c.Broadcast(
 				m,
 				&messages.OutboundWsMessage{
@@ -164,6 +385,62 @@ func (c *Client) Reader(m *Meeting) {
 					},
 				},
 			)
+		case *messages.InboundMuteParticipantPayload:
+			c.muteParticipant(m, payload)
+		case *messages.InboundKickParticipantPayload:
+			c.kickParticipant(m, payload)
+		case *messages.InboundStopScreenSharePayload:
+			c.stopScreenShare(m, payload)
+		case *messages.InboundLockMeetingPayload:
+			c.lockMeeting(m, payload)
+		case *messages.InboundAdmitPayload:
+			c.admit(ctx, m, payload)
+		case *messages.InboundDenyPayload:
+			c.deny(m, payload)
+		case *messages.InboundChatEditPayload:
+			c.editChatMessage(ctx, m, payload)
+		case *messages.InboundChatDeletePayload:
+			c.deleteChatMessage(ctx, m, payload)
+		case *messages.InboundChatReactPayload:
+			c.reactToChatMessage(ctx, m, payload)
+		case *messages.InboundTypingPayload:
+			c.sendTyping(m, payload)
+		case *messages.InboundChatReadPayload:
+			c.markChatRead(ctx, m, payload)
+		case *messages.InboundRaiseHandPayload:
+			c.raiseHand(m)
+		case *messages.InboundLowerHandPayload:
+			c.lowerHand(m, payload)
+		case *messages.InboundClearHandsPayload:
+			c.clearHands(m)
+		case *messages.InboundMoveHandPayload:
+			c.moveHand(m, payload)
+		case *messages.InboundPollCreatePayload:
+			c.createPoll(ctx, m, payload)
+		case *messages.InboundPollVotePayload:
+			c.votePoll(ctx, m, payload)
+		case *messages.InboundPollClosePayload:
+			c.closePoll(ctx, m, payload)
+		case *messages.InboundBreakoutCreatePayload:
+			c.createBreakouts(ctx, m, payload)
+		case *messages.InboundBreakoutEndPayload:
+			c.endBreakouts(ctx, m)
+		case *messages.InboundBreakoutBroadcastPayload:
+			c.broadcastToBreakouts(m, payload)
+		case *messages.InboundStartRecordingPayload:
+			c.startRecording(ctx, m)
+		case *messages.InboundStopRecordingPayload:
+			c.stopRecording(ctx, m)
+		case *messages.InboundRtcStatsPayload:
+			c.reportStats(m, payload)
+		case *messages.InboundAudioLevelPayload:
+			c.reportAudioLevel(m, payload)
+		case *messages.InboundViewportSubscriptionPayload:
+			c.subscribeViewport(m, payload)
+		case *messages.InboundCaptionAudioPayload:
+			c.captionAudio(m, payload)
+		case *messages.InboundWbOpPayload:
+			c.applyWhiteboardOp(m, payload)
 		}
 
 	}
@@ -188,6 +465,20 @@ func (c *Client) Writer() {
 				return
 			}
 
+		case <-c.done:
+			// Flush already queued messages (e.g. a kick notice) before closing
+			for {
+				select {
+				case message := <-c.Messages:
+					if err := c.Conn.WriteJSON(message); err != nil {
+						return
+					}
+				default:
+					c.Conn.WriteMessage(websocket.CloseMessage, nil)
+					return
+				}
+			}
+
 		case <-ticker.C:
 			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
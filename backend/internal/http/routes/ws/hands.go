@@ -0,0 +1,139 @@
+package ws
+
+import (
+	"slices"
+	"time"
+
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/utils"
+)
+
+type raisedHand struct {
+	client   *Client
+	raisedAt time.Time
+}
+
+func (c *Client) raiseHand(m *Meeting) {
+	m.mu.Lock()
+	raised := m.findHand(c) == -1
+	if raised {
+		m.hands = append(m.hands, raisedHand{client: c, raisedAt: time.Now()})
+	}
+	m.mu.Unlock()
+
+	if raised {
+		m.broadcastHandQueue(c)
+	}
+}
+
+func (c *Client) lowerHand(m *Meeting, payload *messages.InboundLowerHandPayload) {
+	target := c
+	if payload.ClientId != "" && payload.ClientId != c.Id {
+		if !c.Can(models.PermissionModerate) {
+			c.SendError("forbidden", "You can't lower other participants' hands")
+			return
+		}
+
+		target = c.moderationTarget(m, payload.ClientId)
+		if target == nil {
+			return
+		}
+	}
+
+	m.mu.Lock()
+	lowered := m.removeHand(target)
+	m.mu.Unlock()
+
+	if lowered {
+		m.broadcastHandQueue(c)
+	}
+}
+
+func (c *Client) clearHands(m *Meeting) {
+	m.mu.Lock()
+	cleared := len(m.hands) > 0
+	m.hands = nil
+	m.mu.Unlock()
+
+	if cleared {
+		m.broadcastHandQueue(c)
+	}
+}
+
+func (c *Client) moveHand(m *Meeting, payload *messages.InboundMoveHandPayload) {
+	m.mu.Lock()
+	i := slices.IndexFunc(m.hands, func(hand raisedHand) bool {
+		return hand.client.Id == payload.ClientId
+	})
+	if i == -1 {
+		m.mu.Unlock()
+		c.SendError("not_found", "Participant has not raised their hand")
+		return
+	}
+
+	hand := m.hands[i]
+	m.hands = slices.Delete(m.hands, i, i+1)
+	position := min(max(payload.Position, 0), len(m.hands))
+	m.hands = slices.Insert(m.hands, position, hand)
+	m.mu.Unlock()
+
+	m.broadcastHandQueue(c)
+}
+
+// broadcastHandQueue shares the current speaking queue after a change, the
+// client who made it doesn't need it. Webinar attendees only see the hands of
+// the hosts and their own.
+func (m *Meeting) broadcastHandQueue(c *Client) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for client := range m.Clients {
+		if client == c {
+			continue
+		}
+
+		payload := &messages.OutboundHandQueuePayload{Queue: m.handQueue(client)}
+		if c != nil && !m.hiddenFrom(c, client) {
+			payload.ClientId = c.Id
+		}
+		client.Messages <- &messages.OutboundWsMessage{
+			Type:    messages.OutboundHandQueue,
+			Payload: payload,
+		}
+	}
+}
+
+// handQueue returns the speaking queue as the recipient may see it, the
+// caller must hold m.mu
+func (m *Meeting) handQueue(recipient *Client) []messages.RaisedHand {
+	hands := utils.Filter(m.hands, func(hand raisedHand) bool {
+		return !m.hiddenFrom(hand.client, recipient)
+	})
+	return utils.Map(hands, func(hand raisedHand) messages.RaisedHand {
+		return messages.RaisedHand{
+			ClientId: hand.client.Id,
+			UserId:   hand.client.UserId,
+			Username: hand.client.Username,
+			RaisedAt: hand.raisedAt,
+		}
+	})
+}
+
+// findHand returns the client's position in the queue or -1, the caller must hold m.mu
+func (m *Meeting) findHand(c *Client) int {
+	return slices.IndexFunc(m.hands, func(hand raisedHand) bool {
+		return hand.client == c
+	})
+}
+
+// removeHand takes the client out of the queue, the caller must hold m.mu
+func (m *Meeting) removeHand(c *Client) bool {
+	i := m.findHand(c)
+	if i == -1 {
+		return false
+	}
+
+	m.hands = slices.Delete(m.hands, i, i+1)
+	return true
+}
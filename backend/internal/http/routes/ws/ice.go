@@ -0,0 +1,34 @@
+package ws
+
+import (
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/utils"
+)
+
+// UseICE lets the meeting hand out ICE servers and TURN credentials with
+// every init
+func (m *Meeting) UseICE(service *services.ICEService) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ice = service
+}
+
+// iceServers issues fresh ICE servers and TURN credentials for the user, as
+// credentials issued on connect expire while the client moves between
+// breakouts. The caller must hold m.mu.
+func (m *Meeting) iceServers(userId uint) []messages.IceServer {
+	if m.ice == nil {
+		return []messages.IceServer{}
+	}
+
+	servers, _ := m.ice.Servers(userId)
+	return utils.Map(servers, func(server services.ICEServer) messages.IceServer {
+		return messages.IceServer{
+			Urls:       server.URLs,
+			Username:   server.Username,
+			Credential: server.Credential,
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package ws
+
+import (
+	"log"
+	"slices"
+
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/sfu"
+)
+
+const maxViewportPeers = 100
+
+// layer is a simulcast layer publishers encode their video at, ordered from
+// nothing to full resolution
+type layer int
+
+const (
+	layerOff layer = iota
+	layerLow
+	layerMedium
+	layerHigh
+)
+
+var layerNames = [...]string{"off", "low", "medium", "high"}
+
+// Nominal heights of the layers, publishers scale their video down to them
+var layerHeights = [...]int{0, 180, 360, 720}
+
+func (l layer) String() string {
+	return layerNames[l]
+}
+
+// viewportLayer picks the smallest layer that still looks sharp at the
+// rendered height
+func viewportLayer(height int) layer {
+	switch {
+	case height >= 540:
+		return layerHigh
+	case height >= 270:
+		return layerMedium
+	case height > 0:
+		return layerLow
+	default:
+		return layerOff
+	}
+}
+
+func (c *Client) subscribeViewport(m *Meeting, payload *messages.InboundViewportSubscriptionPayload) {
+	if len(payload.Subscriptions) > maxViewportPeers || slices.ContainsFunc(payload.Subscriptions, invalidViewport) {
+		c.SendError("invalid_viewport", "Viewports must list at most 100 peers with non-negative sizes")
+		return
+	}
+
+	m.mu.Lock()
+	previous := m.viewports[c]
+
+	// Peers that joined since are rendered in full until the next subscription
+	layers := map[string]layer{}
+	for client := range m.Clients {
+		if client != c {
+			layers[client.Id] = layerOff
+		}
+	}
+	for _, subscription := range payload.Subscriptions {
+		// A peer may be rendered more than once, e.g. pinned and in the grid
+		if current, ok := layers[subscription.ClientId]; ok {
+			layers[subscription.ClientId] = max(current, viewportLayer(subscription.Height))
+		}
+	}
+	m.viewports[c] = layers
+
+	session := m.sfu
+	if session != nil {
+		m.sendPublisherLayers()
+	} else {
+		for client := range m.Clients {
+			current, ok := layers[client.Id]
+			before, known := previous[client.Id]
+			if !known {
+				before = layerHigh
+			}
+			if ok && current != before && client.Can(models.PermissionPublishMedia) {
+				client.Messages <- layerPreference(c.Id, current)
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	if session == nil {
+		return
+	}
+
+	var paused []string
+	for id, l := range layers {
+		if l == layerOff {
+			paused = append(paused, id)
+		}
+	}
+	if err := session.PauseVideo(c.Id, paused); err != nil {
+		log.Printf("Failed to pause video for client %s: %v", c.Id, err)
+	}
+}
+
+func invalidViewport(subscription messages.ViewportSubscription) bool {
+	return subscription.ClientId == "" || subscription.Width < 0 || subscription.Height < 0
+}
+
+// updatePublisherLayers lets publishers know when the layer they're
+// rendered at changed because someone joined or left
+func (m *Meeting) updatePublisherLayers() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.sfu != nil {
+		m.sendPublisherLayers()
+	}
+}
+
+// sendPublisherLayers hints every publisher the highest layer any subscriber
+// renders it at, as the SFU forwards the same layer to everyone. Clients
+// that never sent a subscription render everyone in full. The caller must
+// hold m.mu.
+func (m *Meeting) sendPublisherLayers() {
+	for publisher := range m.Clients {
+		if !publisher.Can(models.PermissionPublishMedia) {
+			continue
+		}
+
+		highest := layerOff
+		subscribers := 0
+		for subscriber := range m.Clients {
+			if subscriber == publisher {
+				continue
+			}
+			subscribers++
+
+			l, ok := m.viewports[subscriber][publisher.Id]
+			if !ok {
+				l = layerHigh
+			}
+			highest = max(highest, l)
+		}
+
+		// Nobody else is in the meeting to render the video
+		if subscribers == 0 {
+			continue
+		}
+
+		before, ok := m.layers[publisher.Id]
+		if !ok {
+			before = layerHigh
+		}
+		if highest == before {
+			continue
+		}
+
+		m.layers[publisher.Id] = highest
+		publisher.Messages <- layerPreference(sfu.PublisherID, highest)
+	}
+}
+
+func layerPreference(clientId string, l layer) *messages.OutboundWsMessage {
+	return &messages.OutboundWsMessage{
+		Type: messages.OutboundLayerPreference,
+		Payload: &messages.OutboundLayerPreferencePayload{
+			ClientId:  clientId,
+			Layer:     l.String(),
+			MaxHeight: layerHeights[l],
+		},
+	}
+}
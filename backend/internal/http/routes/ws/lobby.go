@@ -0,0 +1,133 @@
+package ws
+
+import (
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/utils"
+	"golang.org/x/exp/maps"
+)
+
+// Wait places the client into the meeting's lobby until a moderator admits
+// or denies it
+func (m *Meeting) Wait(c *Client) {
+	m.mu.Lock()
+	m.pending[c] = true
+	m.mu.Unlock()
+
+	c.Messages <- &messages.OutboundWsMessage{
+		Type: messages.OutboundLobbyStatus,
+		Payload: &messages.OutboundLobbyStatusPayload{
+			Status: messages.LobbyWaiting,
+		},
+	}
+
+	m.BroadcastToModerators(
+		&messages.OutboundWsMessage{
+			Type: messages.OutboundLobbyJoinRequest,
+			Payload: &messages.OutboundLobbyJoinRequestPayload{
+				ClientId: c.Id,
+				UserId:   c.UserId,
+				Username: c.Username,
+			},
+		},
+	)
+}
+
+func (m *Meeting) IsPending(c *Client) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.pending[c]
+}
+
+// getPending returns the waiting client with the given ID, or nil
+func (m *Meeting) getPending(id string) *Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for client := range m.pending {
+		if client.Id == id {
+			return client
+		}
+	}
+	return nil
+}
+
+// getPendingUserClients returns the waiting clients of the given user
+func (m *Meeting) getPendingUserClients(userId uint) []*Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return utils.Filter(
+		maps.Keys(m.pending),
+		func(client *Client) bool {
+			return client.UserId == userId
+		},
+	)
+}
+
+// removePending takes the client out of the lobby, reporting whether it was there
+func (m *Meeting) removePending(c *Client) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.pending[c]
+	delete(m.pending, c)
+	return ok
+}
+
+// notifyLobbyResolved lets moderators know a join request is no longer pending
+func (m *Meeting) notifyLobbyResolved(c *Client, status messages.LobbyStatus) {
+	m.BroadcastToModerators(
+		&messages.OutboundWsMessage{
+			Type: messages.OutboundLobbyJoinResolved,
+			Payload: &messages.OutboundLobbyJoinResolvedPayload{
+				ClientId: c.Id,
+				Status:   status,
+			},
+		},
+	)
+}
+
+func (c *Client) admit(ctx *RouterCtx, m *Meeting, payload *messages.InboundAdmitPayload) {
+	target := m.getPending(payload.ClientId)
+	if target == nil || !m.removePending(target) {
+		c.SendError("not_found", "Participant is not waiting in the lobby")
+		return
+	}
+
+	if err := m.Join(target); err != nil {
+		// Keep waiting until someone leaves
+		m.mu.Lock()
+		m.pending[target] = true
+		m.mu.Unlock()
+
+		c.SendError(err.Error(), "The meeting is full")
+		return
+	}
+
+	target.Messages <- &messages.OutboundWsMessage{
+		Type: messages.OutboundLobbyStatus,
+		Payload: &messages.OutboundLobbyStatusPayload{
+			Status: messages.LobbyAdmitted,
+		},
+	}
+	m.notifyLobbyResolved(target, messages.LobbyAdmitted)
+	ctx.sendChatHistory(target, m.currentRoom().ID)
+}
+
+func (c *Client) deny(m *Meeting, payload *messages.InboundDenyPayload) {
+	target := m.getPending(payload.ClientId)
+	if target == nil || !m.removePending(target) {
+		c.SendError("not_found", "Participant is not waiting in the lobby")
+		return
+	}
+
+	target.Messages <- &messages.OutboundWsMessage{
+		Type: messages.OutboundLobbyStatus,
+		Payload: &messages.OutboundLobbyStatusPayload{
+			Status: messages.LobbyDenied,
+			Reason: payload.Reason,
+		},
+	}
+	m.notifyLobbyResolved(target, messages.LobbyDenied)
+	target.Disconnect()
+}
@@ -0,0 +1,120 @@
+package ws
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/sdp"
+	"golang.org/x/exp/maps"
+)
+
+// mediaState tracks what a client announced about its media, so clients
+// joining later can be told about it in init
+type mediaState struct {
+	mutedTracks   []string          // Track kinds, "audio" | "video"
+	streams       map[string]string // Stream ID to "media" | "screen"
+	screenSharing bool
+}
+
+func (m *Meeting) setTrackMuted(c *Client, trackKind string, muted bool) {
+	m.updateMediaState(c, func(state *mediaState) {
+		state.mutedTracks = slices.DeleteFunc(state.mutedTracks, func(kind string) bool {
+			return kind == trackKind
+		})
+		if muted {
+			state.mutedTracks = append(state.mutedTracks, trackKind)
+		}
+	})
+}
+
+// unmuteSentTracks clears the mutes of the track kinds a session description
+// sends on the client's media stream. Clients unmute by adding a new track
+// and renegotiating, while muted tracks are removed from their connections.
+func (c *Client) unmuteSentTracks(m *Meeting, description string) {
+	parsed, err := sdp.Parse(description)
+	if err != nil {
+		return
+	}
+
+	for _, media := range parsed.MediaDescriptions {
+		kind := media.MediaName.Media
+		if !sdp.Sends(media) || !m.trackMuted(c, kind) {
+			continue
+		}
+
+		// Screens are shared as video on a stream of their own
+		msid, _ := media.Attribute("msid")
+		streamId, _, _ := strings.Cut(msid, " ")
+		if m.streamType(c, streamId) != "media" {
+			continue
+		}
+
+		m.setTrackMuted(c, kind, false)
+		c.Broadcast(
+			m,
+			&messages.OutboundWsMessage{
+				Type: messages.OutboundTrackUnmuted,
+				Payload: &messages.OutboundTrackUnmutedPayload{
+					ClientId:  c.Id,
+					TrackKind: kind,
+				},
+			},
+		)
+	}
+}
+
+func (m *Meeting) trackMuted(c *Client, trackKind string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state, ok := m.media[c]
+	return ok && slices.Contains(state.mutedTracks, trackKind)
+}
+
+func (m *Meeting) streamType(c *Client, streamId string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state, ok := m.media[c]
+	if !ok {
+		return ""
+	}
+	return state.streams[streamId]
+}
+
+func (m *Meeting) setStreamType(c *Client, streamId string, streamType string) {
+	m.updateMediaState(c, func(state *mediaState) {
+		state.streams[streamId] = streamType
+	})
+}
+
+func (m *Meeting) setScreenSharing(c *Client, sharing bool) {
+	m.updateMediaState(c, func(state *mediaState) {
+		state.screenSharing = sharing
+
+		// Screen streams end with the share, the next one gets a new stream ID
+		if !sharing {
+			maps.DeleteFunc(state.streams, func(_ string, streamType string) bool {
+				return streamType == "screen"
+			})
+		}
+	})
+}
+
+func (m *Meeting) updateMediaState(c *Client, update func(state *mediaState)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Clients that already left have no state to update
+	if !m.Clients[c] {
+		return
+	}
+
+	state, ok := m.media[c]
+	if !ok {
+		state = &mediaState{streams: map[string]string{}}
+		m.media[c] = state
+	}
+	update(state)
+}
@@ -1,66 +1,198 @@
 package ws
 
 import (
+	"slices"
+	"sync"
+	"time"
+
 	"github.com/serozhenka/shary/internal/messages"
 	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/sdp"
+	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/sfu"
+	"github.com/serozhenka/shary/internal/speaker"
+	"github.com/serozhenka/shary/internal/transcription"
 	"github.com/serozhenka/shary/internal/utils"
+	"github.com/serozhenka/shary/internal/whiteboard"
 	"golang.org/x/exp/maps"
 )
 
 type Meeting struct {
-	Clients map[*Client]bool
-	Room    *models.Room
+	Clients   map[*Client]bool
+	room      *models.Room     // Refreshed on every connect, nil for meetings outside a room
+	pending   map[*Client]bool // Clients waiting in the lobby
+	locked    bool
+	capacity  int          // Maximum publishing participants, 0 means unlimited
+	overflow  bool         // Join late arrivals as viewers instead of rejecting them
+	hands     []raisedHand // Speaking queue, in order
+	media     map[*Client]*mediaState
+	parent    *Meeting         // Main meeting of a breakout
+	breakouts *breakoutSession // Breakouts of a main meeting, nil unless running
+	sfu       *sfu.Session     // Set when media is forwarded by the server
+	recording *meetingRecording
+	policy    sdp.Policy // Enforced on offers and answers
+	ice       *services.ICEService
+	mode      models.RoomMode
+	quality   map[string]*clientQuality // Keyed by client ID, kept until the session ends
+	speakers  *speaker.Detector
+	viewports map[*Client]map[string]layer     // Layers each client renders its peers at
+	layers    map[string]layer                 // Layers hinted to publishers through the SFU
+	captions  map[*Client]transcription.Stream // Audio uploaded by clients for captions
+	board     *whiteboard.Board
+	boards    []services.FinalBoard // Of breakouts closed during the session
+	mu        sync.RWMutex
+
+	transcriber transcription.Transcriber
+	transcribed *sfu.Session               // SFU session whose audio is being transcribed
+	transcript  []models.TranscriptSegment // Final captions, kept until the session ends
+
+	sessions  *services.SessionService
+	session   *models.MeetingSession // Running session, nil while nobody is in the meeting
+	sessionMu sync.Mutex             // Serializes starting and ending sessions
 }
 
 func NewMeeting() *Meeting {
 	return &Meeting{
-		Clients: map[*Client]bool{},
-		Room:    nil,
+		Clients:   map[*Client]bool{},
+		pending:   map[*Client]bool{},
+		media:     map[*Client]*mediaState{},
+		policy:    sdp.Policy{Simulcast: true},
+		mode:      models.RoomModeMeeting,
+		quality:   map[string]*clientQuality{},
+		speakers:  speaker.NewDetector(speaker.DefaultConfig),
+		viewports: map[*Client]map[string]layer{},
+		layers:    map[string]layer{},
+		captions:  map[*Client]transcription.Stream{},
+		board:     &whiteboard.Board{},
 	}
 }
 
-func (m *Meeting) Join(c *Client) {
+// SetRoom keeps the room the meeting is held in up to date, e.g. after a
+// rename
+func (m *Meeting) SetRoom(room *models.Room) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.room = room
+}
+
+func (m *Meeting) currentRoom() *models.Room {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.room
+}
+
+// Join adds the client to the meeting, failing with ErrMeetingFull once the
+// meeting has reached its capacity
+func (m *Meeting) Join(c *Client) error {
+	m.mu.Lock()
+	c.SetRole(m.modeRole(c.GetRole()))
+	if err := m.checkCapacity(c); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+
 	m.Clients[c] = true
 	c.Messages <- &messages.OutboundWsMessage{
 		Type: messages.OutboudInit,
 		Payload: &messages.OutboundInitPayload{
+			Role:   string(c.GetRole()),
+			Locked: m.locked,
 			Clients: func() []messages.InitClient {
 				clients := maps.Keys(m.Clients)
 				filteredClients := utils.Filter(
 					clients,
 					func(roomClient *Client) bool {
-						return roomClient != c
+						return roomClient != c && !m.hiddenFrom(roomClient, c)
 					},
 				)
 
-				return utils.Map(
-					filteredClients,
-					func(client *Client) messages.InitClient {
-						return messages.InitClient{
-							Id:       client.Id,
-							Username: client.Username,
-						}
-					},
-				)
+				return utils.Map(filteredClients, m.initClient)
 			}(),
+			Lobby: func() []messages.InitClient {
+				if !c.Can(models.PermissionModerate) {
+					return nil
+				}
+				return utils.Map(maps.Keys(m.pending), m.initClient)
+			}(),
+			Hands:           m.handQueue(c),
+			SFU:             m.sfu != nil,
+			Mode:            string(m.mode),
+			AttendeeCount:   m.attendeeCount(),
+			ActiveSpeakerId: m.speakers.Dominant(),
+			RecordingId:     m.recordingId(),
+			IceServers:      m.iceServers(c.UserId),
+			Whiteboard:      m.board.Snapshot(),
 		},
 	}
-	c.Broadcast(
+	session := m.sfu
+	attendee := m.isAttendee(c)
+	m.mu.Unlock()
+
+	m.mainMeeting().startSession()
+
+	if session != nil {
+		m.transcribeSFU()
+		c.connectSFU(session)
+		// Publishers hinted to send less have a new subscriber to serve
+		m.updatePublisherLayers()
+	}
+
+	c.announce(
 		m,
+		attendee,
 		&messages.OutboundWsMessage{
 			Type: messages.OutboudClientJoined,
 			Payload: &messages.OutboundClientJoinedPayload{
 				ClientId: c.Id,
+				UserId:   c.UserId,
 				Username: c.Username,
+				Role:     string(c.GetRole()),
 			},
 		},
 	)
+
+	return nil
 }
 
 func (m *Meeting) Leave(c *Client) {
+	// Clients waiting in the lobby never joined the meeting
+	if m.removePending(c) {
+		m.notifyLobbyResolved(c, messages.LobbyLeft)
+		return
+	}
+
+	m.mu.Lock()
+	_, ok := m.Clients[c]
+	attendee := m.isAttendee(c)
 	delete(m.Clients, c)
-	c.Broadcast(
+	handLowered := m.removeHand(c)
+	delete(m.media, c)
+	speaker, speakerChanged := m.speakers.Remove(c.Id, time.Now())
+	delete(m.viewports, c)
+	delete(m.layers, c.Id)
+	captions := m.captions[c]
+	delete(m.captions, c)
+	session := m.sfu
+	m.mu.Unlock()
+
+	if captions != nil {
+		captions.Close()
+	}
+
+	// Client was already removed, e.g. after being kicked
+	if !ok {
+		return
+	}
+
+	if session != nil {
+		session.RemovePeer(c.Id)
+		m.updatePublisherLayers()
+	}
+
+	c.announce(
 		m,
+		attendee,
 		&messages.OutboundWsMessage{
 			Type: messages.OutboudClientLeft,
 			Payload: &messages.OutboundClientLeftPayload{
@@ -68,8 +200,183 @@ func (m *Meeting) Leave(c *Client) {
 			},
 		},
 	)
+
+	if handLowered {
+		m.broadcastHandQueue(nil)
+	}
+
+	if speakerChanged {
+		m.broadcastActiveSpeaker(speaker)
+	}
+}
+
+// release unlocks an empty meeting for whoever comes next and finishes its
+// recording, as nobody is left to record. Clients moved into breakouts are
+// still part of the meeting, so it's only released once they're gone too.
+func (m *Meeting) release() {
+	m.mu.Lock()
+	if len(m.Clients) == 0 {
+		m.locked = false
+	}
+	rec := m.takeRecording()
+	m.mu.Unlock()
+
+	if rec != nil {
+		go rec.finish("")
+	}
+}
+
+// Broadcast delivers a message to every client in the meeting
+func (m *Meeting) Broadcast(msg *messages.OutboundWsMessage) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for client := range m.Clients {
+		client.Messages <- msg
+	}
+}
+
+// BroadcastToModerators delivers a message to every client allowed to moderate
+func (m *Meeting) BroadcastToModerators(msg *messages.OutboundWsMessage) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for client := range m.Clients {
+		if client.Can(models.PermissionModerate) {
+			client.Messages <- msg
+		}
+	}
+}
+
+// GetUserClients returns all clients connected on behalf of the given user
+func (m *Meeting) GetUserClients(userId uint) []*Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return utils.Filter(
+		maps.Keys(m.Clients),
+		func(client *Client) bool {
+			return client.UserId == userId
+		},
+	)
+}
+
+// UserClientCount counts the clients the user has in the meeting and its
+// breakouts
+func (m *Meeting) UserClientCount(userId uint) int {
+	count := 0
+	for _, meeting := range m.withBreakouts() {
+		count += len(meeting.GetUserClients(userId))
+	}
+	return count
+}
+
+// SetUserRole updates the role of every client of the given user and lets the
+// meeting know about the change
+func (m *Meeting) SetUserRole(userId uint, role models.Role) {
+	// The user may have been moved to a breakout
+	for _, meeting := range m.withBreakouts() {
+		meeting.setUserRole(userId, role)
+	}
+}
+
+func (m *Meeting) setUserRole(userId uint, role models.Role) {
+	clients := m.GetUserClients(userId)
+	if len(clients) == 0 {
+		return
+	}
+
+	m.mu.RLock()
+	role = m.modeRole(role)
+	m.mu.RUnlock()
+
+	for _, client := range clients {
+		client.SetRole(role)
+	}
+
+	m.Broadcast(
+		&messages.OutboundWsMessage{
+			Type: messages.OutboundRoleChanged,
+			Payload: &messages.OutboundRoleChangedPayload{
+				UserId: userId,
+				Role:   string(role),
+			},
+		},
+	)
+	m.broadcastAttendeeCount()
+}
+
+// GetClient returns the client with the given ID, or nil if it's not in the meeting
+func (m *Meeting) GetClient(id string) *Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for client := range m.Clients {
+		if client.Id == id {
+			return client
+		}
+	}
+	return nil
+}
+
+// Kick removes every client of the given user from the meeting and closes
+// their connections after notifying them
+func (m *Meeting) Kick(userId uint, reason string) {
+	for _, client := range m.getPendingUserClients(userId) {
+		m.KickClient(client, &messages.OutboundKickedPayload{Reason: reason})
+	}
+
+	// The user may have been moved to a breakout
+	for _, meeting := range m.withBreakouts() {
+		for _, client := range meeting.GetUserClients(userId) {
+			meeting.KickClient(client, &messages.OutboundKickedPayload{Reason: reason})
+		}
+	}
+}
+
+// KickClient removes a single client from the meeting and closes its
+// connection after notifying it
+func (m *Meeting) KickClient(c *Client, payload *messages.OutboundKickedPayload) {
+	m.Leave(c)
+	c.Messages <- &messages.OutboundWsMessage{
+		Type:    messages.OutboundKicked,
+		Payload: payload,
+	}
+	c.Disconnect()
+}
+
+func (m *Meeting) IsLocked() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.locked
+}
+
+func (m *Meeting) SetLocked(locked bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.locked = locked
 }
 
 func (r *Meeting) GetParticipantCount() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return len(r.Clients)
 }
+
+// initClient describes a client to a newcomer, the caller must hold m.mu
+func (m *Meeting) initClient(client *Client) messages.InitClient {
+	initClient := messages.InitClient{
+		Id:       client.Id,
+		UserId:   client.UserId,
+		Username: client.Username,
+		Role:     string(client.GetRole()),
+	}
+
+	if state, ok := m.media[client]; ok {
+		initClient.MutedTracks = slices.Clone(state.mutedTracks)
+		initClient.Streams = maps.Clone(state.streams)
+		initClient.ScreenSharing = state.screenSharing
+	}
+
+	return initClient
+}
@@ -1,6 +1,10 @@
 package ws
 
-import "sync"
+import (
+	"sync"
+
+	"github.com/serozhenka/shary/internal/models"
+)
 
 type inMemoryMeetingManager struct {
 	rooms map[string]*Meeting
@@ -27,6 +31,22 @@ func (m *inMemoryMeetingManager) CreateMeeting(id string) *Meeting {
 	return meeting
 }
 
+// GetOrCreateMeeting sets the room before the meeting is published, so
+// concurrent connections never see a meeting without one
+func (m *inMemoryMeetingManager) GetOrCreateMeeting(id string, room *models.Room) *Meeting {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if meeting, ok := m.rooms[id]; ok {
+		return meeting
+	}
+
+	meeting := NewMeeting()
+	meeting.room = room
+	m.rooms[id] = meeting
+	return meeting
+}
+
 func (m *inMemoryMeetingManager) DeleteMeeting(id string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -1,7 +1,12 @@
 package ws
 
+import "github.com/serozhenka/shary/internal/models"
+
 type MeetingManager interface {
 	GetMeeting(id string) *Meeting
 	CreateMeeting(id string) *Meeting
+	// GetOrCreateMeeting returns the meeting, creating it for the room if
+	// it doesn't exist yet
+	GetOrCreateMeeting(id string, room *models.Room) *Meeting
 	DeleteMeeting(id string)
 }
@@ -0,0 +1,118 @@
+package ws
+
+import (
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/models"
+)
+
+// SetMode configures which media the meeting's clients may publish.
+// Webinars change the roles clients joined with, so like the SFU a webinar
+// only starts or ends once nobody is in the meeting.
+func (m *Meeting) SetMode(mode models.RoomMode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	webinar := mode == models.RoomModeWebinar || m.mode == models.RoomModeWebinar
+	if mode != m.mode && webinar && (len(m.Clients) > 0 || len(m.pending) > 0) {
+		return
+	}
+
+	m.mode = mode
+}
+
+func (m *Meeting) roomMode() models.RoomMode {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.mode
+}
+
+// modeRole returns the role a client holds in the meeting's mode, webinar
+// attendees receive media as viewers. The caller must hold m.mu.
+func (m *Meeting) modeRole(role models.Role) models.Role {
+	if m.mode == models.RoomModeWebinar && !role.Can(models.PermissionModerate) {
+		return models.RoleViewer
+	}
+	return role
+}
+
+// isAttendee reports whether the client attends a webinar rather than
+// hosting it, the caller must hold m.mu
+func (m *Meeting) isAttendee(c *Client) bool {
+	return m.mode == models.RoomModeWebinar && !c.Can(models.PermissionModerate)
+}
+
+// hiddenFrom reports whether the client is hidden from the recipient, as
+// webinar attendees don't get to see each other. The caller must hold m.mu.
+func (m *Meeting) hiddenFrom(c *Client, recipient *Client) bool {
+	return c != recipient && m.isAttendee(c) && m.isAttendee(recipient)
+}
+
+// attendeeCount counts the webinar's attendees, it's nil outside of
+// webinars. The caller must hold m.mu.
+func (m *Meeting) attendeeCount() *int {
+	if m.mode != models.RoomModeWebinar {
+		return nil
+	}
+
+	count := 0
+	for client := range m.Clients {
+		if m.isAttendee(client) {
+			count++
+		}
+	}
+	return &count
+}
+
+// broadcastAttendeeCount lets everyone know how many attendees a webinar
+// has, nothing is sent outside of webinars
+func (m *Meeting) broadcastAttendeeCount() {
+	m.mu.RLock()
+	count := m.attendeeCount()
+	m.mu.RUnlock()
+
+	if count == nil {
+		return
+	}
+
+	m.Broadcast(&messages.OutboundWsMessage{
+		Type: messages.OutboundAttendeeCount,
+		Payload: &messages.OutboundAttendeeCountPayload{
+			Count: *count,
+		},
+	})
+}
+
+// announce delivers a message about the client to the rest of the meeting,
+// webinar attendees are only announced to the hosts
+func (c *Client) announce(m *Meeting, attendee bool, msg *messages.OutboundWsMessage) {
+	if attendee {
+		m.BroadcastToModerators(msg)
+		m.broadcastAttendeeCount()
+		return
+	}
+	c.Broadcast(m, msg)
+}
+
+// broadcastAbout delivers a message revealing the client to everyone in the
+// meeting the client isn't hidden from, except the given one
+func (m *Meeting) broadcastAbout(c *Client, msg *messages.OutboundWsMessage, except *Client) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for client := range m.Clients {
+		if client != except && !m.hiddenFrom(c, client) {
+			client.Messages <- msg
+		}
+	}
+}
+
+// videoAllowed reports whether the meeting's mode lets the client send
+// video, letting the client know otherwise
+func (c *Client) videoAllowed(m *Meeting) bool {
+	if m.roomMode() != models.RoomModeAudioOnly {
+		return true
+	}
+
+	c.SendError("video_not_allowed", "Video is not allowed in audio-only rooms")
+	return false
+}
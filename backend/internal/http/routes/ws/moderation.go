@@ -0,0 +1,82 @@
+package ws
+
+import (
+	"github.com/serozhenka/shary/internal/messages"
+)
+
+// moderationTarget resolves the client a moderator wants to act upon. The
+// moderator has to outrank the target, so moderators can't act on each other
+// or on the owner.
+func (c *Client) moderationTarget(m *Meeting, clientId string) *Client {
+	target := m.GetClient(clientId)
+	if target == nil {
+		c.SendError("not_found", "Participant is not in the meeting")
+		return nil
+	}
+
+	if !c.GetRole().Outranks(target.GetRole()) {
+		c.SendError("forbidden", "You can't moderate this participant")
+		return nil
+	}
+
+	return target
+}
+
+func (c *Client) muteParticipant(m *Meeting, payload *messages.InboundMuteParticipantPayload) {
+	target := c.moderationTarget(m, payload.ClientId)
+	if target == nil {
+		return
+	}
+
+	target.Messages <- &messages.OutboundWsMessage{
+		Type: messages.OutboundMute,
+		Payload: &messages.OutboundMutePayload{
+			ClientId:  c.Id,
+			TrackKind: payload.TrackKind,
+		},
+	}
+}
+
+func (c *Client) kickParticipant(m *Meeting, payload *messages.InboundKickParticipantPayload) {
+	target := c.moderationTarget(m, payload.ClientId)
+	if target == nil {
+		return
+	}
+
+	reason := payload.Reason
+	if reason == "" {
+		reason = "kicked"
+	}
+
+	m.KickClient(target, &messages.OutboundKickedPayload{
+		Reason:   reason,
+		ClientId: c.Id,
+	})
+}
+
+func (c *Client) stopScreenShare(m *Meeting, payload *messages.InboundStopScreenSharePayload) {
+	target := c.moderationTarget(m, payload.ClientId)
+	if target == nil {
+		return
+	}
+
+	target.Messages <- &messages.OutboundWsMessage{
+		Type: messages.OutboundStopScreenShare,
+		Payload: &messages.OutboundStopScreenSharePayload{
+			ClientId: c.Id,
+		},
+	}
+}
+
+func (c *Client) lockMeeting(m *Meeting, payload *messages.InboundLockMeetingPayload) {
+	m.SetLocked(payload.Locked)
+	m.Broadcast(
+		&messages.OutboundWsMessage{
+			Type: messages.OutboundMeetingLocked,
+			Payload: &messages.OutboundMeetingLockedPayload{
+				ClientId: c.Id,
+				Locked:   payload.Locked,
+			},
+		},
+	)
+}
@@ -0,0 +1,140 @@
+package ws
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/repository/rooms"
+	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/utils"
+)
+
+func (c *Client) createPoll(ctx *RouterCtx, m *Meeting, payload *messages.InboundPollCreatePayload) {
+	result, err := ctx.PollService.CreatePoll(c.UserId, m.currentRoom().ID, services.PollDraft{
+		Question:    payload.Question,
+		Options:     payload.Options,
+		Anonymous:   payload.Anonymous,
+		MultiChoice: payload.MultiChoice,
+		Duration:    time.Duration(payload.DurationSeconds) * time.Second,
+	})
+	if err != nil {
+		c.sendPollError(err)
+		return
+	}
+
+	m.broadcastPoll(messages.OutboundPollCreated, result)
+
+	if closesAt := result.Poll.ClosesAt; closesAt != nil {
+		pollId := result.Poll.ID
+		time.AfterFunc(time.Until(*closesAt), func() {
+			result, err := ctx.PollService.ExpirePoll(pollId)
+			if err != nil {
+				// Closed by a moderator in the meantime
+				if !errors.Is(err, services.ErrPollClosed) {
+					log.Printf("Failed to expire poll %d: %v", pollId, err)
+				}
+				return
+			}
+
+			// The meeting may have ended or restarted since the poll was created
+			meet := ctx.MeetingManager.GetMeeting(strconv.FormatUint(uint64(result.Poll.RoomID), 10))
+			if meet != nil {
+				meet.broadcastPoll(messages.OutboundPollClosed, result)
+			}
+		})
+	}
+}
+
+func (c *Client) votePoll(ctx *RouterCtx, m *Meeting, payload *messages.InboundPollVotePayload) {
+	result, err := ctx.PollService.Vote(c.UserId, m.currentRoom().ID, payload.PollId, payload.OptionIds)
+	if err != nil {
+		c.sendPollError(err)
+		return
+	}
+
+	m.broadcastPoll(messages.OutboundPollUpdated, result)
+}
+
+func (c *Client) closePoll(ctx *RouterCtx, m *Meeting, payload *messages.InboundPollClosePayload) {
+	result, err := ctx.PollService.ClosePoll(c.UserId, m.currentRoom().ID, payload.PollId)
+	if err != nil {
+		c.sendPollError(err)
+		return
+	}
+
+	m.broadcastPoll(messages.OutboundPollClosed, result)
+}
+
+// broadcastPoll shares the poll and its live tally with the whole meeting,
+// webinar attendees only learn their own votes
+func (m *Meeting) broadcastPoll(messageType messages.OutboundMessageType, result *services.PollResult) {
+	payload := pollPayload(result)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for client := range m.Clients {
+		msg := &messages.OutboundWsMessage{Type: messageType, Payload: payload}
+		if m.isAttendee(client) {
+			msg.Payload = ownVotes(payload, client.UserId)
+		}
+		client.Messages <- msg
+	}
+}
+
+func (c *Client) sendPollError(err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidPoll):
+		c.SendError("invalid_poll", "Poll needs a question and 2 to 10 distinct options")
+	case errors.Is(err, services.ErrInvalidVote):
+		c.SendError("invalid_vote", "Invalid vote")
+	case errors.Is(err, services.ErrPollClosed):
+		c.SendError("poll_closed", "Poll is closed")
+	case errors.Is(err, services.ErrPollNotFound):
+		c.SendError("not_found", "Poll not found")
+	case errors.Is(err, rooms.ErrPermissionDenied):
+		c.SendError("forbidden", "Your role does not allow this")
+	default:
+		log.Printf("Failed to update poll: %v", err)
+		c.SendError("internal", "Failed to update poll")
+	}
+}
+
+func pollPayload(result *services.PollResult) *messages.OutboundPollPayload {
+	poll := result.Poll
+
+	return &messages.OutboundPollPayload{
+		Id:          poll.ID,
+		CreatorId:   poll.CreatorID,
+		Question:    poll.Question,
+		Anonymous:   poll.Anonymous,
+		MultiChoice: poll.MultiChoice,
+		ClosesAt:    poll.ClosesAt,
+		Closed:      result.Closed,
+		TotalVoters: result.TotalVoters,
+		Options: utils.Map(poll.Options, func(option models.PollOption) messages.OutboundPollOption {
+			return messages.OutboundPollOption{
+				Id:       option.ID,
+				Text:     option.Text,
+				Votes:    result.Votes[option.ID],
+				VoterIds: result.Voters[option.ID],
+			}
+		}),
+	}
+}
+
+// ownVotes copies the poll payload keeping only the user's own votes
+func ownVotes(payload *messages.OutboundPollPayload, userId uint) *messages.OutboundPollPayload {
+	own := *payload
+	own.Options = utils.Map(payload.Options, func(option messages.OutboundPollOption) messages.OutboundPollOption {
+		option.VoterIds = utils.Filter(option.VoterIds, func(voterId uint) bool {
+			return voterId == userId
+		})
+		return option
+	})
+	return &own
+}
@@ -0,0 +1,198 @@
+package ws
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/models"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	maxStatsPeers = 50
+
+	// Reports older than this don't describe a live connection anymore
+	liveQualityWindow = 30 * time.Second
+
+	// A connection is poor once any of its peers crosses one of these
+	poorRtt        = 400  // In milliseconds
+	poorPacketLoss = 0.05 // Fraction of packets lost
+	poorJitter     = 50   // In milliseconds
+)
+
+// clientQuality aggregates the stats a client reported during the session
+type clientQuality struct {
+	userId    uint
+	username  string
+	peers     map[string]messages.PeerStats // Latest stats per connection
+	updatedAt time.Time
+	poor      bool
+
+	reports     int
+	poorReports int
+	samples     int // Peer stats summed up below
+	rttSum      float64
+	lossSum     float64
+	jitterSum   float64
+	bitrateSum  float64
+	maxRtt      float64
+	maxLoss     float64
+}
+
+// ClientQuality is the latest connection quality a client reported
+type ClientQuality struct {
+	ClientId  string
+	UserId    uint
+	Username  string
+	Poor      bool
+	UpdatedAt time.Time
+	Peers     []messages.PeerStats
+}
+
+func (c *Client) reportStats(m *Meeting, payload *messages.InboundRtcStatsPayload) {
+	if len(payload.Peers) == 0 || len(payload.Peers) > maxStatsPeers || slices.ContainsFunc(payload.Peers, invalidStats) {
+		c.SendError("invalid_stats", "Stats must describe between 1 and 50 connections with non-negative values")
+		return
+	}
+
+	// Stats are kept by the main meeting, so they survive breakouts
+	hint := m.mainMeeting().recordStats(c, payload.Peers)
+	if hint == nil {
+		return
+	}
+
+	m.Broadcast(&messages.OutboundWsMessage{
+		Type:    messages.OutboundPoorConnection,
+		Payload: hint,
+	})
+}
+
+func invalidStats(stats messages.PeerStats) bool {
+	for _, value := range []float64{stats.Rtt, stats.PacketLoss, stats.Jitter, stats.Bitrate} {
+		if value < 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+			return true
+		}
+	}
+	return stats.PacketLoss > 1
+}
+
+// recordStats adds a report to the client's aggregate, it returns the hint
+// to broadcast when the client's connection got poor or recovered
+func (m *Meeting) recordStats(c *Client, peers []messages.PeerStats) *messages.OutboundPoorConnectionPayload {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	quality, ok := m.quality[c.Id]
+	if !ok {
+		quality = &clientQuality{
+			userId:   c.UserId,
+			username: c.Username,
+		}
+		m.quality[c.Id] = quality
+	}
+
+	var worst messages.PeerStats
+	quality.peers = make(map[string]messages.PeerStats, len(peers))
+	for _, stats := range peers {
+		quality.peers[stats.ClientId] = stats
+
+		quality.samples++
+		quality.rttSum += stats.Rtt
+		quality.lossSum += stats.PacketLoss
+		quality.jitterSum += stats.Jitter
+		quality.bitrateSum += stats.Bitrate
+		quality.maxRtt = max(quality.maxRtt, stats.Rtt)
+		quality.maxLoss = max(quality.maxLoss, stats.PacketLoss)
+
+		worst.Rtt = max(worst.Rtt, stats.Rtt)
+		worst.PacketLoss = max(worst.PacketLoss, stats.PacketLoss)
+		worst.Jitter = max(worst.Jitter, stats.Jitter)
+	}
+
+	poor := worst.Rtt > poorRtt || worst.PacketLoss > poorPacketLoss || worst.Jitter > poorJitter
+	quality.reports++
+	if poor {
+		quality.poorReports++
+	}
+	quality.updatedAt = time.Now()
+
+	if poor == quality.poor {
+		return nil
+	}
+	quality.poor = poor
+
+	return &messages.OutboundPoorConnectionPayload{
+		ClientId:   c.Id,
+		Poor:       poor,
+		Rtt:        worst.Rtt,
+		PacketLoss: worst.PacketLoss,
+		Jitter:     worst.Jitter,
+	}
+}
+
+// LiveQuality returns the connection quality of the clients that reported
+// recently, along with the ID of the running session, 0 if there's none
+func (m *Meeting) LiveQuality() (uint, []ClientQuality) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var sessionId uint
+	if m.session != nil {
+		sessionId = m.session.ID
+	}
+
+	clients := make([]ClientQuality, 0, len(m.quality))
+	for clientId, quality := range m.quality {
+		if time.Since(quality.updatedAt) > liveQualityWindow {
+			continue
+		}
+
+		peers := maps.Values(quality.peers)
+		slices.SortFunc(peers, func(a, b messages.PeerStats) int {
+			return strings.Compare(a.ClientId, b.ClientId)
+		})
+
+		clients = append(clients, ClientQuality{
+			ClientId:  clientId,
+			UserId:    quality.userId,
+			Username:  quality.username,
+			Poor:      quality.poor,
+			UpdatedAt: quality.updatedAt,
+			Peers:     peers,
+		})
+	}
+
+	slices.SortFunc(clients, func(a, b ClientQuality) int {
+		return strings.Compare(a.ClientId, b.ClientId)
+	})
+	return sessionId, clients
+}
+
+// qualitySummaries sums up the stats every client reported during the
+// session, the caller must hold m.mu
+func (m *Meeting) qualitySummaries() []models.QualitySummary {
+	summaries := make([]models.QualitySummary, 0, len(m.quality))
+	for clientId, quality := range m.quality {
+		if quality.samples == 0 {
+			continue
+		}
+
+		samples := float64(quality.samples)
+		summaries = append(summaries, models.QualitySummary{
+			UserID:        quality.userId,
+			ClientID:      clientId,
+			Reports:       quality.reports,
+			PoorReports:   quality.poorReports,
+			AvgRTT:        quality.rttSum / samples,
+			MaxRTT:        quality.maxRtt,
+			AvgPacketLoss: quality.lossSum / samples,
+			MaxPacketLoss: quality.maxLoss,
+			AvgJitter:     quality.jitterSum / samples,
+			AvgBitrate:    quality.bitrateSum / samples,
+		})
+	}
+	return summaries
+}
@@ -0,0 +1,199 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/recording"
+	"github.com/serozhenka/shary/internal/repository/rooms"
+	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/sfu"
+)
+
+// meetingRecording records every track forwarded by the meeting's SFU
+// session into a file of its own
+type meetingRecording struct {
+	id       uint // Zero until the recording has been created
+	meeting  *Meeting
+	session  *sfu.Session
+	service  *services.RecordingService
+	writers  sync.WaitGroup
+	stopped  bool
+	failed   bool
+	recordMu sync.Mutex
+}
+
+func (c *Client) startRecording(ctx *RouterCtx, m *Meeting) {
+	session := m.mediaSession()
+	if session == nil || ctx.RecordingService == nil {
+		c.SendError("recording_unavailable", "Recording requires the room to forward media through the server")
+		return
+	}
+
+	// Reserve the meeting's recording before it's created
+	rec := &meetingRecording{
+		meeting: m,
+		session: session,
+		service: ctx.RecordingService,
+	}
+	m.mu.Lock()
+	if m.recording != nil {
+		m.mu.Unlock()
+		c.SendError("recording_active", "The meeting is already being recorded")
+		return
+	}
+	m.recording = rec
+	m.mu.Unlock()
+
+	result, err := ctx.RecordingService.Start(c.UserId, m.currentRoom().ID)
+	if err != nil {
+		m.mu.Lock()
+		m.recording = nil
+		m.mu.Unlock()
+
+		if errors.Is(err, rooms.ErrPermissionDenied) {
+			c.SendError("forbidden", "Your role does not allow this")
+			return
+		}
+		log.Printf("Failed to start recording: %v", err)
+		c.SendError("internal", "Failed to start recording")
+		return
+	}
+
+	m.mu.Lock()
+	rec.id = result.ID
+	m.mu.Unlock()
+
+	session.StartRecording(rec)
+
+	m.Broadcast(
+		&messages.OutboundWsMessage{
+			Type: messages.OutboundRecordingStarted,
+			Payload: &messages.OutboundRecordingStartedPayload{
+				RecordingId: result.ID,
+				ClientId:    c.Id,
+				StartedAt:   result.StartedAt,
+			},
+		},
+	)
+}
+
+func (c *Client) stopRecording(ctx *RouterCtx, m *Meeting) {
+	m.mu.Lock()
+	rec := m.recording
+	if rec == nil || rec.id == 0 {
+		m.mu.Unlock()
+		c.SendError("not_recording", "The meeting is not being recorded")
+		return
+	}
+	m.recording = nil
+	m.mu.Unlock()
+
+	// Saving the files takes a while, don't hold up the client's messages
+	go rec.finish(c.Id)
+}
+
+// takeRecording detaches the recording of a meeting that has no clients
+// left, the caller must hold m.mu
+func (m *Meeting) takeRecording() *meetingRecording {
+	if len(m.Clients) > 0 || m.recording == nil || m.recording.id == 0 {
+		return nil
+	}
+
+	rec := m.recording
+	m.recording = nil
+	return rec
+}
+
+// recordingId returns the ID of the running recording, the caller must hold m.mu
+func (m *Meeting) recordingId() uint {
+	if m.recording == nil {
+		return 0
+	}
+	return m.recording.id
+}
+
+// finish closes the track files, waits for them to be saved and lets the
+// meeting know. clientId is the moderator who stopped the recording.
+func (r *meetingRecording) finish(clientId string) {
+	r.session.StopRecording()
+
+	r.recordMu.Lock()
+	r.stopped = true
+	r.recordMu.Unlock()
+	r.writers.Wait()
+
+	status := models.RecordingCompleted
+	if r.failed {
+		status = models.RecordingFailed
+	}
+
+	if _, err := r.service.Finish(r.id, status); err != nil {
+		log.Printf("Failed to finish recording %d: %v", r.id, err)
+	}
+
+	r.meeting.Broadcast(
+		&messages.OutboundWsMessage{
+			Type: messages.OutboundRecordingStopped,
+			Payload: &messages.OutboundRecordingStoppedPayload{
+				RecordingId: r.id,
+				ClientId:    clientId,
+				Status:      string(status),
+			},
+		},
+	)
+}
+
+// RecordTrack implements sfu.Recorder
+func (r *meetingRecording) RecordTrack(info sfu.TrackInfo, codec webrtc.RTPCodecParameters) sfu.PacketWriter {
+	publisher := r.meeting.GetClient(info.PublisherID)
+	if publisher == nil {
+		return nil
+	}
+
+	writer, err := recording.NewTrackWriter(codec, func(file io.Reader, size int64, contentType string) error {
+		_, err := r.service.SaveTrack(context.Background(), r.id, publisher.UserId, info.Kind, contentType, file, size)
+		return err
+	})
+	if err != nil {
+		if !errors.Is(err, recording.ErrUnsupportedCodec) {
+			log.Printf("Failed to record track %s: %v", info.TrackID, err)
+		}
+		return nil
+	}
+
+	r.recordMu.Lock()
+	defer r.recordMu.Unlock()
+
+	if r.stopped {
+		writer.Close()
+		return nil
+	}
+	r.writers.Add(1)
+
+	return &trackRecording{TrackWriter: writer, recording: r}
+}
+
+// trackRecording lets the meeting's recording know when a track is saved
+type trackRecording struct {
+	*recording.TrackWriter
+	recording *meetingRecording
+}
+
+func (t *trackRecording) Close() error {
+	defer t.recording.writers.Done()
+
+	err := t.TrackWriter.Close()
+	if err != nil {
+		t.recording.recordMu.Lock()
+		t.recording.failed = true
+		t.recording.recordMu.Unlock()
+	}
+	return err
+}
@@ -5,13 +5,23 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/serozhenka/shary/internal/repository/rooms"
 	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/sfu"
+	"github.com/serozhenka/shary/internal/transcription"
 )
 
 type RouterCtx struct {
-	RoomsRepo      rooms.Repository
-	MeetingManager MeetingManager
-	Upgrader       *websocket.Upgrader
-	AuthService    *services.AuthService
+	RoomsRepo        rooms.Repository
+	ChatService      *services.ChatService
+	PollService      *services.PollService
+	RecordingService *services.RecordingService
+	SessionService   *services.SessionService
+	MeetingManager   MeetingManager
+	Upgrader         *websocket.Upgrader
+	AuthService      *services.AuthService
+	ICEService       *services.ICEService
+	MaxParticipants  int                       // Server-wide default meeting capacity
+	SFU              *sfu.SFU                  // Forwards media of rooms with the SFU enabled, nil disables it
+	Transcriber      transcription.Transcriber // Captions speech, nil turns captions off
 }
 
 func SetupRouter(rg *gin.RouterGroup, ctx *RouterCtx) {
@@ -6,7 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/segmentio/ksuid"
-	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/models"
 )
 
 func (ctx *RouterCtx) ws(c *gin.Context) {
@@ -25,10 +25,11 @@ func (ctx *RouterCtx) ws(c *gin.Context) {
 	}
 
 	// Get roomId from query parameters
+	var room *models.Room
 	roomId := c.Query("roomId")
 	if roomId != "" {
 		// If roomId is specified, validate that it exists
-		_, err := ctx.RoomsRepo.GetRoomByStringID(claims.UserID, roomId)
+		room, err = ctx.RoomsRepo.GetRoomByStringID(claims.UserID, roomId)
 		if err != nil {
 			c.String(http.StatusNotFound, "Room not found")
 			return
@@ -41,21 +42,45 @@ func (ctx *RouterCtx) ws(c *gin.Context) {
 			return
 		}
 		if len(rooms) > 0 {
-			roomId = strconv.FormatUint(uint64(rooms[0].ID), 10)
+			room = rooms[0]
 		} else {
 			// Create a default room if none exists
-			roomModel, err := ctx.RoomsRepo.CreateRoom(claims.UserID, "Default Room")
+			room, err = ctx.RoomsRepo.CreateRoom(claims.UserID, "Default Room")
 			if err != nil {
 				c.String(http.StatusInternalServerError, "Failed to create default room")
 				return
 			}
-			roomId = strconv.FormatUint(uint64(roomModel.ID), 10)
 		}
 	}
+	roomId = strconv.FormatUint(uint64(room.ID), 10)
 
-	meet := ctx.MeetingManager.GetMeeting(roomId)
-	if meet == nil {
-		meet = ctx.MeetingManager.CreateMeeting(roomId)
+	// Resolve the user's role in the room
+	participant, err := ctx.RoomsRepo.GetParticipant(room.ID, claims.UserID)
+	if err != nil {
+		c.String(http.StatusForbidden, "Not a room participant")
+		return
+	}
+
+	meet := ctx.MeetingManager.GetOrCreateMeeting(roomId, room)
+	meet.SetRoom(room)
+
+	// Apply the room's capacity, falling back to the server-wide default
+	capacity := room.MaxParticipants
+	if capacity == 0 {
+		capacity = ctx.MaxParticipants
+	}
+	meet.SetCapacity(capacity, room.OverflowAsViewers)
+	meet.UseSFU(ctx.SFU, room.SFUEnabled)
+	meet.SetSDPPolicy(roomSDPPolicy(room))
+	meet.SetMode(room.Mode)
+	meet.UseSessions(ctx.SessionService)
+	meet.UseICE(ctx.ICEService)
+	meet.UseTranscriber(ctx.Transcriber)
+
+	// Locked meetings only admit moderators
+	if meet.IsLocked() && !participant.Role.Can(models.PermissionModerate) {
+		c.String(http.StatusLocked, "Meeting is locked")
+		return
 	}
 
 	// Upgrade the connection to WebSocket
@@ -66,15 +91,22 @@ func (ctx *RouterCtx) ws(c *gin.Context) {
 	}
 
 	// Create client and join room
-	client := &Client{
-		Id:       ksuid.New().String(),
-		Username: claims.Username,
-		Conn:     conn,
-		Messages: make(chan *messages.OutboundWsMessage, 1024),
-	}
+	client := NewClient(ksuid.New().String(), claims.UserID, claims.Username, participant.Role, conn)
+	client.meeting = meet
 
-	meet.Join(client)
+	// Rooms with a lobby make everyone but moderators wait to be admitted
+	if room.LobbyEnabled && !participant.Role.Can(models.PermissionModerate) {
+		meet.Wait(client)
+	} else if err := meet.Join(client); err != nil {
+		// Let the writer deliver the rejection and close the connection
+		client.SendError(err.Error(), "The meeting is full")
+		client.Disconnect()
+		go client.Writer()
+		return
+	} else {
+		ctx.sendChatHistory(client, room.ID)
+	}
 
-	go client.Reader(meet)
+	go client.Reader(ctx)
 	go client.Writer()
 }
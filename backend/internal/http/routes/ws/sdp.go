@@ -0,0 +1,52 @@
+package ws
+
+import (
+	"errors"
+
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/sdp"
+)
+
+// SetSDPPolicy configures the media policy enforced on the offers and
+// answers the meeting's clients exchange
+func (m *Meeting) SetSDPPolicy(policy sdp.Policy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.policy = policy
+}
+
+func (m *Meeting) sdpPolicy() sdp.Policy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.policy
+}
+
+// roomSDPPolicy builds the media policy from the room's settings
+func roomSDPPolicy(room *models.Room) sdp.Policy {
+	return sdp.Policy{
+		AllowedCodecs:   room.CodecList(),
+		MaxVideoBitrate: room.MaxVideoBitrate,
+		Simulcast:       room.SimulcastEnabled,
+		AudioOnly:       room.Mode == models.RoomModeAudioOnly,
+	}
+}
+
+// applySDPPolicy rewrites a session description before it's relayed, it
+// reports false after letting the client know it was rejected. Clients that
+// may not publish, such as webinar attendees, may only receive media.
+func (c *Client) applySDPPolicy(m *Meeting, raw string) (string, bool) {
+	policy := m.sdpPolicy()
+	policy.ReceiveOnly = !c.Can(models.PermissionPublishMedia)
+
+	rewritten, err := policy.Apply(raw)
+	if err != nil {
+		if errors.Is(err, sdp.ErrInvalidSDP) {
+			c.SendError("invalid_sdp", "Session description can't be parsed")
+		} else {
+			c.SendError("sdp_rejected", err.Error())
+		}
+		return "", false
+	}
+	return rewritten, true
+}
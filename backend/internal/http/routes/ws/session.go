@@ -0,0 +1,97 @@
+package ws
+
+import (
+	"log"
+
+	"github.com/serozhenka/shary/internal/services"
+)
+
+// UseSessions lets the meeting record its sessions, a session lasts from
+// the first client joining until the last one leaves
+func (m *Meeting) UseSessions(service *services.SessionService) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions = service
+}
+
+// SessionId returns the ID of the running session, 0 if there's none
+func (m *Meeting) SessionId() uint {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.session == nil {
+		return 0
+	}
+	return m.session.ID
+}
+
+// startSession starts a session unless one is already running, breakouts
+// belong to the session of their main meeting
+func (m *Meeting) startSession() {
+	m.sessionMu.Lock()
+	defer m.sessionMu.Unlock()
+
+	m.mu.RLock()
+	running := m.session != nil
+	service := m.sessions
+	room := m.room
+	m.mu.RUnlock()
+
+	if running || service == nil || room == nil || m.parent != nil {
+		return
+	}
+
+	session, err := service.Start(room.ID)
+	if err != nil {
+		log.Printf("Failed to start session of room %d: %v", room.ID, err)
+		return
+	}
+
+	m.mu.Lock()
+	m.session = session
+	m.mu.Unlock()
+}
+
+// endSession ends the running session once nobody is left in the meeting or
+// its breakouts, keeping the connection quality its clients reported, what
+// they said and what they drew
+func (m *Meeting) endSession() {
+	m.sessionMu.Lock()
+	defer m.sessionMu.Unlock()
+
+	// Someone may have joined in the meantime
+	if !m.idle() {
+		return
+	}
+
+	boards := m.finalBoards()
+
+	m.mu.Lock()
+	session := m.session
+	service := m.sessions
+	summaries := m.qualitySummaries()
+	transcript := m.transcript
+	m.session = nil
+	m.quality = map[string]*clientQuality{}
+	m.transcript = nil
+	m.mu.Unlock()
+
+	if session == nil || service == nil {
+		return
+	}
+
+	if err := service.End(session.ID, summaries, transcript, boards); err != nil {
+		log.Printf("Failed to end session %d: %v", session.ID, err)
+	}
+}
+
+// idle reports whether nobody is in the meeting or any of its breakouts
+func (m *Meeting) idle() bool {
+	for _, meeting := range m.withBreakouts() {
+		if meeting.GetParticipantCount() > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package ws
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/segmentio/ksuid"
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/sfu"
+)
+
+// UseSFU switches the meeting between a full mesh and forwarding media
+// through the server. The mode can't change while anyone is in the meeting.
+func (m *Meeting) UseSFU(forwarder *sfu.SFU, enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.Clients) > 0 || len(m.pending) > 0 {
+		return
+	}
+
+	if !enabled || forwarder == nil {
+		m.sfu = nil
+	} else if m.sfu == nil {
+		m.sfu = forwarder.NewSession()
+	}
+}
+
+func (m *Meeting) mediaSession() *sfu.Session {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sfu
+}
+
+func (c *Client) connectSFU(session *sfu.Session) {
+	if err := session.AddPeer(c.Id, c.sendSignal); err != nil {
+		log.Printf("Failed to connect client %s to the SFU: %v", c.Id, err)
+		c.SendError("sfu", "Failed to connect to the media server")
+	}
+}
+
+// signalSFU hands an offer, answer or ICE candidate addressed to the server
+// over to the meeting's SFU session
+func (c *Client) signalSFU(m *Meeting, signal sfu.Signal) {
+	session := m.mediaSession()
+	if session == nil {
+		c.SendError("sfu", "The meeting doesn't use the media server")
+		return
+	}
+
+	if err := session.Signal(c.Id, signal); err != nil {
+		log.Printf("Failed to negotiate with client %s: %v", c.Id, err)
+		c.SendError("sfu", "Failed to negotiate with the media server")
+	}
+}
+
+// sendSignal relays a negotiation message of the SFU to the client using the
+// same message types peers use among each other
+func (c *Client) sendSignal(signal sfu.Signal) {
+	var msg *messages.OutboundWsMessage
+
+	switch {
+	case signal.Track != nil:
+		msg = &messages.OutboundWsMessage{
+			Type: messages.OutboundTrackPublished,
+			Payload: &messages.OutboundTrackPublishedPayload{
+				ClientId: signal.Track.PublisherID,
+				StreamId: signal.Track.StreamID,
+				TrackId:  signal.Track.TrackID,
+				Kind:     signal.Track.Kind,
+			},
+		}
+	case signal.Description != nil && signal.Description.Type == webrtc.SDPTypeOffer:
+		payload := &messages.OutboundOfferPayload{
+			MessageId: ksuid.New().String(),
+			ClientId:  signal.Target,
+		}
+		payload.Value.Type = signal.Description.Type.String()
+		payload.Value.Sdp = signal.Description.SDP
+		msg = &messages.OutboundWsMessage{Type: messages.OutboudOffer, Payload: payload}
+	case signal.Description != nil:
+		payload := &messages.OutboundAnswerPayload{
+			MessageId: ksuid.New().String(),
+			ClientId:  signal.Target,
+		}
+		payload.Value.Type = signal.Description.Type.String()
+		payload.Value.Sdp = signal.Description.SDP
+		msg = &messages.OutboundWsMessage{Type: messages.OutboudAnswer, Payload: payload}
+	case signal.Candidate != nil:
+		var value map[string]any
+		encoded, _ := json.Marshal(signal.Candidate)
+		json.Unmarshal(encoded, &value)
+
+		msg = &messages.OutboundWsMessage{
+			Type: messages.OutboudIceCandidate,
+			Payload: &messages.OutboundIceCandidatePayload{
+				MessageId: ksuid.New().String(),
+				ClientId:  signal.Target,
+				Value:     value,
+			},
+		}
+	default:
+		return
+	}
+
+	c.Messages <- msg
+}
+
+// sessionDescription converts the SDP of an offer or answer message
+func sessionDescription(sdpType webrtc.SDPType, sdp string) *webrtc.SessionDescription {
+	return &webrtc.SessionDescription{Type: sdpType, SDP: sdp}
+}
+
+// iceCandidate converts the candidate of an iceCandidate message
+func iceCandidate(value map[string]any) *webrtc.ICECandidateInit {
+	var candidate webrtc.ICECandidateInit
+	encoded, _ := json.Marshal(value)
+	json.Unmarshal(encoded, &candidate)
+	return &candidate
+}
@@ -0,0 +1,38 @@
+package ws
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/serozhenka/shary/internal/messages"
+)
+
+func (c *Client) reportAudioLevel(m *Meeting, payload *messages.InboundAudioLevelPayload) {
+	if payload.Level < 0 || payload.Level > 1 || math.IsNaN(payload.Level) {
+		c.SendError("invalid_level", "Audio level must be between 0 and 1")
+		return
+	}
+
+	m.mu.Lock()
+	level := payload.Level
+	// Background noise of a muted microphone isn't speech
+	if state, ok := m.media[c]; ok && slices.Contains(state.mutedTracks, "audio") {
+		level = 0
+	}
+	speaker, changed := m.speakers.Update(c.Id, level, time.Now())
+	m.mu.Unlock()
+
+	if changed {
+		m.broadcastActiveSpeaker(speaker)
+	}
+}
+
+func (m *Meeting) broadcastActiveSpeaker(clientId string) {
+	m.Broadcast(&messages.OutboundWsMessage{
+		Type: messages.OutboundActiveSpeaker,
+		Payload: &messages.OutboundActiveSpeakerPayload{
+			ClientId: clientId,
+		},
+	})
+}
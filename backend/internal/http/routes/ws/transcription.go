@@ -0,0 +1,185 @@
+package ws
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/sfu"
+	"github.com/serozhenka/shary/internal/transcription"
+)
+
+const maxCaptionChunk = 64 << 10
+
+// UseTranscriber sets what captions the speech of the meeting's clients,
+// nil turns captions off
+func (m *Meeting) UseTranscriber(transcriber transcription.Transcriber) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.transcriber = transcriber
+}
+
+func (m *Meeting) currentTranscriber() transcription.Transcriber {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.transcriber
+}
+
+// transcribeSFU captions the audio forwarded by the meeting's SFU session,
+// it's started once per session
+func (m *Meeting) transcribeSFU() {
+	m.mu.Lock()
+	session := m.sfu
+	start := session != nil && m.transcriber != nil && m.transcribed != session
+	if start {
+		m.transcribed = session
+	}
+	m.mu.Unlock()
+
+	if start {
+		session.StartTranscribing(&sfuTranscription{meeting: m})
+	}
+}
+
+// captionAudio transcribes audio the client uploads itself, e.g. in rooms
+// without the SFU
+func (c *Client) captionAudio(m *Meeting, payload *messages.InboundCaptionAudioPayload) {
+	if payload.MimeType == "" || len(payload.Data) == 0 || len(payload.Data) > maxCaptionChunk {
+		c.SendError("invalid_audio", "Audio chunks need a MIME type and must hold up to 64 KiB")
+		return
+	}
+
+	m.mu.RLock()
+	stream, ok := m.captions[c]
+	m.mu.RUnlock()
+
+	if !ok {
+		if m.currentTranscriber() == nil {
+			c.SendError("captions_unavailable", "Captions are not available")
+			return
+		}
+
+		stream = m.openCaptions(c, payload.MimeType)
+		if stream == nil {
+			c.SendError("internal", "Failed to start captions")
+			return
+		}
+
+		m.mu.Lock()
+		_, joined := m.Clients[c]
+		if joined {
+			m.captions[c] = stream
+		}
+		m.mu.Unlock()
+
+		if !joined {
+			stream.Close()
+			return
+		}
+	}
+
+	if err := stream.Write(payload.Data); err != nil {
+		log.Printf("Failed to caption audio of client %s: %v", c.Id, err)
+		c.SendError("internal", "Failed to caption audio")
+	}
+}
+
+// openCaptions starts transcribing the client's audio, it returns nil if
+// that's not possible
+func (m *Meeting) openCaptions(c *Client, mimeType string) transcription.Stream {
+	transcriber := m.currentTranscriber()
+	if transcriber == nil {
+		return nil
+	}
+
+	openedAt := time.Now()
+	stream, err := transcriber.Open(mimeType, func(segment transcription.Segment) {
+		m.caption(c, openedAt, segment)
+	})
+	if err != nil {
+		log.Printf("Failed to transcribe client %s: %v", c.Id, err)
+		return nil
+	}
+	return stream
+}
+
+// caption shows the meeting what the client said, final captions are kept
+// in the session's transcript
+func (m *Meeting) caption(c *Client, openedAt time.Time, segment transcription.Segment) {
+	text := strings.TrimSpace(segment.Text)
+	if text == "" {
+		return
+	}
+
+	startedAt := openedAt.Add(segment.Start)
+	if segment.Final {
+		m.mainMeeting().addTranscript(models.TranscriptSegment{
+			UserID:    c.UserId,
+			ClientID:  c.Id,
+			Speaker:   c.Username,
+			Text:      text,
+			StartedAt: startedAt,
+			EndedAt:   openedAt.Add(segment.End),
+		})
+	}
+
+	m.broadcastAbout(c, &messages.OutboundWsMessage{
+		Type: messages.OutboundCaption,
+		Payload: &messages.OutboundCaptionPayload{
+			ClientId:  c.Id,
+			Username:  c.Username,
+			Text:      text,
+			Final:     segment.Final,
+			StartedAt: startedAt,
+		},
+	}, nil)
+}
+
+// addTranscript keeps a final caption until the session ends, captions
+// outside of a session are dropped
+func (m *Meeting) addTranscript(segment models.TranscriptSegment) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.session != nil {
+		m.transcript = append(m.transcript, segment)
+	}
+}
+
+// sfuTranscription hands the audio tracks forwarded by the SFU to the
+// meeting's transcriber
+type sfuTranscription struct {
+	meeting *Meeting
+}
+
+func (t *sfuTranscription) RecordTrack(info sfu.TrackInfo, codec webrtc.RTPCodecParameters) sfu.PacketWriter {
+	publisher := t.meeting.GetClient(info.PublisherID)
+	if publisher == nil {
+		return nil
+	}
+
+	stream := t.meeting.openCaptions(publisher, codec.MimeType)
+	if stream == nil {
+		return nil
+	}
+	return &captionWriter{stream: stream}
+}
+
+// captionWriter passes the payloads of audio packets on to a transcription
+// stream
+type captionWriter struct {
+	stream transcription.Stream
+}
+
+func (w *captionWriter) WriteRTP(packet *rtp.Packet) error {
+	return w.stream.Write(packet.Payload)
+}
+
+func (w *captionWriter) Close() error {
+	return w.stream.Close()
+}
@@ -0,0 +1,93 @@
+package ws
+
+import (
+	"errors"
+
+	"github.com/serozhenka/shary/internal/messages"
+	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/whiteboard"
+)
+
+func (c *Client) applyWhiteboardOp(m *Meeting, payload *messages.InboundWbOpPayload) {
+	op := *payload
+	op.ClientId = c.Id
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	applied, err := m.board.Apply(op)
+	if err != nil {
+		if errors.Is(err, whiteboard.ErrBoardFull) {
+			c.SendError("board_full", "The whiteboard is full, erase or clear some elements")
+			return
+		}
+		c.SendError("invalid_op", err.Error())
+		return
+	}
+
+	// Broadcasting while holding the lock delivers ops in sequence order
+	msg := &messages.OutboundWsMessage{
+		Type:    messages.OutboundWbOp,
+		Payload: &applied,
+	}
+	for client := range m.Clients {
+		client.Messages <- msg
+	}
+}
+
+// takeBoard hands over the meeting's board unless nothing is on it, a blank
+// board is left for the next session
+func (m *Meeting) takeBoard(name string) *services.FinalBoard {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	board := m.board
+	m.board = &whiteboard.Board{}
+	if board.Empty() {
+		return nil
+	}
+	return &services.FinalBoard{Name: name, Snapshot: board.Snapshot()}
+}
+
+// keepBoard holds on to the board of a closed breakout until the session
+// ends
+func (m *Meeting) keepBoard(board *services.FinalBoard) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if board != nil && m.session != nil {
+		m.boards = append(m.boards, *board)
+	}
+}
+
+// finalBoards collects the boards of the meeting, its running breakouts and
+// the breakouts closed during the session
+func (m *Meeting) finalBoards() []services.FinalBoard {
+	m.mu.RLock()
+	var rooms []*breakoutRoom
+	if m.breakouts != nil {
+		rooms = m.breakouts.rooms
+	}
+	name := ""
+	if m.room != nil {
+		name = m.room.Name
+	}
+	m.mu.RUnlock()
+
+	boards := []services.FinalBoard{}
+	if board := m.takeBoard(name); board != nil {
+		boards = append(boards, *board)
+	}
+	for _, room := range rooms {
+		if board := room.meeting.takeBoard(room.name); board != nil {
+			boards = append(boards, *board)
+		}
+	}
+
+	m.mu.Lock()
+	boards = append(boards, m.boards...)
+	m.boards = nil
+	m.mu.Unlock()
+
+	return boards
+}
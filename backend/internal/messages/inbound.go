@@ -1,6 +1,10 @@
 package messages
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/serozhenka/shary/internal/whiteboard"
+)
 
 type InboundWsMessage struct {
 	Type    InboundMessageType `json:"type"`
@@ -9,6 +13,124 @@ type InboundWsMessage struct {
 
 type InboundDataPayload struct {
 	Message string `json:"message"`
+	ReplyTo *uint  `json:"replyTo"`
+	// Private messages target either a client or a user of the room
+	ClientId string `json:"clientId,omitempty"`
+	UserId   *uint  `json:"userId,omitempty"`
+	// Uploaded with POST /rooms/:id/attachments
+	AttachmentId *uint `json:"attachmentId,omitempty"`
+}
+
+type InboundChatEditPayload struct {
+	MessageId uint   `json:"messageId"`
+	Message   string `json:"message"`
+}
+
+type InboundChatDeletePayload struct {
+	MessageId uint `json:"messageId"`
+}
+
+type InboundChatReactPayload struct {
+	MessageId uint   `json:"messageId"`
+	Emoji     string `json:"emoji"`
+	Remove    bool   `json:"remove"`
+}
+
+type InboundTypingPayload struct {
+	Typing bool `json:"typing"`
+}
+
+type InboundChatReadPayload struct {
+	MessageId uint `json:"messageId"`
+}
+
+type InboundRaiseHandPayload struct{}
+
+type InboundLowerHandPayload struct {
+	ClientId string `json:"clientId,omitempty"` // Moderators may lower other participants' hands
+}
+
+type InboundClearHandsPayload struct{}
+
+type InboundMoveHandPayload struct {
+	ClientId string `json:"clientId"`
+	Position int    `json:"position"` // Zero-based, clamped to the queue
+}
+
+type InboundPollCreatePayload struct {
+	Question        string   `json:"question"`
+	Options         []string `json:"options"`
+	Anonymous       bool     `json:"anonymous"`
+	MultiChoice     bool     `json:"multiChoice"`
+	DurationSeconds int      `json:"durationSeconds"` // 0 keeps the poll open until closed
+}
+
+type InboundPollVotePayload struct {
+	PollId    uint   `json:"pollId"`
+	OptionIds []uint `json:"optionIds"` // Empty retracts the vote
+}
+
+type InboundPollClosePayload struct {
+	PollId uint `json:"pollId"`
+}
+
+type InboundBreakoutCreatePayload struct {
+	Count           int            `json:"count"`
+	Assignments     map[string]int `json:"assignments"`     // Client ID to zero-based breakout index
+	Random          bool           `json:"random"`          // Spread the remaining participants randomly
+	DurationSeconds int            `json:"durationSeconds"` // 0 keeps the breakouts open until ended
+}
+
+type InboundBreakoutEndPayload struct{}
+
+type InboundBreakoutBroadcastPayload struct {
+	Message string `json:"message"`
+}
+
+type InboundStartRecordingPayload struct{}
+
+type InboundStopRecordingPayload struct{}
+
+// InboundRtcStatsPayload summarizes the client's WebRTC stats since its last
+// report, one entry per connection
+type InboundRtcStatsPayload struct {
+	Peers []PeerStats `json:"peers"`
+}
+
+type InboundAudioLevelPayload struct {
+	Level float64 `json:"level"` // Between 0 and 1, as reported by WebRTC
+}
+
+// InboundViewportSubscriptionPayload lists the peers the client renders and
+// at which size. It replaces the previous subscription, peers left out
+// aren't rendered at all.
+type InboundViewportSubscriptionPayload struct {
+	Subscriptions []ViewportSubscription `json:"subscriptions"`
+}
+
+type ViewportSubscription struct {
+	ClientId string `json:"clientId"`
+	Width    int    `json:"width"` // Rendered size, in CSS pixels
+	Height   int    `json:"height"`
+}
+
+// InboundCaptionAudioPayload carries a chunk of the client's microphone
+// audio to caption, e.g. from a MediaRecorder
+type InboundCaptionAudioPayload struct {
+	MimeType string `json:"mimeType"`
+	Data     []byte `json:"data"` // Base64 encoded
+}
+
+// InboundWbOpPayload changes the meeting's whiteboard, the server assigns
+// the sequence number
+type InboundWbOpPayload = whiteboard.Op
+
+type PeerStats struct {
+	ClientId   string  `json:"clientId"`   // Remote peer, or the SFU's publisher or subscriber
+	Rtt        float64 `json:"rtt"`        // Round trip time, in milliseconds
+	PacketLoss float64 `json:"packetLoss"` // Fraction of packets lost, between 0 and 1
+	Jitter     float64 `json:"jitter"`     // In milliseconds
+	Bitrate    float64 `json:"bitrate"`    // Received from the peer, in kbps
 }
 
 type InboundOfferPayload struct {
@@ -39,6 +161,10 @@ type InboundTrackMutedPayload struct {
 	TrackKind string `json:"trackKind"`
 }
 
+type InboundTrackUnmutedPayload struct {
+	TrackKind string `json:"trackKind"`
+}
+
 type InboundStreamMetadataPayload struct {
 	StreamId   string `json:"streamId"`
 	StreamType string `json:"streamType"` // "media" | "screen"
@@ -48,26 +174,111 @@ type InboundScreenShareStartedPayload struct{}
 
 type InboundScreenShareStoppedPayload struct{}
 
+type InboundMuteParticipantPayload struct {
+	ClientId  string `json:"clientId"`
+	TrackKind string `json:"trackKind"`
+}
+
+type InboundKickParticipantPayload struct {
+	ClientId string `json:"clientId"`
+	Reason   string `json:"reason"`
+}
+
+type InboundStopScreenSharePayload struct {
+	ClientId string `json:"clientId"`
+}
+
+type InboundLockMeetingPayload struct {
+	Locked bool `json:"locked"`
+}
+
+type InboundAdmitPayload struct {
+	ClientId string `json:"clientId"`
+}
+
+type InboundDenyPayload struct {
+	ClientId string `json:"clientId"`
+	Reason   string `json:"reason"`
+}
+
 type InboundMessageType string
 
 const (
-	InboundOffer              InboundMessageType = "offer"
-	InboundAnswer             InboundMessageType = "answer"
-	InboundData               InboundMessageType = "data"
-	InboundIceCandidate       InboundMessageType = "iceCandidate"
-	InboundTrackMuted         InboundMessageType = "trackMuted"
-	InboundStreamMetadata     InboundMessageType = "streamMetadata"
-	InboundScreenShareStarted InboundMessageType = "screenShareStarted"
-	InboundScreenShareStopped InboundMessageType = "screenShareStopped"
+	InboundOffer                InboundMessageType = "offer"
+	InboundAnswer               InboundMessageType = "answer"
+	InboundData                 InboundMessageType = "data"
+	InboundIceCandidate         InboundMessageType = "iceCandidate"
+	InboundTrackMuted           InboundMessageType = "trackMuted"
+	InboundTrackUnmuted         InboundMessageType = "trackUnmuted"
+	InboundStreamMetadata       InboundMessageType = "streamMetadata"
+	InboundScreenShareStarted   InboundMessageType = "screenShareStarted"
+	InboundScreenShareStopped   InboundMessageType = "screenShareStopped"
+	InboundMuteParticipant      InboundMessageType = "muteParticipant"
+	InboundKickParticipant      InboundMessageType = "kickParticipant"
+	InboundStopScreenShare      InboundMessageType = "stopScreenShare"
+	InboundLockMeeting          InboundMessageType = "lockMeeting"
+	InboundAdmit                InboundMessageType = "admit"
+	InboundDeny                 InboundMessageType = "deny"
+	InboundChatEdit             InboundMessageType = "chatEdit"
+	InboundChatDelete           InboundMessageType = "chatDelete"
+	InboundChatReact            InboundMessageType = "chatReact"
+	InboundTyping               InboundMessageType = "typing"
+	InboundChatRead             InboundMessageType = "chatRead"
+	InboundRaiseHand            InboundMessageType = "raiseHand"
+	InboundLowerHand            InboundMessageType = "lowerHand"
+	InboundClearHands           InboundMessageType = "clearHands"
+	InboundMoveHand             InboundMessageType = "moveHand"
+	InboundPollCreate           InboundMessageType = "pollCreate"
+	InboundPollVote             InboundMessageType = "pollVote"
+	InboundPollClose            InboundMessageType = "pollClose"
+	InboundBreakoutCreate       InboundMessageType = "breakoutCreate"
+	InboundBreakoutEnd          InboundMessageType = "breakoutEnd"
+	InboundBreakoutBroadcast    InboundMessageType = "breakoutBroadcast"
+	InboundStartRecording       InboundMessageType = "startRecording"
+	InboundStopRecording        InboundMessageType = "stopRecording"
+	InboundRtcStats             InboundMessageType = "rtcStats"
+	InboundAudioLevel           InboundMessageType = "audioLevel"
+	InboundViewportSubscription InboundMessageType = "viewportSubscription"
+	InboundCaptionAudio         InboundMessageType = "captionAudio"
+	InboundWbOp                 InboundMessageType = "wbOp"
 )
 
 var InboundPayload = map[InboundMessageType]func() any{
-	InboundOffer:              func() any { return &InboundOfferPayload{} },
-	InboundAnswer:             func() any { return &InboundAnswerPayload{} },
-	InboundData:               func() any { return &InboundDataPayload{} },
-	InboundIceCandidate:       func() any { return &InboundIceCandidatePayload{} },
-	InboundTrackMuted:         func() any { return &InboundTrackMutedPayload{} },
-	InboundStreamMetadata:     func() any { return &InboundStreamMetadataPayload{} },
-	InboundScreenShareStarted: func() any { return &InboundScreenShareStartedPayload{} },
-	InboundScreenShareStopped: func() any { return &InboundScreenShareStoppedPayload{} },
+	InboundOffer:                func() any { return &InboundOfferPayload{} },
+	InboundAnswer:               func() any { return &InboundAnswerPayload{} },
+	InboundData:                 func() any { return &InboundDataPayload{} },
+	InboundIceCandidate:         func() any { return &InboundIceCandidatePayload{} },
+	InboundTrackMuted:           func() any { return &InboundTrackMutedPayload{} },
+	InboundTrackUnmuted:         func() any { return &InboundTrackUnmutedPayload{} },
+	InboundStreamMetadata:       func() any { return &InboundStreamMetadataPayload{} },
+	InboundScreenShareStarted:   func() any { return &InboundScreenShareStartedPayload{} },
+	InboundScreenShareStopped:   func() any { return &InboundScreenShareStoppedPayload{} },
+	InboundMuteParticipant:      func() any { return &InboundMuteParticipantPayload{} },
+	InboundKickParticipant:      func() any { return &InboundKickParticipantPayload{} },
+	InboundStopScreenShare:      func() any { return &InboundStopScreenSharePayload{} },
+	InboundLockMeeting:          func() any { return &InboundLockMeetingPayload{} },
+	InboundAdmit:                func() any { return &InboundAdmitPayload{} },
+	InboundDeny:                 func() any { return &InboundDenyPayload{} },
+	InboundChatEdit:             func() any { return &InboundChatEditPayload{} },
+	InboundChatDelete:           func() any { return &InboundChatDeletePayload{} },
+	InboundChatReact:            func() any { return &InboundChatReactPayload{} },
+	InboundTyping:               func() any { return &InboundTypingPayload{} },
+	InboundChatRead:             func() any { return &InboundChatReadPayload{} },
+	InboundRaiseHand:            func() any { return &InboundRaiseHandPayload{} },
+	InboundLowerHand:            func() any { return &InboundLowerHandPayload{} },
+	InboundClearHands:           func() any { return &InboundClearHandsPayload{} },
+	InboundMoveHand:             func() any { return &InboundMoveHandPayload{} },
+	InboundPollCreate:           func() any { return &InboundPollCreatePayload{} },
+	InboundPollVote:             func() any { return &InboundPollVotePayload{} },
+	InboundPollClose:            func() any { return &InboundPollClosePayload{} },
+	InboundBreakoutCreate:       func() any { return &InboundBreakoutCreatePayload{} },
+	InboundBreakoutEnd:          func() any { return &InboundBreakoutEndPayload{} },
+	InboundBreakoutBroadcast:    func() any { return &InboundBreakoutBroadcastPayload{} },
+	InboundStartRecording:       func() any { return &InboundStartRecordingPayload{} },
+	InboundStopRecording:        func() any { return &InboundStopRecordingPayload{} },
+	InboundRtcStats:             func() any { return &InboundRtcStatsPayload{} },
+	InboundAudioLevel:           func() any { return &InboundAudioLevelPayload{} },
+	InboundViewportSubscription: func() any { return &InboundViewportSubscriptionPayload{} },
+	InboundCaptionAudio:         func() any { return &InboundCaptionAudioPayload{} },
+	InboundWbOp:                 func() any { return &InboundWbOpPayload{} },
 }
@@ -1,5 +1,11 @@
 package messages
 
+import (
+	"time"
+
+	"github.com/serozhenka/shary/internal/whiteboard"
+)
+
 type OutboundMessageType string
 
 const (
@@ -11,9 +17,41 @@ const (
 	OutboudData                OutboundMessageType = "data"
 	OutboudIceCandidate        OutboundMessageType = "iceCandidate"
 	OutboundTrackMuted         OutboundMessageType = "trackMuted"
+	OutboundTrackUnmuted       OutboundMessageType = "trackUnmuted"
 	OutboundStreamMetadata     OutboundMessageType = "streamMetadata"
 	OutboundScreenShareStarted OutboundMessageType = "screenShareStarted"
 	OutboundScreenShareStopped OutboundMessageType = "screenShareStopped"
+	OutboundRoleChanged        OutboundMessageType = "roleChanged"
+	OutboundError              OutboundMessageType = "error"
+	OutboundKicked             OutboundMessageType = "kicked"
+	OutboundMute               OutboundMessageType = "mute"
+	OutboundStopScreenShare    OutboundMessageType = "stopScreenShare"
+	OutboundMeetingLocked      OutboundMessageType = "meetingLocked"
+	OutboundLobbyStatus        OutboundMessageType = "lobbyStatus"
+	OutboundLobbyJoinRequest   OutboundMessageType = "lobbyJoinRequest"
+	OutboundLobbyJoinResolved  OutboundMessageType = "lobbyJoinResolved"
+	OutboundChatHistory        OutboundMessageType = "chatHistory"
+	OutboundChatEdited         OutboundMessageType = "chatEdited"
+	OutboundChatDeleted        OutboundMessageType = "chatDeleted"
+	OutboundChatReacted        OutboundMessageType = "chatReacted"
+	OutboundTyping             OutboundMessageType = "typing"
+	OutboundChatRead           OutboundMessageType = "chatRead"
+	OutboundHandQueue          OutboundMessageType = "handQueue"
+	OutboundPollCreated        OutboundMessageType = "pollCreated"
+	OutboundPollUpdated        OutboundMessageType = "pollUpdated"
+	OutboundPollClosed         OutboundMessageType = "pollClosed"
+	OutboundMoveToBreakout     OutboundMessageType = "moveToBreakout"
+	OutboundBreakouts          OutboundMessageType = "breakouts"
+	OutboundBreakoutBroadcast  OutboundMessageType = "breakoutBroadcast"
+	OutboundTrackPublished     OutboundMessageType = "trackPublished"
+	OutboundRecordingStarted   OutboundMessageType = "recordingStarted"
+	OutboundRecordingStopped   OutboundMessageType = "recordingStopped"
+	OutboundAttendeeCount      OutboundMessageType = "attendeeCount"
+	OutboundPoorConnection     OutboundMessageType = "poorConnection"
+	OutboundActiveSpeaker      OutboundMessageType = "activeSpeakerChanged"
+	OutboundLayerPreference    OutboundMessageType = "layerPreference"
+	OutboundCaption            OutboundMessageType = "caption"
+	OutboundWbOp               OutboundMessageType = "wbOp"
 )
 
 type OutboundWsMessage struct {
@@ -23,22 +61,171 @@ type OutboundWsMessage struct {
 
 type InitClient struct {
 	Id       string `json:"id"`
+	UserId   uint   `json:"userId"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
+
+	// Media state, as announced by the client so far
+	MutedTracks   []string          `json:"mutedTracks,omitempty"`
+	Streams       map[string]string `json:"streams,omitempty"` // Stream ID to "media" | "screen"
+	ScreenSharing bool              `json:"screenSharing,omitempty"`
 }
 
 type OutboundInitPayload struct {
+	Role    string       `json:"role"`
+	Locked  bool         `json:"locked"`
 	Clients []InitClient `json:"clients"`
+	Lobby   []InitClient `json:"lobby,omitempty"` // Only sent to moderators
+	Hands   []RaisedHand `json:"hands"`
+	SFU     bool         `json:"sfu,omitempty"` // Negotiate media with the server instead of each peer
+	Mode    string       `json:"mode"`          // "meeting" | "audio_only" | "webinar"
+
+	// Webinar attendees only see the hosts in Clients, everyone gets the count
+	AttendeeCount *int `json:"attendeeCount,omitempty"`
+
+	ActiveSpeakerId string      `json:"activeSpeakerId,omitempty"` // Dominant speaker, once someone has spoken
+	RecordingId     uint        `json:"recordingId,omitempty"`     // Set while the meeting is being recorded
+	IceServers      []IceServer `json:"iceServers"`                // STUN and TURN servers for the client's connections
+
+	// Elements on the whiteboard, later ops continue from its sequence number
+	Whiteboard whiteboard.Snapshot `json:"whiteboard"`
+}
+
+// IceServer is passed as is to the browser's RTCPeerConnection configuration
+type IceServer struct {
+	Urls       []string `json:"urls"`
+	Username   string   `json:"username,omitempty"`
+	Credential string   `json:"credential,omitempty"`
+}
+
+// RaisedHand is an entry of the meeting's speaking queue
+type RaisedHand struct {
+	ClientId string    `json:"clientId"`
+	UserId   uint      `json:"userId"`
+	Username string    `json:"username"`
+	RaisedAt time.Time `json:"raisedAt"`
 }
 
 type OutboundClientJoinedPayload struct {
 	ClientId string `json:"clientId"`
+	UserId   uint   `json:"userId"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
 }
 
 type OutboundClientLeftPayload struct {
 	ClientId string `json:"clientId"`
 }
 
+type OutboundDataPayload struct {
+	Id         uint            `json:"id"`
+	ClientId   string          `json:"clientId,omitempty"`
+	UserId     uint            `json:"userId"`
+	Username   string          `json:"username"`
+	Message    string          `json:"message"`
+	ReplyTo    *uint           `json:"replyTo,omitempty"`
+	Recipient  *uint           `json:"recipientId,omitempty"` // Set for private messages
+	CreatedAt  time.Time       `json:"createdAt"`
+	EditedAt   *time.Time      `json:"editedAt,omitempty"`
+	Deleted    bool            `json:"deleted,omitempty"` // Deleted messages keep their place with an empty body
+	Reactions  []ChatReaction  `json:"reactions"`
+	Attachment *ChatAttachment `json:"attachment,omitempty"`
+}
+
+// ChatAttachment describes a file shared in the chat, clients fetch a
+// download link with GET /rooms/:id/attachments/:attachmentId
+type ChatAttachment struct {
+	Id          uint   `json:"id"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+type ChatReaction struct {
+	Emoji   string `json:"emoji"`
+	UserIds []uint `json:"userIds"`
+}
+
+type OutboundChatHistoryPayload struct {
+	Messages []OutboundDataPayload `json:"messages"`
+	HasMore  bool                  `json:"hasMore"`
+}
+
+// OutboundHandQueuePayload carries the whole speaking queue after each change
+type OutboundHandQueuePayload struct {
+	ClientId string       `json:"clientId,omitempty"` // Client who changed the queue, if any
+	Queue    []RaisedHand `json:"queue"`
+}
+
+type OutboundPollPayload struct {
+	Id          uint                 `json:"id"`
+	CreatorId   uint                 `json:"creatorId"`
+	Question    string               `json:"question"`
+	Anonymous   bool                 `json:"anonymous"`
+	MultiChoice bool                 `json:"multiChoice"`
+	ClosesAt    *time.Time           `json:"closesAt,omitempty"`
+	Closed      bool                 `json:"closed"`
+	TotalVoters int                  `json:"totalVoters"`
+	Options     []OutboundPollOption `json:"options"`
+}
+
+type OutboundPollOption struct {
+	Id       uint   `json:"id"`
+	Text     string `json:"text"`
+	Votes    int    `json:"votes"`
+	VoterIds []uint `json:"voterIds,omitempty"` // Never sent for anonymous polls
+}
+
+// OutboundMoveToBreakoutPayload tells a client it was moved to another
+// meeting, an init for the new meeting follows on the same connection
+type OutboundMoveToBreakoutPayload struct {
+	BreakoutId string     `json:"breakoutId,omitempty"` // Empty when returning to the main meeting
+	Name       string     `json:"name"`
+	EndsAt     *time.Time `json:"endsAt,omitempty"`
+}
+
+// OutboundBreakoutsPayload carries all breakouts of the meeting after each
+// change, the list is empty once they have ended
+type OutboundBreakoutsPayload struct {
+	ClientId  string     `json:"clientId,omitempty"` // Moderator who made the change, if any
+	Breakouts []Breakout `json:"breakouts"`
+	EndsAt    *time.Time `json:"endsAt,omitempty"`
+}
+
+type Breakout struct {
+	Id        string   `json:"id"`
+	Name      string   `json:"name"`
+	ClientIds []string `json:"clientIds"`
+}
+
+type OutboundBreakoutBroadcastPayload struct {
+	ClientId string `json:"clientId"`
+	Username string `json:"username"`
+	Message  string `json:"message"`
+}
+
+// OutboundTrackPublishedPayload announces a track the SFU is about to offer
+// to the client, so it knows whose media the track carries
+type OutboundTrackPublishedPayload struct {
+	ClientId string `json:"clientId"`
+	StreamId string `json:"streamId"`
+	TrackId  string `json:"trackId"`
+	Kind     string `json:"kind"` // "audio" | "video"
+}
+
+type OutboundTypingPayload struct {
+	ClientId string `json:"clientId"`
+	UserId   uint   `json:"userId"`
+	Username string `json:"username"`
+	Typing   bool   `json:"typing"`
+}
+
+type OutboundChatReadPayload struct {
+	ClientId  string `json:"clientId"`
+	UserId    uint   `json:"userId"`
+	MessageId uint   `json:"messageId"`
+}
+
 type OutboundOfferPayload struct {
 	MessageId string `json:"messageId"`
 	Value     struct {
@@ -68,6 +255,11 @@ type OutboundTrackMutedPayload struct {
 	TrackKind string `json:"trackKind"`
 }
 
+type OutboundTrackUnmutedPayload struct {
+	ClientId  string `json:"clientId"`
+	TrackKind string `json:"trackKind"`
+}
+
 type OutboundStreamMetadataPayload struct {
 	ClientId   string `json:"clientId"`
 	StreamId   string `json:"streamId"`
@@ -81,3 +273,110 @@ type OutboundScreenShareStartedPayload struct {
 type OutboundScreenShareStoppedPayload struct {
 	ClientId string `json:"clientId"`
 }
+
+type OutboundRoleChangedPayload struct {
+	UserId uint   `json:"userId"`
+	Role   string `json:"role"`
+}
+
+type OutboundErrorPayload struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type OutboundKickedPayload struct {
+	Reason   string `json:"reason"`
+	ClientId string `json:"clientId,omitempty"` // Moderator who issued the kick, if any
+}
+
+type OutboundMutePayload struct {
+	ClientId  string `json:"clientId"` // Moderator who issued the command
+	TrackKind string `json:"trackKind"`
+}
+
+type OutboundStopScreenSharePayload struct {
+	ClientId string `json:"clientId"` // Moderator who issued the command
+}
+
+type OutboundMeetingLockedPayload struct {
+	ClientId string `json:"clientId"`
+	Locked   bool   `json:"locked"`
+}
+
+type LobbyStatus string
+
+const (
+	LobbyWaiting  LobbyStatus = "waiting"
+	LobbyAdmitted LobbyStatus = "admitted"
+	LobbyDenied   LobbyStatus = "denied"
+	LobbyLeft     LobbyStatus = "left"
+)
+
+type OutboundLobbyStatusPayload struct {
+	Status LobbyStatus `json:"status"`
+	Reason string      `json:"reason,omitempty"`
+}
+
+type OutboundLobbyJoinRequestPayload struct {
+	ClientId string `json:"clientId"`
+	UserId   uint   `json:"userId"`
+	Username string `json:"username"`
+}
+
+type OutboundLobbyJoinResolvedPayload struct {
+	ClientId string      `json:"clientId"`
+	Status   LobbyStatus `json:"status"`
+}
+
+type OutboundRecordingStartedPayload struct {
+	RecordingId uint      `json:"recordingId"`
+	ClientId    string    `json:"clientId"` // Moderator who started the recording
+	StartedAt   time.Time `json:"startedAt"`
+}
+
+type OutboundRecordingStoppedPayload struct {
+	RecordingId uint   `json:"recordingId"`
+	ClientId    string `json:"clientId,omitempty"` // Empty when the meeting ended
+	Status      string `json:"status"`             // "completed" | "failed"
+}
+
+type OutboundAttendeeCountPayload struct {
+	Count int `json:"count"`
+}
+
+// OutboundPoorConnectionPayload hints that a peer's connection got poor, or
+// recovered, along with the worst stats it reported
+type OutboundPoorConnectionPayload struct {
+	ClientId   string  `json:"clientId"`
+	Poor       bool    `json:"poor"`
+	Rtt        float64 `json:"rtt"`
+	PacketLoss float64 `json:"packetLoss"`
+	Jitter     float64 `json:"jitter"`
+}
+
+type OutboundActiveSpeakerPayload struct {
+	ClientId string `json:"clientId"` // Empty once the speaker left and nobody else speaks
+}
+
+// OutboundLayerPreferencePayload tells a publisher the highest layer anyone
+// renders its video at. ClientId is the subscribing peer, or the SFU's
+// publisher connection when media is forwarded by the server.
+type OutboundLayerPreferencePayload struct {
+	ClientId  string `json:"clientId"`
+	Layer     string `json:"layer"`     // "high", "medium", "low" or "off"
+	MaxHeight int    `json:"maxHeight"` // Nominal height of the layer, 0 when off
+}
+
+// OutboundCaptionPayload is recognised speech of a client. Interim captions
+// are revised by later ones until a final caption settles the text.
+type OutboundCaptionPayload struct {
+	ClientId  string    `json:"clientId"`
+	Username  string    `json:"username"`
+	Text      string    `json:"text"`
+	Final     bool      `json:"final"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// OutboundWbOpPayload is an op applied to the whiteboard, numbered in the
+// order ops were applied
+type OutboundWbOpPayload = whiteboard.Op
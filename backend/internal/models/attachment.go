@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Attachment represents a file uploaded to a room, chat messages may reference it
+type Attachment struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	RoomID      uint      `gorm:"not null;index" json:"room_id"`
+	UploaderID  uint      `gorm:"not null" json:"uploader_id"`
+	FileName    string    `gorm:"size:255;not null" json:"file_name"`
+	ContentType string    `gorm:"size:100;not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	StorageKey  string    `gorm:"size:255;not null;uniqueIndex" json:"-"`
+	CreatedAt   time.Time `gorm:"not null;default:now()" json:"created_at"`
+
+	// Relationships
+	Uploader User `gorm:"foreignKey:UploaderID" json:"-"`
+	Room     Room `gorm:"foreignKey:RoomID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (Attachment) TableName() string {
+	return "attachments"
+}
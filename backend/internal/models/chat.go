@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// ChatMessage represents a message posted to a room's chat
+type ChatMessage struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	RoomID    uint   `gorm:"not null;index" json:"room_id"`
+	AuthorID  uint   `gorm:"not null" json:"author_id"`
+	Body      string `gorm:"type:text;not null" json:"body"`
+	ReplyToID *uint  `json:"reply_to_id"`
+	// RecipientID is set for private messages, which only the author and the
+	// recipient can see
+	RecipientID  *uint      `gorm:"index" json:"recipient_id"`
+	AttachmentID *uint      `gorm:"index" json:"attachment_id"`
+	CreatedAt    time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	EditedAt     *time.Time `json:"edited_at"`
+	DeletedAt    *time.Time `json:"deleted_at"` // Soft delete, the message stays as a tombstone
+
+	// Relationships
+	Author     User           `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Attachment *Attachment    `gorm:"foreignKey:AttachmentID;constraint:OnDelete:SET NULL" json:"attachment,omitempty"`
+	Room       Room           `gorm:"foreignKey:RoomID;constraint:OnDelete:CASCADE" json:"-"`
+	Reactions  []ChatReaction `gorm:"foreignKey:MessageID;constraint:OnDelete:CASCADE" json:"reactions,omitempty"`
+}
+
+func (ChatMessage) TableName() string {
+	return "chat_messages"
+}
+
+// IsDeleted reports whether the message was soft deleted
+func (m *ChatMessage) IsDeleted() bool {
+	return m.DeletedAt != nil
+}
+
+// VisibleTo reports whether the user may see the message
+func (m *ChatMessage) VisibleTo(userID uint) bool {
+	return m.RecipientID == nil || m.AuthorID == userID || *m.RecipientID == userID
+}
+
+// ChatReaction represents a user's emoji reaction to a chat message
+type ChatReaction struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	MessageID uint      `gorm:"not null;uniqueIndex:idx_chat_reaction" json:"message_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_chat_reaction" json:"user_id"`
+	Emoji     string    `gorm:"size:32;not null;uniqueIndex:idx_chat_reaction" json:"emoji"`
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+}
+
+func (ChatReaction) TableName() string {
+	return "chat_reactions"
+}
+
+// ChatReadMarker tracks the last chat message of a room a user has read
+type ChatReadMarker struct {
+	RoomID     uint      `gorm:"primaryKey" json:"room_id"`
+	UserID     uint      `gorm:"primaryKey" json:"user_id"`
+	LastReadID uint      `gorm:"not null" json:"last_read_id"`
+	UpdatedAt  time.Time `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relationships
+	Room Room `gorm:"foreignKey:RoomID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (ChatReadMarker) TableName() string {
+	return "chat_read_markers"
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Poll represents a question asked to the participants of a room
+type Poll struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	RoomID      uint       `gorm:"not null;index" json:"room_id"`
+	CreatorID   uint       `gorm:"not null" json:"creator_id"`
+	Question    string     `gorm:"size:300;not null" json:"question"`
+	Anonymous   bool       `gorm:"not null;default:false" json:"anonymous"`
+	MultiChoice bool       `gorm:"not null;default:false" json:"multi_choice"`
+	ClosesAt    *time.Time `json:"closes_at"` // Optional timeout
+	ClosedAt    *time.Time `json:"closed_at"`
+	CreatedAt   time.Time  `gorm:"not null;default:now()" json:"created_at"`
+
+	// Relationships
+	Room    Room         `gorm:"foreignKey:RoomID;constraint:OnDelete:CASCADE" json:"-"`
+	Options []PollOption `gorm:"foreignKey:PollID;constraint:OnDelete:CASCADE" json:"options"`
+}
+
+func (Poll) TableName() string {
+	return "polls"
+}
+
+// IsClosed reports whether the poll was closed or its timeout has passed
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.ClosedAt != nil || (p.ClosesAt != nil && !now.Before(*p.ClosesAt))
+}
+
+// PollOption is one of the answers of a poll
+type PollOption struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	PollID   uint   `gorm:"not null;index" json:"poll_id"`
+	Position int    `gorm:"not null" json:"position"`
+	Text     string `gorm:"size:100;not null" json:"text"`
+}
+
+func (PollOption) TableName() string {
+	return "poll_options"
+}
+
+// PollVote is a user's choice of a poll option, multi-choice polls allow
+// several votes per user
+type PollVote struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PollID    uint      `gorm:"not null;uniqueIndex:idx_poll_vote" json:"poll_id"`
+	OptionID  uint      `gorm:"not null;uniqueIndex:idx_poll_vote" json:"option_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_poll_vote" json:"user_id"`
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+
+	// Relationships
+	Poll   Poll       `gorm:"foreignKey:PollID;constraint:OnDelete:CASCADE" json:"-"`
+	Option PollOption `gorm:"foreignKey:OptionID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (PollVote) TableName() string {
+	return "poll_votes"
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+type RecordingStatus string
+
+const (
+	RecordingInProgress RecordingStatus = "recording"
+	RecordingCompleted  RecordingStatus = "completed"
+	RecordingFailed     RecordingStatus = "failed"
+)
+
+// Recording represents a recorded meeting of a room, made of one file per
+// participant track
+type Recording struct {
+	ID          uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	RoomID      uint            `gorm:"not null;index" json:"room_id"`
+	StartedByID uint            `gorm:"not null" json:"started_by_id"`
+	Status      RecordingStatus `gorm:"size:20;not null;default:'recording'" json:"status"`
+	StartedAt   time.Time       `gorm:"not null;default:now()" json:"started_at"`
+	StoppedAt   *time.Time      `json:"stopped_at"`
+
+	// Relationships
+	Room   Room             `gorm:"foreignKey:RoomID;constraint:OnDelete:CASCADE" json:"-"`
+	Tracks []RecordingTrack `gorm:"foreignKey:RecordingID;constraint:OnDelete:CASCADE" json:"tracks"`
+}
+
+func (Recording) TableName() string {
+	return "recordings"
+}
+
+// RecordingTrack is the recorded audio or video of a single participant track
+type RecordingTrack struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	RecordingID uint      `gorm:"not null;index" json:"recording_id"`
+	UserID      uint      `gorm:"not null" json:"user_id"`
+	Kind        string    `gorm:"size:10;not null" json:"kind"` // "audio" | "video"
+	ContentType string    `gorm:"size:100;not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	StorageKey  string    `gorm:"size:255;not null;uniqueIndex" json:"-"`
+	CreatedAt   time.Time `gorm:"not null;default:now()" json:"created_at"`
+}
+
+func (RecordingTrack) TableName() string {
+	return "recording_tracks"
+}
@@ -0,0 +1,84 @@
+package models
+
+// Role represents a participant's role within a room
+type Role string
+
+const (
+	RoleOwner     Role = "owner"
+	RoleModerator Role = "moderator"
+	RoleMember    Role = "member"
+	RoleViewer    Role = "viewer"
+)
+
+// Permission represents an action that can be performed within a room
+type Permission string
+
+const (
+	PermissionUpdateRoom        Permission = "update_room"
+	PermissionDeleteRoom        Permission = "delete_room"
+	PermissionAddParticipant    Permission = "add_participant"
+	PermissionRemoveParticipant Permission = "remove_participant"
+	PermissionManageRoles       Permission = "manage_roles"
+	PermissionTransferOwnership Permission = "transfer_ownership"
+	PermissionPublishMedia      Permission = "publish_media"
+	PermissionSendData          Permission = "send_data"
+	PermissionModerate          Permission = "moderate"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleOwner: {
+		PermissionUpdateRoom,
+		PermissionDeleteRoom,
+		PermissionAddParticipant,
+		PermissionRemoveParticipant,
+		PermissionManageRoles,
+		PermissionTransferOwnership,
+		PermissionPublishMedia,
+		PermissionSendData,
+		PermissionModerate,
+	},
+	RoleModerator: {
+		PermissionUpdateRoom,
+		PermissionAddParticipant,
+		PermissionRemoveParticipant,
+		PermissionManageRoles,
+		PermissionPublishMedia,
+		PermissionSendData,
+		PermissionModerate,
+	},
+	RoleMember: {
+		PermissionPublishMedia,
+		PermissionSendData,
+	},
+	RoleViewer: {
+		PermissionSendData,
+	},
+}
+
+var roleRanks = map[Role]int{
+	RoleOwner:     3,
+	RoleModerator: 2,
+	RoleMember:    1,
+	RoleViewer:    0,
+}
+
+// IsValid reports whether the role is one of the known roles
+func (r Role) IsValid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Can reports whether the role grants the given permission
+func (r Role) Can(p Permission) bool {
+	for _, permission := range rolePermissions[r] {
+		if permission == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Outranks reports whether the role is strictly higher than the other role
+func (r Role) Outranks(other Role) bool {
+	return roleRanks[r] > roleRanks[other]
+}
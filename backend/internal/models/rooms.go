@@ -1,6 +1,27 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+// RoomMode decides who may publish which media in a room
+type RoomMode string
+
+const (
+	RoomModeMeeting   RoomMode = "meeting"    // Everyone allowed to publish shares audio and video
+	RoomModeAudioOnly RoomMode = "audio_only" // Video tracks are rejected
+	RoomModeWebinar   RoomMode = "webinar"    // Only hosts publish, attendees receive
+)
+
+// IsValid reports whether the mode is one of the known modes
+func (m RoomMode) IsValid() bool {
+	switch m {
+	case RoomModeMeeting, RoomModeAudioOnly, RoomModeWebinar:
+		return true
+	}
+	return false
+}
 
 // Room represents a video chat room
 type Room struct {
@@ -9,6 +30,19 @@ type Room struct {
 	Name      string    `gorm:"size:100;not null" json:"name"`
 	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
 
+	// Settings
+	LobbyEnabled      bool `gorm:"not null;default:false" json:"lobby_enabled"`
+	MaxParticipants   int  `gorm:"not null;default:0" json:"max_participants"` // Publishing participants limit, 0 uses the server default
+	OverflowAsViewers bool `gorm:"not null;default:false" json:"overflow_as_viewers"`
+	SFUEnabled        bool `gorm:"not null;default:false" json:"sfu_enabled"` // Route media through the server instead of a full mesh
+
+	Mode RoomMode `gorm:"size:20;not null;default:'meeting'" json:"mode"`
+
+	// Media policy enforced on the session descriptions clients exchange
+	AllowedCodecs    string `gorm:"size:255;not null;default:''" json:"allowed_codecs"` // Comma-separated codec names, empty allows every codec
+	MaxVideoBitrate  int    `gorm:"not null;default:0" json:"max_video_bitrate"`        // In kbps, 0 is unlimited
+	SimulcastEnabled bool   `gorm:"not null;default:true" json:"simulcast_enabled"`
+
 	// Relationships
 	Owner        User          `gorm:"foreignKey:OwnerID" json:"owner,omitempty"`
 	Participants []Participant `gorm:"foreignKey:RoomID" json:"participants,omitempty"`
@@ -21,11 +55,20 @@ func (Room) TableName() string {
 	return "rooms"
 }
 
+// CodecList splits AllowedCodecs into codec names
+func (r *Room) CodecList() []string {
+	if r.AllowedCodecs == "" {
+		return []string{}
+	}
+	return strings.Split(r.AllowedCodecs, ",")
+}
+
 // Participant represents a user's participation in a room
 type Participant struct {
 	ID       uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	UserID   uint      `gorm:"not null" json:"user_id"`
 	RoomID   uint      `gorm:"not null;constraint:OnDelete:CASCADE" json:"room_id"`
+	Role     Role      `gorm:"size:20;not null;default:'member'" json:"role"`
 	JoinedAt time.Time `gorm:"not null;default:now()" json:"joined_at"`
 
 	// Relationships
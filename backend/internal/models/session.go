@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// MeetingSession spans a room's meeting from the first client joining until
+// the last one leaves
+type MeetingSession struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	RoomID    uint       `gorm:"not null;index" json:"room_id"`
+	StartedAt time.Time  `gorm:"not null;default:now()" json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+
+	// Relationships
+	Room Room `gorm:"foreignKey:RoomID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (MeetingSession) TableName() string {
+	return "meeting_sessions"
+}
+
+// QualitySummary sums up the connection quality a client reported during a
+// session
+type QualitySummary struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	SessionID     uint      `gorm:"not null;index" json:"session_id"`
+	UserID        uint      `gorm:"not null" json:"user_id"`
+	ClientID      string    `gorm:"size:27;not null" json:"client_id"`
+	Reports       int       `gorm:"not null" json:"reports"`
+	PoorReports   int       `gorm:"not null" json:"poor_reports"`
+	AvgRTT        float64   `gorm:"not null" json:"avg_rtt"` // In milliseconds
+	MaxRTT        float64   `gorm:"not null" json:"max_rtt"`
+	AvgPacketLoss float64   `gorm:"not null" json:"avg_packet_loss"` // Fraction of packets lost
+	MaxPacketLoss float64   `gorm:"not null" json:"max_packet_loss"`
+	AvgJitter     float64   `gorm:"not null" json:"avg_jitter"`  // In milliseconds
+	AvgBitrate    float64   `gorm:"not null" json:"avg_bitrate"` // In kbps
+	CreatedAt     time.Time `gorm:"not null;default:now()" json:"created_at"`
+
+	// Relationships
+	Session MeetingSession `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (QualitySummary) TableName() string {
+	return "quality_summaries"
+}
+
+// TranscriptSegment is a caption settled during a session
+type TranscriptSegment struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	SessionID uint      `gorm:"not null;index" json:"session_id"`
+	UserID    uint      `gorm:"not null" json:"user_id"`
+	ClientID  string    `gorm:"size:27;not null" json:"client_id"`
+	Speaker   string    `gorm:"size:100;not null" json:"speaker"` // Username at the time
+	Text      string    `gorm:"type:text;not null" json:"text"`
+	StartedAt time.Time `gorm:"not null" json:"started_at"`
+	EndedAt   time.Time `gorm:"not null" json:"ended_at"`
+
+	// Relationships
+	Session MeetingSession `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (TranscriptSegment) TableName() string {
+	return "transcript_segments"
+}
+
+// Whiteboard is a board as it was left when its session ended
+type Whiteboard struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	SessionID uint      `gorm:"not null;index" json:"session_id"`
+	Name      string    `gorm:"size:100;not null" json:"name"` // Of the meeting or breakout it was drawn in
+	Elements  int       `gorm:"not null" json:"elements"`
+	Snapshot  string    `gorm:"type:text;not null" json:"-"` // JSON encoded whiteboard.Snapshot
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+
+	// Relationships
+	Session MeetingSession `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (Whiteboard) TableName() string {
+	return "whiteboards"
+}
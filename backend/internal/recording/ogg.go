@@ -0,0 +1,41 @@
+package recording
+
+import (
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media/oggwriter"
+)
+
+type oggContainer struct {
+	writer  *oggwriter.OggWriter
+	written bool
+}
+
+func newOggContainer(out nopCloser, codec webrtc.RTPCodecParameters) (*oggContainer, error) {
+	channels := codec.Channels
+	if channels == 0 {
+		channels = 2
+	}
+
+	writer, err := oggwriter.NewWith(out, codec.ClockRate, channels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oggContainer{
+		writer: writer,
+	}, nil
+}
+
+func (c *oggContainer) WriteRTP(packet *rtp.Packet) error {
+	if len(packet.Payload) == 0 {
+		return nil
+	}
+
+	c.written = true
+	return c.writer.WriteRTP(packet)
+}
+
+func (c *oggContainer) Close() (bool, error) {
+	return c.written, c.writer.Close()
+}
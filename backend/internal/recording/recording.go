@@ -0,0 +1,100 @@
+// Package recording muxes the RTP packets of forwarded tracks into media
+// files, Opus audio into Ogg and VP8 video into WebM
+package recording
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+)
+
+var ErrUnsupportedCodec = errors.New("codec can't be recorded")
+
+// SaveFunc stores a finished track file, it's read from the start and
+// removed once SaveFunc returns
+type SaveFunc func(file io.Reader, size int64, contentType string) error
+
+// container writes RTP packets into a media file format
+type container interface {
+	WriteRTP(packet *rtp.Packet) error
+	// Close finishes the file, it reports whether any media was written
+	Close() (bool, error)
+}
+
+// TrackWriter records a single track into a temporary file, which is handed
+// over to be saved when the track is closed
+type TrackWriter struct {
+	file        *os.File
+	container   container
+	contentType string
+	save        SaveFunc
+}
+
+func NewTrackWriter(codec webrtc.RTPCodecParameters, save SaveFunc) (*TrackWriter, error) {
+	file, err := os.CreateTemp("", "recording-*")
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &TrackWriter{
+		file: file,
+		save: save,
+	}
+
+	// The containers must not close the file, it's uploaded afterwards
+	out := nopCloser{file}
+	switch strings.ToLower(codec.MimeType) {
+	case strings.ToLower(webrtc.MimeTypeOpus):
+		writer.container, err = newOggContainer(out, codec)
+		writer.contentType = "audio/ogg"
+	case strings.ToLower(webrtc.MimeTypeVP8):
+		writer.container = newWebMContainer(out, codec)
+		writer.contentType = "video/webm"
+	default:
+		err = ErrUnsupportedCodec
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+func (w *TrackWriter) WriteRTP(packet *rtp.Packet) error {
+	return w.container.WriteRTP(packet)
+}
+
+// Close finishes the file and saves it, tracks without any media are dropped
+func (w *TrackWriter) Close() error {
+	defer os.Remove(w.file.Name())
+	defer w.file.Close()
+
+	written, err := w.container.Close()
+	if err != nil || !written {
+		return err
+	}
+
+	size, err := w.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	return w.save(w.file, size, w.contentType)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
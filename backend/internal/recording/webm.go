@@ -0,0 +1,94 @@
+package recording
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/at-wat/ebml-go/webm"
+	"github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+	"github.com/pion/webrtc/v4/pkg/media/samplebuilder"
+)
+
+// Packets are reordered within this window before being given up on
+const maxLatePackets = 128
+
+// webmContainer writes VP8 frames into a WebM file. The file header needs
+// the frame size, so nothing is written until the first keyframe arrives.
+type webmContainer struct {
+	out       nopCloser
+	clockRate uint32
+	builder   *samplebuilder.SampleBuilder
+	writer    webm.BlockWriteCloser
+	start     uint32 // RTP timestamp of the first written frame
+}
+
+func newWebMContainer(out nopCloser, codec webrtc.RTPCodecParameters) *webmContainer {
+	return &webmContainer{
+		out:       out,
+		clockRate: codec.ClockRate,
+		builder:   samplebuilder.New(maxLatePackets, &codecs.VP8Packet{}, codec.ClockRate),
+	}
+}
+
+func (c *webmContainer) WriteRTP(packet *rtp.Packet) error {
+	c.builder.Push(packet)
+
+	for sample := c.builder.Pop(); sample != nil; sample = c.builder.Pop() {
+		if err := c.writeFrame(sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *webmContainer) writeFrame(sample *media.Sample) error {
+	keyframe := isVP8Keyframe(sample.Data)
+
+	if c.writer == nil {
+		if !keyframe {
+			return nil
+		}
+
+		// The frame size follows the keyframe start code
+		width := binary.LittleEndian.Uint16(sample.Data[6:8]) & 0x3fff
+		height := binary.LittleEndian.Uint16(sample.Data[8:10]) & 0x3fff
+
+		writers, err := webm.NewSimpleBlockWriter(c.out, []webm.TrackEntry{{
+			Name:        "Video",
+			TrackNumber: 1,
+			TrackUID:    1,
+			CodecID:     "V_VP8",
+			TrackType:   1,
+			Video: &webm.Video{
+				PixelWidth:  uint64(width),
+				PixelHeight: uint64(height),
+			},
+		}})
+		if err != nil {
+			return err
+		}
+
+		c.writer = writers[0]
+		c.start = sample.PacketTimestamp
+	}
+
+	elapsed := time.Duration(sample.PacketTimestamp-c.start) * time.Second / time.Duration(c.clockRate)
+	_, err := c.writer.Write(keyframe, elapsed.Milliseconds(), sample.Data)
+	return err
+}
+
+func (c *webmContainer) Close() (bool, error) {
+	if c.writer == nil {
+		return false, nil
+	}
+	return true, c.writer.Close()
+}
+
+// isVP8Keyframe checks the frame tag, keyframes also carry the frame size
+// after a 3 byte start code
+func isVP8Keyframe(frame []byte) bool {
+	return len(frame) >= 10 && frame[0]&0x01 == 0
+}
@@ -0,0 +1,16 @@
+package attachments
+
+import (
+	"errors"
+
+	"github.com/serozhenka/shary/internal/models"
+)
+
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// Repository defines the interface for attachment metadata operations, the
+// files themselves live in a storage.BlobStore
+type Repository interface {
+	CreateAttachment(attachment models.Attachment) (*models.Attachment, error)
+	GetAttachment(id uint) (*models.Attachment, error)
+}
@@ -0,0 +1,51 @@
+package attachments
+
+import (
+	"sync"
+	"time"
+
+	"github.com/serozhenka/shary/internal/models"
+)
+
+type inMemoryRepository struct {
+	attachments map[uint]*models.Attachment
+	nextID      uint
+	mutex       sync.RWMutex
+}
+
+// NewInMemoryRepository creates a new in-memory attachments repository
+func NewInMemoryRepository() Repository {
+	return &inMemoryRepository{
+		attachments: make(map[uint]*models.Attachment),
+		nextID:      1,
+	}
+}
+
+func (r *inMemoryRepository) CreateAttachment(attachment models.Attachment) (*models.Attachment, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	attachment.ID = r.nextID
+	r.nextID++
+	if attachment.CreatedAt.IsZero() {
+		attachment.CreatedAt = time.Now()
+	}
+
+	r.attachments[attachment.ID] = &attachment
+
+	attachmentCopy := attachment
+	return &attachmentCopy, nil
+}
+
+func (r *inMemoryRepository) GetAttachment(id uint) (*models.Attachment, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	attachment, exists := r.attachments[id]
+	if !exists {
+		return nil, ErrAttachmentNotFound
+	}
+
+	attachmentCopy := *attachment
+	return &attachmentCopy, nil
+}
@@ -0,0 +1,37 @@
+package attachments
+
+import (
+	"errors"
+
+	"github.com/serozhenka/shary/internal/models"
+	"gorm.io/gorm"
+)
+
+type postgresRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL attachments repository
+func NewPostgresRepository(db *gorm.DB) Repository {
+	return &postgresRepository{
+		db: db,
+	}
+}
+
+func (r *postgresRepository) CreateAttachment(attachment models.Attachment) (*models.Attachment, error) {
+	if err := r.db.Create(&attachment).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *postgresRepository) GetAttachment(id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := r.db.First(&attachment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
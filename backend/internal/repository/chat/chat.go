@@ -0,0 +1,32 @@
+package chat
+
+import (
+	"errors"
+
+	"github.com/serozhenka/shary/internal/models"
+)
+
+var ErrMessageNotFound = errors.New("message not found")
+
+// Repository defines the interface for chat message operations
+type Repository interface {
+	CreateMessage(message models.ChatMessage) (*models.ChatMessage, error)
+	GetMessage(id uint) (*models.ChatMessage, error)
+	// ListMessages returns up to limit messages of the room visible to the
+	// user older than the message with the given ID (or the latest ones if
+	// before is 0), ordered from oldest to newest
+	ListMessages(roomID uint, userID uint, before uint, limit int) ([]*models.ChatMessage, error)
+	UpdateMessageBody(id uint, body string) (*models.ChatMessage, error)
+	SoftDeleteMessage(id uint) (*models.ChatMessage, error)
+
+	// Reactions are unique per message, user and emoji
+	AddReaction(messageID uint, userID uint, emoji string) error
+	RemoveReaction(messageID uint, userID uint, emoji string) error
+
+	// MarkRead moves the user's read marker of the room forward, it never
+	// moves backwards
+	MarkRead(roomID uint, userID uint, messageID uint) error
+	// UnreadCounts returns the number of messages by others visible to the
+	// user past their read marker, rooms without unread messages are omitted
+	UnreadCounts(userID uint, roomIDs []uint) (map[uint]int, error)
+}
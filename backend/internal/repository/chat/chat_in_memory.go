@@ -0,0 +1,200 @@
+package chat
+
+import (
+	"sync"
+	"time"
+
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/repository/users"
+	"github.com/serozhenka/shary/internal/utils"
+)
+
+type inMemoryRepository struct {
+	messages       []*models.ChatMessage
+	nextID         uint
+	nextReactionID uint
+	readMarkers    map[readMarkerKey]uint
+	usersRepo      users.Repository
+	mutex          sync.RWMutex
+}
+
+type readMarkerKey struct {
+	roomID uint
+	userID uint
+}
+
+// NewInMemoryRepository creates a new in-memory chat repository
+func NewInMemoryRepository(usersRepo users.Repository) Repository {
+	return &inMemoryRepository{
+		messages:       make([]*models.ChatMessage, 0),
+		nextID:         1,
+		nextReactionID: 1,
+		readMarkers:    make(map[readMarkerKey]uint),
+		usersRepo:      usersRepo,
+	}
+}
+
+func (r *inMemoryRepository) CreateMessage(message models.ChatMessage) (*models.ChatMessage, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	message.ID = r.nextID
+	r.nextID++
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now()
+	}
+	if author, err := r.usersRepo.GetUserByID(message.AuthorID); err == nil {
+		message.Author = *author
+	}
+
+	r.messages = append(r.messages, &message)
+
+	return copyMessage(&message), nil
+}
+
+func (r *inMemoryRepository) GetMessage(id uint) (*models.ChatMessage, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	message := r.findMessage(id)
+	if message == nil {
+		return nil, ErrMessageNotFound
+	}
+	return copyMessage(message), nil
+}
+
+func (r *inMemoryRepository) ListMessages(roomID uint, userID uint, before uint, limit int) ([]*models.ChatMessage, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	// Walk backwards from the newest message, messages are stored in ID order
+	page := make([]*models.ChatMessage, 0, limit)
+	for i := len(r.messages) - 1; i >= 0 && len(page) < limit; i-- {
+		message := r.messages[i]
+		if message.RoomID != roomID || !message.VisibleTo(userID) || (before != 0 && message.ID >= before) {
+			continue
+		}
+
+		page = append(page, copyMessage(message))
+	}
+
+	for i, j := 0, len(page)-1; i < j; i, j = i+1, j-1 {
+		page[i], page[j] = page[j], page[i]
+	}
+
+	return page, nil
+}
+
+func (r *inMemoryRepository) UpdateMessageBody(id uint, body string) (*models.ChatMessage, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	message := r.findMessage(id)
+	if message == nil {
+		return nil, ErrMessageNotFound
+	}
+
+	now := time.Now()
+	message.Body = body
+	message.EditedAt = &now
+	return copyMessage(message), nil
+}
+
+func (r *inMemoryRepository) SoftDeleteMessage(id uint) (*models.ChatMessage, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	message := r.findMessage(id)
+	if message == nil {
+		return nil, ErrMessageNotFound
+	}
+
+	now := time.Now()
+	message.DeletedAt = &now
+	return copyMessage(message), nil
+}
+
+func (r *inMemoryRepository) AddReaction(messageID uint, userID uint, emoji string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	message := r.findMessage(messageID)
+	if message == nil {
+		return ErrMessageNotFound
+	}
+
+	for _, reaction := range message.Reactions {
+		if reaction.UserID == userID && reaction.Emoji == emoji {
+			return nil
+		}
+	}
+
+	message.Reactions = append(message.Reactions, models.ChatReaction{
+		ID:        r.nextReactionID,
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+		CreatedAt: time.Now(),
+	})
+	r.nextReactionID++
+	return nil
+}
+
+func (r *inMemoryRepository) RemoveReaction(messageID uint, userID uint, emoji string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	message := r.findMessage(messageID)
+	if message == nil {
+		return ErrMessageNotFound
+	}
+
+	message.Reactions = utils.Filter(message.Reactions, func(reaction models.ChatReaction) bool {
+		return reaction.UserID != userID || reaction.Emoji != emoji
+	})
+	return nil
+}
+
+func (r *inMemoryRepository) MarkRead(roomID uint, userID uint, messageID uint) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := readMarkerKey{roomID: roomID, userID: userID}
+	if messageID > r.readMarkers[key] {
+		r.readMarkers[key] = messageID
+	}
+	return nil
+}
+
+func (r *inMemoryRepository) UnreadCounts(userID uint, roomIDs []uint) (map[uint]int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	counts := make(map[uint]int)
+	for _, roomID := range roomIDs {
+		lastRead := r.readMarkers[readMarkerKey{roomID: roomID, userID: userID}]
+		for _, message := range r.messages {
+			if message.RoomID == roomID && message.AuthorID != userID && !message.IsDeleted() &&
+				message.VisibleTo(userID) && message.ID > lastRead {
+				counts[roomID]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (r *inMemoryRepository) findMessage(id uint) *models.ChatMessage {
+	for _, message := range r.messages {
+		if message.ID == id {
+			return message
+		}
+	}
+	return nil
+}
+
+// copyMessage returns a copy of the message that doesn't share its reactions
+func copyMessage(message *models.ChatMessage) *models.ChatMessage {
+	messageCopy := *message
+	messageCopy.Reactions = append([]models.ChatReaction(nil), message.Reactions...)
+	return &messageCopy
+}
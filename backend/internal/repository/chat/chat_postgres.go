@@ -0,0 +1,147 @@
+package chat
+
+import (
+	"errors"
+	"time"
+
+	"github.com/serozhenka/shary/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL chat repository
+func NewPostgresRepository(db *gorm.DB) Repository {
+	return &postgresRepository{
+		db: db,
+	}
+}
+
+func (r *postgresRepository) CreateMessage(message models.ChatMessage) (*models.ChatMessage, error) {
+	if err := r.db.Omit(clause.Associations).Create(&message).Error; err != nil {
+		return nil, err
+	}
+
+	// Load relationships
+	r.db.Preload("Author").Preload("Reactions").Preload("Attachment").First(&message, message.ID)
+
+	return &message, nil
+}
+
+func (r *postgresRepository) GetMessage(id uint) (*models.ChatMessage, error) {
+	var message models.ChatMessage
+	if err := r.db.Preload("Author").Preload("Reactions").Preload("Attachment").First(&message, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *postgresRepository) ListMessages(roomID uint, userID uint, before uint, limit int) ([]*models.ChatMessage, error) {
+	var messages []*models.ChatMessage
+
+	query := r.db.Preload("Author").Preload("Reactions").Preload("Attachment").Where("room_id = ?", roomID).
+		Where("recipient_id IS NULL OR author_id = ? OR recipient_id = ?", userID, userID)
+	if before != 0 {
+		query = query.Where("id < ?", before)
+	}
+
+	// Fetch the newest page first, then flip it into chronological order
+	if err := query.Order("id DESC").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	return messages, nil
+}
+
+func (r *postgresRepository) UpdateMessageBody(id uint, body string) (*models.ChatMessage, error) {
+	err := r.db.Model(&models.ChatMessage{}).Where("id = ?", id).
+		Updates(map[string]any{"body": body, "edited_at": time.Now()}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetMessage(id)
+}
+
+func (r *postgresRepository) SoftDeleteMessage(id uint) (*models.ChatMessage, error) {
+	err := r.db.Model(&models.ChatMessage{}).Where("id = ?", id).
+		Update("deleted_at", time.Now()).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetMessage(id)
+}
+
+func (r *postgresRepository) AddReaction(messageID uint, userID uint, emoji string) error {
+	reaction := &models.ChatReaction{
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+	}
+
+	// Reacting twice with the same emoji is a no-op
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction).Error
+}
+
+func (r *postgresRepository) RemoveReaction(messageID uint, userID uint, emoji string) error {
+	return r.db.
+		Where("message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
+		Delete(&models.ChatReaction{}).Error
+}
+
+func (r *postgresRepository) MarkRead(roomID uint, userID uint, messageID uint) error {
+	marker := &models.ChatReadMarker{
+		RoomID:     roomID,
+		UserID:     userID,
+		LastReadID: messageID,
+		UpdatedAt:  time.Now(),
+	}
+
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "room_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"last_read_id": gorm.Expr("GREATEST(chat_read_markers.last_read_id, EXCLUDED.last_read_id)"),
+			"updated_at":   gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).Create(marker).Error
+}
+
+func (r *postgresRepository) UnreadCounts(userID uint, roomIDs []uint) (map[uint]int, error) {
+	counts := make(map[uint]int)
+	if len(roomIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		RoomID uint
+		Count  int
+	}
+	err := r.db.Table("chat_messages AS m").
+		Select("m.room_id, COUNT(*) AS count").
+		Joins("LEFT JOIN chat_read_markers AS r ON r.room_id = m.room_id AND r.user_id = ?", userID).
+		Where("m.room_id IN ?", roomIDs).
+		Where("m.author_id <> ? AND m.deleted_at IS NULL", userID).
+		Where("m.recipient_id IS NULL OR m.recipient_id = ?", userID).
+		Where("m.id > COALESCE(r.last_read_id, 0)").
+		Group("m.room_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.RoomID] = row.Count
+	}
+	return counts, nil
+}
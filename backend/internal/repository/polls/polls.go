@@ -0,0 +1,24 @@
+package polls
+
+import (
+	"errors"
+
+	"github.com/serozhenka/shary/internal/models"
+)
+
+var ErrPollNotFound = errors.New("poll not found")
+
+// Repository defines the interface for poll operations
+type Repository interface {
+	// CreatePoll stores the poll together with its options
+	CreatePoll(poll models.Poll) (*models.Poll, error)
+	GetPoll(id uint) (*models.Poll, error)
+	// ListPolls returns the room's polls, newest first
+	ListPolls(roomID uint) ([]*models.Poll, error)
+	// ClosePoll marks the poll as closed, reporting false if it already was
+	ClosePoll(id uint) (bool, error)
+
+	// ReplaceVotes swaps the user's votes on the poll for the given options
+	ReplaceVotes(pollID uint, userID uint, optionIDs []uint) error
+	ListVotes(pollID uint) ([]models.PollVote, error)
+}
@@ -0,0 +1,139 @@
+package polls
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/utils"
+)
+
+type inMemoryRepository struct {
+	polls        []*models.Poll
+	votes        []models.PollVote
+	nextID       uint
+	nextOptionID uint
+	nextVoteID   uint
+	mutex        sync.RWMutex
+}
+
+// NewInMemoryRepository creates a new in-memory polls repository
+func NewInMemoryRepository() Repository {
+	return &inMemoryRepository{
+		polls:        make([]*models.Poll, 0),
+		votes:        make([]models.PollVote, 0),
+		nextID:       1,
+		nextOptionID: 1,
+		nextVoteID:   1,
+	}
+}
+
+func (r *inMemoryRepository) CreatePoll(poll models.Poll) (*models.Poll, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	poll.ID = r.nextID
+	r.nextID++
+	if poll.CreatedAt.IsZero() {
+		poll.CreatedAt = time.Now()
+	}
+
+	poll.Options = slices.Clone(poll.Options)
+	for i := range poll.Options {
+		poll.Options[i].ID = r.nextOptionID
+		poll.Options[i].PollID = poll.ID
+		r.nextOptionID++
+	}
+
+	r.polls = append(r.polls, &poll)
+	return copyPoll(&poll), nil
+}
+
+func (r *inMemoryRepository) GetPoll(id uint) (*models.Poll, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	poll := r.findPoll(id)
+	if poll == nil {
+		return nil, ErrPollNotFound
+	}
+	return copyPoll(poll), nil
+}
+
+func (r *inMemoryRepository) ListPolls(roomID uint) ([]*models.Poll, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	polls := make([]*models.Poll, 0)
+	for i := len(r.polls) - 1; i >= 0; i-- {
+		if r.polls[i].RoomID == roomID {
+			polls = append(polls, copyPoll(r.polls[i]))
+		}
+	}
+	return polls, nil
+}
+
+func (r *inMemoryRepository) ClosePoll(id uint) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	poll := r.findPoll(id)
+	if poll == nil || poll.ClosedAt != nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	poll.ClosedAt = &now
+	return true, nil
+}
+
+func (r *inMemoryRepository) ReplaceVotes(pollID uint, userID uint, optionIDs []uint) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.votes = utils.Filter(r.votes, func(vote models.PollVote) bool {
+		return vote.PollID != pollID || vote.UserID != userID
+	})
+
+	for _, optionID := range optionIDs {
+		r.votes = append(r.votes, models.PollVote{
+			ID:        r.nextVoteID,
+			PollID:    pollID,
+			OptionID:  optionID,
+			UserID:    userID,
+			CreatedAt: time.Now(),
+		})
+		r.nextVoteID++
+	}
+	return nil
+}
+
+func (r *inMemoryRepository) ListVotes(pollID uint) ([]models.PollVote, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	votes := utils.Filter(r.votes, func(vote models.PollVote) bool {
+		return vote.PollID == pollID
+	})
+	if votes == nil {
+		votes = make([]models.PollVote, 0)
+	}
+	return votes, nil
+}
+
+func (r *inMemoryRepository) findPoll(id uint) *models.Poll {
+	for _, poll := range r.polls {
+		if poll.ID == id {
+			return poll
+		}
+	}
+	return nil
+}
+
+// copyPoll returns a copy of the poll that doesn't share its options
+func copyPoll(poll *models.Poll) *models.Poll {
+	pollCopy := *poll
+	pollCopy.Options = slices.Clone(poll.Options)
+	return &pollCopy
+}
@@ -0,0 +1,80 @@
+package polls
+
+import (
+	"errors"
+	"time"
+
+	"github.com/serozhenka/shary/internal/models"
+	"gorm.io/gorm"
+)
+
+type postgresRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL polls repository
+func NewPostgresRepository(db *gorm.DB) Repository {
+	return &postgresRepository{
+		db: db,
+	}
+}
+
+func (r *postgresRepository) CreatePoll(poll models.Poll) (*models.Poll, error) {
+	if err := r.db.Create(&poll).Error; err != nil {
+		return nil, err
+	}
+	return &poll, nil
+}
+
+func (r *postgresRepository) GetPoll(id uint) (*models.Poll, error) {
+	var poll models.Poll
+	err := r.db.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).First(&poll, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPollNotFound
+		}
+		return nil, err
+	}
+	return &poll, nil
+}
+
+func (r *postgresRepository) ListPolls(roomID uint) ([]*models.Poll, error) {
+	var polls []*models.Poll
+	err := r.db.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Where("room_id = ?", roomID).Order("id DESC").Find(&polls).Error
+	return polls, err
+}
+
+func (r *postgresRepository) ClosePoll(id uint) (bool, error) {
+	result := r.db.Model(&models.Poll{}).
+		Where("id = ? AND closed_at IS NULL", id).
+		Update("closed_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *postgresRepository) ReplaceVotes(pollID uint, userID uint, optionIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("poll_id = ? AND user_id = ?", pollID, userID).Delete(&models.PollVote{}).Error
+		if err != nil {
+			return err
+		}
+
+		votes := make([]models.PollVote, 0, len(optionIDs))
+		for _, optionID := range optionIDs {
+			votes = append(votes, models.PollVote{PollID: pollID, OptionID: optionID, UserID: userID})
+		}
+		if len(votes) == 0 {
+			return nil
+		}
+		return tx.Create(&votes).Error
+	})
+}
+
+func (r *postgresRepository) ListVotes(pollID uint) ([]models.PollVote, error) {
+	var votes []models.PollVote
+	err := r.db.Where("poll_id = ?", pollID).Order("id").Find(&votes).Error
+	return votes, err
+}
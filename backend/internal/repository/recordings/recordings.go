@@ -0,0 +1,26 @@
+package recordings
+
+import (
+	"errors"
+	"time"
+
+	"github.com/serozhenka/shary/internal/models"
+)
+
+var (
+	ErrRecordingNotFound = errors.New("recording not found")
+	ErrTrackNotFound     = errors.New("recording track not found")
+)
+
+// Repository defines the interface for recording metadata operations, the
+// recorded files live in a storage.BlobStore
+type Repository interface {
+	CreateRecording(recording models.Recording) (*models.Recording, error)
+	GetRecording(id uint) (*models.Recording, error)
+	// ListRecordings returns the room's recordings with their tracks, newest first
+	ListRecordings(roomID uint) ([]*models.Recording, error)
+	FinishRecording(id uint, status models.RecordingStatus, stoppedAt time.Time) (*models.Recording, error)
+
+	AddTrack(track models.RecordingTrack) (*models.RecordingTrack, error)
+	GetTrack(id uint) (*models.RecordingTrack, error)
+}
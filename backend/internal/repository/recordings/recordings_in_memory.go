@@ -0,0 +1,130 @@
+package recordings
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/serozhenka/shary/internal/models"
+)
+
+type inMemoryRepository struct {
+	recordings  []*models.Recording
+	nextID      uint
+	nextTrackID uint
+	mutex       sync.RWMutex
+}
+
+// NewInMemoryRepository creates a new in-memory recordings repository
+func NewInMemoryRepository() Repository {
+	return &inMemoryRepository{
+		recordings:  make([]*models.Recording, 0),
+		nextID:      1,
+		nextTrackID: 1,
+	}
+}
+
+func (r *inMemoryRepository) CreateRecording(recording models.Recording) (*models.Recording, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	recording.ID = r.nextID
+	r.nextID++
+	if recording.Status == "" {
+		recording.Status = models.RecordingInProgress
+	}
+	if recording.StartedAt.IsZero() {
+		recording.StartedAt = time.Now()
+	}
+	recording.Tracks = make([]models.RecordingTrack, 0)
+
+	r.recordings = append(r.recordings, &recording)
+	return copyRecording(&recording), nil
+}
+
+func (r *inMemoryRepository) GetRecording(id uint) (*models.Recording, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	recording := r.findRecording(id)
+	if recording == nil {
+		return nil, ErrRecordingNotFound
+	}
+	return copyRecording(recording), nil
+}
+
+func (r *inMemoryRepository) ListRecordings(roomID uint) ([]*models.Recording, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	recordings := make([]*models.Recording, 0)
+	for i := len(r.recordings) - 1; i >= 0; i-- {
+		if r.recordings[i].RoomID == roomID {
+			recordings = append(recordings, copyRecording(r.recordings[i]))
+		}
+	}
+	return recordings, nil
+}
+
+func (r *inMemoryRepository) FinishRecording(id uint, status models.RecordingStatus, stoppedAt time.Time) (*models.Recording, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	recording := r.findRecording(id)
+	if recording == nil {
+		return nil, ErrRecordingNotFound
+	}
+
+	recording.Status = status
+	recording.StoppedAt = &stoppedAt
+	return copyRecording(recording), nil
+}
+
+func (r *inMemoryRepository) AddTrack(track models.RecordingTrack) (*models.RecordingTrack, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	recording := r.findRecording(track.RecordingID)
+	if recording == nil {
+		return nil, ErrRecordingNotFound
+	}
+
+	track.ID = r.nextTrackID
+	r.nextTrackID++
+	if track.CreatedAt.IsZero() {
+		track.CreatedAt = time.Now()
+	}
+
+	recording.Tracks = append(recording.Tracks, track)
+	return &track, nil
+}
+
+func (r *inMemoryRepository) GetTrack(id uint) (*models.RecordingTrack, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, recording := range r.recordings {
+		for _, track := range recording.Tracks {
+			if track.ID == id {
+				return &track, nil
+			}
+		}
+	}
+	return nil, ErrTrackNotFound
+}
+
+func (r *inMemoryRepository) findRecording(id uint) *models.Recording {
+	for _, recording := range r.recordings {
+		if recording.ID == id {
+			return recording
+		}
+	}
+	return nil
+}
+
+// copyRecording returns a copy of the recording that doesn't share its tracks
+func copyRecording(recording *models.Recording) *models.Recording {
+	recordingCopy := *recording
+	recordingCopy.Tracks = slices.Clone(recording.Tracks)
+	return &recordingCopy
+}
@@ -0,0 +1,80 @@
+package recordings
+
+import (
+	"errors"
+	"time"
+
+	"github.com/serozhenka/shary/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL recordings repository
+func NewPostgresRepository(db *gorm.DB) Repository {
+	return &postgresRepository{
+		db: db,
+	}
+}
+
+func (r *postgresRepository) CreateRecording(recording models.Recording) (*models.Recording, error) {
+	if err := r.db.Omit(clause.Associations).Create(&recording).Error; err != nil {
+		return nil, err
+	}
+	return &recording, nil
+}
+
+func (r *postgresRepository) GetRecording(id uint) (*models.Recording, error) {
+	var recording models.Recording
+	if err := r.db.Preload("Tracks").First(&recording, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordingNotFound
+		}
+		return nil, err
+	}
+	return &recording, nil
+}
+
+func (r *postgresRepository) ListRecordings(roomID uint) ([]*models.Recording, error) {
+	var recordings []*models.Recording
+	err := r.db.
+		Preload("Tracks", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("room_id = ?", roomID).
+		Order("id DESC").
+		Find(&recordings).Error
+	return recordings, err
+}
+
+func (r *postgresRepository) FinishRecording(id uint, status models.RecordingStatus, stoppedAt time.Time) (*models.Recording, error) {
+	result := r.db.Model(&models.Recording{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "stopped_at": stoppedAt})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrRecordingNotFound
+	}
+	return r.GetRecording(id)
+}
+
+func (r *postgresRepository) AddTrack(track models.RecordingTrack) (*models.RecordingTrack, error) {
+	if err := r.db.Create(&track).Error; err != nil {
+		return nil, err
+	}
+	return &track, nil
+}
+
+func (r *postgresRepository) GetTrack(id uint) (*models.RecordingTrack, error) {
+	var track models.RecordingTrack
+	if err := r.db.First(&track, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTrackNotFound
+		}
+		return nil, err
+	}
+	return &track, nil
+}
@@ -1,16 +1,74 @@
 package rooms
 
 import (
+	"errors"
+
 	"github.com/serozhenka/shary/internal/models"
 )
 
+var (
+	ErrRoomNotFound        = errors.New("room not found")
+	ErrPermissionDenied    = errors.New("permission denied")
+	ErrParticipantNotFound = errors.New("participant not found")
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrOwnerCannotLeave    = errors.New("room owner must transfer ownership before leaving")
+)
+
+// RoomSettings holds optional room setting changes; nil fields are left untouched
+type RoomSettings struct {
+	LobbyEnabled      *bool
+	MaxParticipants   *int
+	OverflowAsViewers *bool
+	SFUEnabled        *bool
+	AllowedCodecs     *string
+	MaxVideoBitrate   *int
+	SimulcastEnabled  *bool
+	Mode              *models.RoomMode
+}
+
+// apply copies the provided settings onto the room
+func (s RoomSettings) apply(room *models.Room) {
+	if s.LobbyEnabled != nil {
+		room.LobbyEnabled = *s.LobbyEnabled
+	}
+	if s.MaxParticipants != nil {
+		room.MaxParticipants = *s.MaxParticipants
+	}
+	if s.OverflowAsViewers != nil {
+		room.OverflowAsViewers = *s.OverflowAsViewers
+	}
+	if s.SFUEnabled != nil {
+		room.SFUEnabled = *s.SFUEnabled
+	}
+	if s.AllowedCodecs != nil {
+		room.AllowedCodecs = *s.AllowedCodecs
+	}
+	if s.MaxVideoBitrate != nil {
+		room.MaxVideoBitrate = *s.MaxVideoBitrate
+	}
+	if s.SimulcastEnabled != nil {
+		room.SimulcastEnabled = *s.SimulcastEnabled
+	}
+	if s.Mode != nil {
+		room.Mode = *s.Mode
+	}
+}
+
 type Repository interface {
 	GetRoom(userID uint, id uint) (*models.Room, error)
 	CreateRoom(userID uint, name string) (*models.Room, error)
 	UpdateRoom(userID uint, id uint, name string) (*models.Room, error)
+	UpdateRoomSettings(userID uint, id uint, settings RoomSettings) (*models.Room, error)
 	DeleteRoom(userID uint, id uint) error
 	ListRooms(userID uint) ([]*models.Room, error)
 	AddUserToRoom(ownerID uint, roomID uint, email string) error
+	RemoveUserFromRoom(requesterID uint, roomID uint, userID uint) error
+	LeaveRoom(userID uint, roomID uint) error
+
+	// Roles and ownership
+	GetParticipant(roomID uint, userID uint) (*models.Participant, error)
+	UpdateParticipantRole(requesterID uint, roomID uint, userID uint, role models.Role) (*models.Participant, error)
+	TransferOwnership(ownerID uint, roomID uint, newOwnerID uint) (*models.Room, error)
 
 	// Backward compatibility methods for string IDs
 	GetRoomByStringID(userID uint, id string) (*models.Room, error)
@@ -18,3 +76,27 @@ type Repository interface {
 	DeleteRoomByStringID(userID uint, id string) error
 	AddUserToRoomByStringID(ownerID uint, id string, email string) error
 }
+
+// canAssignRole checks whether a requester with the given role may move a
+// participant from its current role to the new one
+func canAssignRole(requester models.Role, current models.Role, role models.Role) error {
+	if !role.IsValid() || role == models.RoleOwner {
+		return ErrInvalidRole
+	}
+	if !requester.Can(models.PermissionManageRoles) {
+		return ErrPermissionDenied
+	}
+	if !requester.Outranks(current) || !requester.Outranks(role) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// canRemoveParticipant checks whether a requester with the given role may
+// remove a participant holding the target role
+func canRemoveParticipant(requester models.Role, target models.Role) error {
+	if !requester.Can(models.PermissionRemoveParticipant) || !requester.Outranks(target) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
@@ -9,21 +9,27 @@ import (
 
 	"github.com/segmentio/ksuid"
 	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/repository/users"
+	"github.com/serozhenka/shary/internal/utils"
 )
 
 type inMemoryRepository struct {
 	rooms            map[string]*models.Room
 	roomIDToStringID map[uint]string // Map numeric ID to string ID
 	nextRoomID       uint
+	nextID           uint // Next participant ID
+	usersRepo        users.Repository
 	mutex            sync.RWMutex
 }
 
 // NewInMemoryRepository creates a new in-memory rooms repository
-func NewInMemoryRepository() Repository {
+func NewInMemoryRepository(usersRepo users.Repository) Repository {
 	return &inMemoryRepository{
 		rooms:            make(map[string]*models.Room),
 		roomIDToStringID: make(map[uint]string),
 		nextRoomID:       1,
+		nextID:           1,
+		usersRepo:        usersRepo,
 	}
 }
 
@@ -37,28 +43,14 @@ func (rm *inMemoryRepository) GetRoomByStringID(userID uint, id string) (*models
 	rm.mutex.RLock()
 	defer rm.mutex.RUnlock()
 
-	// Try to find room by string ID directly
-	room, exists := rm.rooms[id]
+	room, _, exists := rm.findRoom(id)
 	if !exists {
-		// Try to find by numeric ID converted to string
-		numericID, err := strconv.ParseUint(id, 10, 32)
-		if err == nil {
-			stringID, hasMapping := rm.roomIDToStringID[uint(numericID)]
-			if hasMapping {
-				room, exists = rm.rooms[stringID]
-			}
-		}
-	}
-
-	if !exists {
-		return nil, errors.New("room not found")
+		return nil, ErrRoomNotFound
 	}
 
 	// Check if user has access to this room (owner or participant)
-	if room.OwnerID != userID {
-		// For simplicity in tests, allow access if user is owner
-		// In a real implementation, we'd check participants table
-		return nil, errors.New("room not found")
+	if room.OwnerID != userID && rm.findParticipant(room, userID) == nil {
+		return nil, ErrRoomNotFound
 	}
 
 	// Set computed fields
@@ -81,6 +73,15 @@ func (rm *inMemoryRepository) CreateRoom(userID uint, name string) (*models.Room
 		Name:      name,
 		CreatedAt: time.Now(),
 		IsOwner:   true,
+
+		Mode:             models.RoomModeMeeting,
+		SimulcastEnabled: true,
+	}
+
+	// Add owner as participant
+	room.Participants = append(room.Participants, rm.newParticipant(room.ID, userID, models.RoleOwner))
+	if owner, err := rm.usersRepo.GetUserByID(userID); err == nil {
+		room.Owner = *owner
 	}
 
 	rm.rooms[stringID] = room
@@ -89,77 +90,66 @@ func (rm *inMemoryRepository) CreateRoom(userID uint, name string) (*models.Room
 	return room, nil
 }
 
-// UpdateRoom updates a room's name with ownership check
+// UpdateRoom updates a room's name with permission check
 func (rm *inMemoryRepository) UpdateRoom(userID uint, id uint, name string) (*models.Room, error) {
 	return rm.UpdateRoomByStringID(userID, fmt.Sprintf("%d", id), name)
 }
 
-// UpdateRoomByStringID updates a room's name by string ID with ownership check
+// UpdateRoomByStringID updates a room's name by string ID with permission check
 func (rm *inMemoryRepository) UpdateRoomByStringID(userID uint, id string, name string) (*models.Room, error) {
 	rm.mutex.Lock()
 	defer rm.mutex.Unlock()
 
-	// Try to find room by string ID directly
-	room, exists := rm.rooms[id]
+	room, _, exists := rm.findRoom(id)
 	if !exists {
-		// Try to find by numeric ID converted to string
-		numericID, err := strconv.ParseUint(id, 10, 32)
-		if err == nil {
-			stringID, hasMapping := rm.roomIDToStringID[uint(numericID)]
-			if hasMapping {
-				room, exists = rm.rooms[stringID]
-			}
-		}
+		return nil, ErrRoomNotFound
+	}
+
+	if _, err := rm.authorize(room, userID, models.PermissionUpdateRoom); err != nil {
+		return nil, err
 	}
 
+	room.Name = name
+	room.IsOwner = room.OwnerID == userID
+	return room, nil
+}
+
+// UpdateRoomSettings updates a room's settings with permission check
+func (rm *inMemoryRepository) UpdateRoomSettings(userID uint, id uint, settings RoomSettings) (*models.Room, error) {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	room, _, exists := rm.findRoom(fmt.Sprintf("%d", id))
 	if !exists {
-		return nil, errors.New("room not found")
+		return nil, ErrRoomNotFound
 	}
 
-	// Check ownership
-	if room.OwnerID != userID {
-		return nil, errors.New("permission denied")
+	if _, err := rm.authorize(room, userID, models.PermissionUpdateRoom); err != nil {
+		return nil, err
 	}
 
-	room.Name = name
-	room.IsOwner = true
+	settings.apply(room)
+	room.IsOwner = room.OwnerID == userID
 	return room, nil
 }
 
-// DeleteRoom removes a room with ownership check
+// DeleteRoom removes a room with permission check
 func (rm *inMemoryRepository) DeleteRoom(userID uint, id uint) error {
 	return rm.DeleteRoomByStringID(userID, fmt.Sprintf("%d", id))
 }
 
-// DeleteRoomByStringID removes a room by string ID with ownership check
+// DeleteRoomByStringID removes a room by string ID with permission check
 func (rm *inMemoryRepository) DeleteRoomByStringID(userID uint, id string) error {
 	rm.mutex.Lock()
 	defer rm.mutex.Unlock()
 
-	// Try to find room by string ID directly
-	var actualStringID string
-	room, exists := rm.rooms[id]
-	if exists {
-		actualStringID = id
-	} else {
-		// Try to find by numeric ID converted to string
-		numericID, err := strconv.ParseUint(id, 10, 32)
-		if err == nil {
-			stringID, hasMapping := rm.roomIDToStringID[uint(numericID)]
-			if hasMapping {
-				room, exists = rm.rooms[stringID]
-				actualStringID = stringID
-			}
-		}
-	}
-
+	room, actualStringID, exists := rm.findRoom(id)
 	if !exists {
-		return errors.New("room not found")
+		return ErrRoomNotFound
 	}
 
-	// Check ownership
-	if room.OwnerID != userID {
-		return errors.New("permission denied")
+	if _, err := rm.authorize(room, userID, models.PermissionDeleteRoom); err != nil {
+		return err
 	}
 
 	delete(rm.rooms, actualStringID)
@@ -167,15 +157,15 @@ func (rm *inMemoryRepository) DeleteRoomByStringID(userID uint, id string) error
 	return nil
 }
 
-// ListRooms returns all rooms owned by the user
+// ListRooms returns all rooms where the user is a participant
 func (rm *inMemoryRepository) ListRooms(userID uint) ([]*models.Room, error) {
 	rm.mutex.RLock()
 	defer rm.mutex.RUnlock()
 
 	rooms := make([]*models.Room, 0)
 	for _, room := range rm.rooms {
-		if room.OwnerID == userID {
-			room.IsOwner = true
+		if rm.findParticipant(room, userID) != nil {
+			room.IsOwner = room.OwnerID == userID
 			rooms = append(rooms, room)
 		}
 	}
@@ -193,33 +183,235 @@ func (rm *inMemoryRepository) AddUserToRoomByStringID(requesterID uint, id strin
 	rm.mutex.Lock()
 	defer rm.mutex.Unlock()
 
-	// Try to find room by string ID directly
-	room, exists := rm.rooms[id]
+	room, _, exists := rm.findRoom(id)
 	if !exists {
-		// Try to find by numeric ID converted to string
-		numericID, err := strconv.ParseUint(id, 10, 32)
-		if err == nil {
-			stringID, hasMapping := rm.roomIDToStringID[uint(numericID)]
-			if hasMapping {
-				room, exists = rm.rooms[stringID]
-			}
-		}
+		return ErrRoomNotFound
 	}
 
+	if _, err := rm.authorize(room, requesterID, models.PermissionAddParticipant); err != nil {
+		return err
+	}
+
+	// User doesn't exist, but don't raise error as per requirements
+	user, err := rm.usersRepo.GetUserByEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	// User is already a participant
+	if rm.findParticipant(room, user.ID) != nil {
+		return nil
+	}
+
+	room.Participants = append(room.Participants, rm.newParticipant(room.ID, user.ID, models.RoleMember))
+	return nil
+}
+
+// RemoveUserFromRoom removes a participant from a room (only if requester outranks them)
+func (rm *inMemoryRepository) RemoveUserFromRoom(requesterID uint, roomID uint, userID uint) error {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	room, _, exists := rm.findRoom(fmt.Sprintf("%d", roomID))
 	if !exists {
-		return errors.New("room not found")
+		return ErrRoomNotFound
+	}
+
+	requester, err := rm.authorize(room, requesterID, models.PermissionRemoveParticipant)
+	if err != nil {
+		return err
+	}
+
+	participant := rm.findParticipant(room, userID)
+	if participant == nil {
+		return ErrParticipantNotFound
 	}
 
-	// Only room owners can add users to rooms
-	if room.OwnerID != requesterID {
-		return errors.New("only room owners can add users")
+	if err := canRemoveParticipant(requester.Role, participant.Role); err != nil {
+		return err
 	}
 
-	// In a real implementation, we'd add the user to participants table
-	// For now, just return success since we don't have user lookup
+	rm.removeParticipant(room, userID)
 	return nil
 }
 
+// LeaveRoom removes the user's own participation in a room
+func (rm *inMemoryRepository) LeaveRoom(userID uint, roomID uint) error {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	room, _, exists := rm.findRoom(fmt.Sprintf("%d", roomID))
+	if !exists {
+		return ErrRoomNotFound
+	}
+
+	participant := rm.findParticipant(room, userID)
+	if participant == nil {
+		return ErrRoomNotFound
+	}
+
+	if participant.Role == models.RoleOwner {
+		return ErrOwnerCannotLeave
+	}
+
+	rm.removeParticipant(room, userID)
+	return nil
+}
+
+// GetParticipant retrieves a user's participation in a room
+func (rm *inMemoryRepository) GetParticipant(roomID uint, userID uint) (*models.Participant, error) {
+	rm.mutex.RLock()
+	defer rm.mutex.RUnlock()
+
+	room, _, exists := rm.findRoom(fmt.Sprintf("%d", roomID))
+	if !exists {
+		return nil, ErrRoomNotFound
+	}
+
+	participant := rm.findParticipant(room, userID)
+	if participant == nil {
+		return nil, ErrParticipantNotFound
+	}
+
+	participantCopy := *participant
+	return &participantCopy, nil
+}
+
+// UpdateParticipantRole changes the role of a room participant
+func (rm *inMemoryRepository) UpdateParticipantRole(requesterID uint, roomID uint, userID uint, role models.Role) (*models.Participant, error) {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	room, _, exists := rm.findRoom(fmt.Sprintf("%d", roomID))
+	if !exists {
+		return nil, ErrRoomNotFound
+	}
+
+	requester, err := rm.authorize(room, requesterID, models.PermissionManageRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	participant := rm.findParticipant(room, userID)
+	if participant == nil {
+		return nil, ErrParticipantNotFound
+	}
+
+	if err := canAssignRole(requester.Role, participant.Role, role); err != nil {
+		return nil, err
+	}
+
+	participant.Role = role
+	participantCopy := *participant
+	return &participantCopy, nil
+}
+
+// TransferOwnership hands the room over to another participant, demoting the
+// previous owner to moderator
+func (rm *inMemoryRepository) TransferOwnership(ownerID uint, roomID uint, newOwnerID uint) (*models.Room, error) {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	room, _, exists := rm.findRoom(fmt.Sprintf("%d", roomID))
+	if !exists {
+		return nil, ErrRoomNotFound
+	}
+
+	owner, err := rm.authorize(room, ownerID, models.PermissionTransferOwnership)
+	if err != nil {
+		return nil, err
+	}
+
+	if ownerID == newOwnerID {
+		return nil, ErrInvalidRole
+	}
+
+	newOwner := rm.findParticipant(room, newOwnerID)
+	if newOwner == nil {
+		return nil, ErrParticipantNotFound
+	}
+
+	room.OwnerID = newOwnerID
+	room.Owner = newOwner.User
+	newOwner.Role = models.RoleOwner
+	owner.Role = models.RoleModerator
+	room.IsOwner = false
+
+	return room, nil
+}
+
+// findRoom looks a room up by its string ID or by its numeric ID
+func (rm *inMemoryRepository) findRoom(id string) (*models.Room, string, bool) {
+	// Try to find room by string ID directly
+	if room, exists := rm.rooms[id]; exists {
+		return room, id, true
+	}
+
+	// Try to find by numeric ID converted to string
+	numericID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, "", false
+	}
+
+	stringID, hasMapping := rm.roomIDToStringID[uint(numericID)]
+	if !hasMapping {
+		return nil, "", false
+	}
+
+	room, exists := rm.rooms[stringID]
+	return room, stringID, exists
+}
+
+// findParticipant returns the user's participant record within the room, if any
+func (rm *inMemoryRepository) findParticipant(room *models.Room, userID uint) *models.Participant {
+	for i := range room.Participants {
+		if room.Participants[i].UserID == userID {
+			return &room.Participants[i]
+		}
+	}
+	return nil
+}
+
+// removeParticipant drops the user's participant record from the room
+func (rm *inMemoryRepository) removeParticipant(room *models.Room, userID uint) {
+	room.Participants = utils.Filter(room.Participants, func(participant models.Participant) bool {
+		return participant.UserID != userID
+	})
+}
+
+// authorize checks that the user participates in the room with a role
+// granting the given permission
+func (rm *inMemoryRepository) authorize(room *models.Room, userID uint, permission models.Permission) (*models.Participant, error) {
+	participant := rm.findParticipant(room, userID)
+	if participant == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	if !participant.Role.Can(permission) {
+		return nil, ErrPermissionDenied
+	}
+
+	return participant, nil
+}
+
+// newParticipant builds a participant record, resolving the user when possible
+func (rm *inMemoryRepository) newParticipant(roomID uint, userID uint, role models.Role) models.Participant {
+	participant := models.Participant{
+		ID:       rm.nextID,
+		UserID:   userID,
+		RoomID:   roomID,
+		Role:     role,
+		JoinedAt: time.Now(),
+	}
+	rm.nextID++
+
+	if user, err := rm.usersRepo.GetUserByID(userID); err == nil {
+		participant.User = *user
+	}
+
+	return participant
+}
+
 // Legacy methods for backward compatibility with old string-based interface
 
 // LegacyGetRoom retrieves a room by string ID (old interface)
@@ -247,6 +439,9 @@ func (rm *inMemoryRepository) LegacyCreateRoom(name string) *models.Room {
 		ID:        numericID,
 		Name:      name,
 		CreatedAt: time.Now(),
+
+		Mode:             models.RoomModeMeeting,
+		SimulcastEnabled: true,
 	}
 	rm.rooms[stringID] = room
 	rm.roomIDToStringID[numericID] = stringID
@@ -31,7 +31,7 @@ func (r *postgresRepository) GetRoom(userID uint, id uint) (*models.Room, error)
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("room not found")
+			return nil, ErrRoomNotFound
 		}
 		return nil, err
 	}
@@ -47,6 +47,9 @@ func (r *postgresRepository) CreateRoom(userID uint, name string) (*models.Room,
 	room := &models.Room{
 		OwnerID: userID,
 		Name:    name,
+
+		Mode:             models.RoomModeMeeting,
+		SimulcastEnabled: true,
 	}
 
 	// Start transaction
@@ -62,6 +65,7 @@ func (r *postgresRepository) CreateRoom(userID uint, name string) (*models.Room,
 	participant := &models.Participant{
 		UserID: userID,
 		RoomID: room.ID,
+		Role:   models.RoleOwner,
 	}
 
 	if err := tx.Create(participant).Error; err != nil {
@@ -78,16 +82,14 @@ func (r *postgresRepository) CreateRoom(userID uint, name string) (*models.Room,
 	return room, nil
 }
 
-// UpdateRoom updates a room's name (only if user is owner)
+// UpdateRoom updates a room's name (only if user may update the room)
 func (r *postgresRepository) UpdateRoom(userID uint, id uint, name string) (*models.Room, error) {
-	var room models.Room
+	if _, err := r.authorize(id, userID, models.PermissionUpdateRoom); err != nil {
+		return nil, err
+	}
 
-	// Check if user is owner
-	err := r.db.Where("id = ? AND owner_id = ?", id, userID).First(&room).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("room not found or you don't have permission")
-		}
+	var room models.Room
+	if err := r.db.First(&room, id).Error; err != nil {
 		return nil, err
 	}
 
@@ -99,21 +101,42 @@ func (r *postgresRepository) UpdateRoom(userID uint, id uint, name string) (*mod
 
 	// Load relationships
 	r.db.Preload("Owner").Preload("Participants.User").First(&room, room.ID)
-	room.IsOwner = true
+	room.IsOwner = room.OwnerID == userID
 
 	return &room, nil
 }
 
-// DeleteRoom removes a room (only if user is owner)
-func (r *postgresRepository) DeleteRoom(userID uint, id uint) error {
+// UpdateRoomSettings updates a room's settings (only if user may update the room)
+func (r *postgresRepository) UpdateRoomSettings(userID uint, id uint, settings RoomSettings) (*models.Room, error) {
+	if _, err := r.authorize(id, userID, models.PermissionUpdateRoom); err != nil {
+		return nil, err
+	}
+
 	var room models.Room
+	if err := r.db.First(&room, id).Error; err != nil {
+		return nil, err
+	}
 
-	// Check if user is owner
-	err := r.db.Where("id = ? AND owner_id = ?", id, userID).First(&room).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("room not found or you don't have permission")
-		}
+	settings.apply(&room)
+	if err := r.db.Save(&room).Error; err != nil {
+		return nil, err
+	}
+
+	// Load relationships
+	r.db.Preload("Owner").Preload("Participants.User").First(&room, room.ID)
+	room.IsOwner = room.OwnerID == userID
+
+	return &room, nil
+}
+
+// DeleteRoom removes a room (only if user may delete the room)
+func (r *postgresRepository) DeleteRoom(userID uint, id uint) error {
+	if _, err := r.authorize(id, userID, models.PermissionDeleteRoom); err != nil {
+		return err
+	}
+
+	var room models.Room
+	if err := r.db.First(&room, id).Error; err != nil {
 		return err
 	}
 
@@ -158,21 +181,15 @@ func (r *postgresRepository) ListRooms(userID uint) ([]*models.Room, error) {
 	return rooms, nil
 }
 
-// AddUserToRoom adds a user to a room by email (only if requester is owner)
+// AddUserToRoom adds a user to a room by email (only if requester may add participants)
 func (r *postgresRepository) AddUserToRoom(ownerID uint, roomID uint, email string) error {
-	// Check if requester is owner of the room
-	var room models.Room
-	err := r.db.Where("id = ? AND owner_id = ?", roomID, ownerID).First(&room).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("room not found or you don't have permission")
-		}
+	if _, err := r.authorize(roomID, ownerID, models.PermissionAddParticipant); err != nil {
 		return err
 	}
 
 	// Find user by email
 	var user models.User
-	err = r.db.Where("email = ?", email).First(&user).Error
+	err := r.db.Where("email = ?", email).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// User doesn't exist, but don't raise error as per requirements
@@ -196,11 +213,151 @@ func (r *postgresRepository) AddUserToRoom(ownerID uint, roomID uint, email stri
 	participant := &models.Participant{
 		UserID: user.ID,
 		RoomID: roomID,
+		Role:   models.RoleMember,
 	}
 
 	return r.db.Create(participant).Error
 }
 
+// RemoveUserFromRoom removes a participant from a room (only if requester outranks them)
+func (r *postgresRepository) RemoveUserFromRoom(requesterID uint, roomID uint, userID uint) error {
+	requester, err := r.authorize(roomID, requesterID, models.PermissionRemoveParticipant)
+	if err != nil {
+		return err
+	}
+
+	participant, err := r.GetParticipant(roomID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := canRemoveParticipant(requester.Role, participant.Role); err != nil {
+		return err
+	}
+
+	return r.db.Delete(participant).Error
+}
+
+// LeaveRoom removes the user's own participation in a room
+func (r *postgresRepository) LeaveRoom(userID uint, roomID uint) error {
+	participant, err := r.GetParticipant(roomID, userID)
+	if err != nil {
+		if errors.Is(err, ErrParticipantNotFound) {
+			return ErrRoomNotFound
+		}
+		return err
+	}
+
+	if participant.Role == models.RoleOwner {
+		return ErrOwnerCannotLeave
+	}
+
+	return r.db.Delete(participant).Error
+}
+
+// GetParticipant retrieves a user's participation in a room
+func (r *postgresRepository) GetParticipant(roomID uint, userID uint) (*models.Participant, error) {
+	var participant models.Participant
+
+	err := r.db.Preload("User").
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		First(&participant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrParticipantNotFound
+		}
+		return nil, err
+	}
+
+	return &participant, nil
+}
+
+// UpdateParticipantRole changes the role of a room participant
+func (r *postgresRepository) UpdateParticipantRole(requesterID uint, roomID uint, userID uint, role models.Role) (*models.Participant, error) {
+	requester, err := r.authorize(roomID, requesterID, models.PermissionManageRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := r.GetParticipant(roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := canAssignRole(requester.Role, participant.Role, role); err != nil {
+		return nil, err
+	}
+
+	participant.Role = role
+	if err := r.db.Model(participant).Update("role", role).Error; err != nil {
+		return nil, err
+	}
+
+	return participant, nil
+}
+
+// TransferOwnership hands the room over to another participant, demoting the
+// previous owner to moderator
+func (r *postgresRepository) TransferOwnership(ownerID uint, roomID uint, newOwnerID uint) (*models.Room, error) {
+	if _, err := r.authorize(roomID, ownerID, models.PermissionTransferOwnership); err != nil {
+		return nil, err
+	}
+
+	if ownerID == newOwnerID {
+		return nil, ErrInvalidRole
+	}
+
+	if _, err := r.GetParticipant(roomID, newOwnerID); err != nil {
+		return nil, err
+	}
+
+	// Start transaction to ensure atomicity
+	tx := r.db.Begin()
+
+	if err := tx.Model(&models.Room{}).Where("id = ?", roomID).Update("owner_id", newOwnerID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Model(&models.Participant{}).
+		Where("room_id = ? AND user_id = ?", roomID, newOwnerID).
+		Update("role", models.RoleOwner).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Model(&models.Participant{}).
+		Where("room_id = ? AND user_id = ?", roomID, ownerID).
+		Update("role", models.RoleModerator).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return r.GetRoom(ownerID, roomID)
+}
+
+// authorize checks that the user participates in the room with a role
+// granting the given permission
+func (r *postgresRepository) authorize(roomID uint, userID uint, permission models.Permission) (*models.Participant, error) {
+	participant, err := r.GetParticipant(roomID, userID)
+	if err != nil {
+		if errors.Is(err, ErrParticipantNotFound) {
+			return nil, ErrRoomNotFound
+		}
+		return nil, err
+	}
+
+	if !participant.Role.Can(permission) {
+		return nil, ErrPermissionDenied
+	}
+
+	return participant, nil
+}
+
 // GetRoomByStringID is a helper method for backward compatibility with string IDs
 func (r *postgresRepository) GetRoomByStringID(userID uint, id string) (*models.Room, error) {
 	// Try to parse string ID as uint
@@ -0,0 +1,33 @@
+package sessions
+
+import (
+	"errors"
+	"time"
+
+	"github.com/serozhenka/shary/internal/models"
+)
+
+var (
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrWhiteboardNotFound = errors.New("whiteboard not found")
+)
+
+// Repository defines the interface for meeting session operations
+type Repository interface {
+	CreateSession(session models.MeetingSession) (*models.MeetingSession, error)
+	GetSession(id uint) (*models.MeetingSession, error)
+	// ListSessions returns the room's sessions, newest first
+	ListSessions(roomID uint) ([]*models.MeetingSession, error)
+	EndSession(id uint, endedAt time.Time) (*models.MeetingSession, error)
+
+	AddQualitySummaries(summaries []models.QualitySummary) error
+	ListQualitySummaries(sessionID uint) ([]models.QualitySummary, error)
+
+	AddTranscriptSegments(segments []models.TranscriptSegment) error
+	// ListTranscriptSegments returns the session's transcript in the order it was spoken
+	ListTranscriptSegments(sessionID uint) ([]models.TranscriptSegment, error)
+
+	AddWhiteboards(whiteboards []models.Whiteboard) error
+	GetWhiteboard(id uint) (*models.Whiteboard, error)
+	ListWhiteboards(sessionID uint) ([]models.Whiteboard, error)
+}
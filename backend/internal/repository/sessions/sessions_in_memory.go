@@ -0,0 +1,213 @@
+package sessions
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/serozhenka/shary/internal/models"
+)
+
+type inMemoryRepository struct {
+	sessions      []*models.MeetingSession
+	summaries     []models.QualitySummary
+	segments      []models.TranscriptSegment
+	whiteboards   []models.Whiteboard
+	nextID        uint
+	nextSummaryID uint
+	nextSegmentID uint
+	nextBoardID   uint
+	mutex         sync.RWMutex
+}
+
+// NewInMemoryRepository creates a new in-memory sessions repository
+func NewInMemoryRepository() Repository {
+	return &inMemoryRepository{
+		sessions:      make([]*models.MeetingSession, 0),
+		summaries:     make([]models.QualitySummary, 0),
+		segments:      make([]models.TranscriptSegment, 0),
+		whiteboards:   make([]models.Whiteboard, 0),
+		nextID:        1,
+		nextSummaryID: 1,
+		nextSegmentID: 1,
+		nextBoardID:   1,
+	}
+}
+
+func (r *inMemoryRepository) CreateSession(session models.MeetingSession) (*models.MeetingSession, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session.ID = r.nextID
+	r.nextID++
+	if session.StartedAt.IsZero() {
+		session.StartedAt = time.Now()
+	}
+
+	r.sessions = append(r.sessions, &session)
+	sessionCopy := session
+	return &sessionCopy, nil
+}
+
+func (r *inMemoryRepository) GetSession(id uint) (*models.MeetingSession, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	session := r.findSession(id)
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+	sessionCopy := *session
+	return &sessionCopy, nil
+}
+
+func (r *inMemoryRepository) ListSessions(roomID uint) ([]*models.MeetingSession, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	sessions := make([]*models.MeetingSession, 0)
+	for i := len(r.sessions) - 1; i >= 0; i-- {
+		if r.sessions[i].RoomID == roomID {
+			sessionCopy := *r.sessions[i]
+			sessions = append(sessions, &sessionCopy)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *inMemoryRepository) EndSession(id uint, endedAt time.Time) (*models.MeetingSession, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session := r.findSession(id)
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+
+	session.EndedAt = &endedAt
+	sessionCopy := *session
+	return &sessionCopy, nil
+}
+
+func (r *inMemoryRepository) AddQualitySummaries(summaries []models.QualitySummary) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, summary := range summaries {
+		if r.findSession(summary.SessionID) == nil {
+			return ErrSessionNotFound
+		}
+	}
+
+	for _, summary := range summaries {
+		summary.ID = r.nextSummaryID
+		r.nextSummaryID++
+		if summary.CreatedAt.IsZero() {
+			summary.CreatedAt = time.Now()
+		}
+		r.summaries = append(r.summaries, summary)
+	}
+	return nil
+}
+
+func (r *inMemoryRepository) ListQualitySummaries(sessionID uint) ([]models.QualitySummary, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	summaries := make([]models.QualitySummary, 0)
+	for _, summary := range r.summaries {
+		if summary.SessionID == sessionID {
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries, nil
+}
+
+func (r *inMemoryRepository) AddTranscriptSegments(segments []models.TranscriptSegment) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, segment := range segments {
+		if r.findSession(segment.SessionID) == nil {
+			return ErrSessionNotFound
+		}
+	}
+
+	for _, segment := range segments {
+		segment.ID = r.nextSegmentID
+		r.nextSegmentID++
+		r.segments = append(r.segments, segment)
+	}
+	return nil
+}
+
+func (r *inMemoryRepository) ListTranscriptSegments(sessionID uint) ([]models.TranscriptSegment, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	segments := make([]models.TranscriptSegment, 0)
+	for _, segment := range r.segments {
+		if segment.SessionID == sessionID {
+			segments = append(segments, segment)
+		}
+	}
+	slices.SortStableFunc(segments, func(a, b models.TranscriptSegment) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return segments, nil
+}
+
+func (r *inMemoryRepository) AddWhiteboards(whiteboards []models.Whiteboard) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, whiteboard := range whiteboards {
+		if r.findSession(whiteboard.SessionID) == nil {
+			return ErrSessionNotFound
+		}
+	}
+
+	for _, whiteboard := range whiteboards {
+		whiteboard.ID = r.nextBoardID
+		r.nextBoardID++
+		if whiteboard.CreatedAt.IsZero() {
+			whiteboard.CreatedAt = time.Now()
+		}
+		r.whiteboards = append(r.whiteboards, whiteboard)
+	}
+	return nil
+}
+
+func (r *inMemoryRepository) GetWhiteboard(id uint) (*models.Whiteboard, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, whiteboard := range r.whiteboards {
+		if whiteboard.ID == id {
+			return &whiteboard, nil
+		}
+	}
+	return nil, ErrWhiteboardNotFound
+}
+
+func (r *inMemoryRepository) ListWhiteboards(sessionID uint) ([]models.Whiteboard, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	whiteboards := make([]models.Whiteboard, 0)
+	for _, whiteboard := range r.whiteboards {
+		if whiteboard.SessionID == sessionID {
+			whiteboards = append(whiteboards, whiteboard)
+		}
+	}
+	return whiteboards, nil
+}
+
+func (r *inMemoryRepository) findSession(id uint) *models.MeetingSession {
+	for _, session := range r.sessions {
+		if session.ID == id {
+			return session
+		}
+	}
+	return nil
+}
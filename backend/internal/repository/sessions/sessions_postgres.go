@@ -0,0 +1,107 @@
+package sessions
+
+import (
+	"errors"
+	"time"
+
+	"github.com/serozhenka/shary/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL sessions repository
+func NewPostgresRepository(db *gorm.DB) Repository {
+	return &postgresRepository{
+		db: db,
+	}
+}
+
+func (r *postgresRepository) CreateSession(session models.MeetingSession) (*models.MeetingSession, error) {
+	if err := r.db.Omit(clause.Associations).Create(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *postgresRepository) GetSession(id uint) (*models.MeetingSession, error) {
+	var session models.MeetingSession
+	if err := r.db.First(&session, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *postgresRepository) ListSessions(roomID uint) ([]*models.MeetingSession, error) {
+	var sessions []*models.MeetingSession
+	err := r.db.Where("room_id = ?", roomID).Order("id DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *postgresRepository) EndSession(id uint, endedAt time.Time) (*models.MeetingSession, error) {
+	result := r.db.Model(&models.MeetingSession{}).Where("id = ?", id).Update("ended_at", endedAt)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrSessionNotFound
+	}
+	return r.GetSession(id)
+}
+
+func (r *postgresRepository) AddQualitySummaries(summaries []models.QualitySummary) error {
+	if len(summaries) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).Create(&summaries).Error
+}
+
+func (r *postgresRepository) ListQualitySummaries(sessionID uint) ([]models.QualitySummary, error) {
+	var summaries []models.QualitySummary
+	err := r.db.Where("session_id = ?", sessionID).Order("id").Find(&summaries).Error
+	return summaries, err
+}
+
+func (r *postgresRepository) AddTranscriptSegments(segments []models.TranscriptSegment) error {
+	if len(segments) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).Create(&segments).Error
+}
+
+func (r *postgresRepository) ListTranscriptSegments(sessionID uint) ([]models.TranscriptSegment, error) {
+	var segments []models.TranscriptSegment
+	err := r.db.Where("session_id = ?", sessionID).Order("started_at, id").Find(&segments).Error
+	return segments, err
+}
+
+func (r *postgresRepository) AddWhiteboards(whiteboards []models.Whiteboard) error {
+	if len(whiteboards) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).Create(&whiteboards).Error
+}
+
+func (r *postgresRepository) GetWhiteboard(id uint) (*models.Whiteboard, error) {
+	var whiteboard models.Whiteboard
+	err := r.db.First(&whiteboard, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWhiteboardNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &whiteboard, nil
+}
+
+func (r *postgresRepository) ListWhiteboards(sessionID uint) ([]models.Whiteboard, error) {
+	var whiteboards []models.Whiteboard
+	err := r.db.Where("session_id = ?", sessionID).Order("id").Find(&whiteboards).Error
+	return whiteboards, err
+}
@@ -0,0 +1,190 @@
+package sdp
+
+import (
+	"slices"
+	"strings"
+
+	pionsdp "github.com/pion/sdp/v3"
+)
+
+// auxiliaryCodecs don't carry media of their own, they're kept alongside
+// whichever codecs the policy allows
+var auxiliaryCodecs = []string{"red", "ulpfec", "flexfec-03", "telephone-event", "cn"}
+
+var (
+	audioCodecs = []string{"opus", "G722", "PCMU", "PCMA"}
+	videoCodecs = []string{"VP8", "VP9", "H264", "AV1"}
+)
+
+// KnownCodecs are the codecs a policy may allow
+var KnownCodecs = slices.Concat(audioCodecs, videoCodecs)
+
+// Policy restricts the media a room's clients may negotiate
+type Policy struct {
+	AllowedCodecs   []string // Codec names, kinds of media without a listed codec allow every codec
+	MaxVideoBitrate int      // In kbps, 0 leaves the bitrate to the browsers
+	Simulcast       bool     // Lets clients send several encodings of their video
+	AudioOnly       bool     // Rejects descriptions sending video
+	ReceiveOnly     bool     // Rejects descriptions sending any media
+}
+
+// Apply rewrites an offer or answer to comply with the policy, it fails
+// when the description can't be made to comply
+func (p Policy) Apply(raw string) (string, error) {
+	if p.permitsAll() {
+		return raw, nil
+	}
+
+	description, err := Parse(raw)
+	if err != nil {
+		return "", err
+	}
+
+	for _, media := range description.MediaDescriptions {
+		kind := media.MediaName.Media
+		if (kind != "audio" && kind != "video") || media.MediaName.Port.Value == 0 {
+			continue
+		}
+
+		if p.ReceiveOnly && Sends(media) {
+			return "", ErrSendNotAllowed
+		}
+		if kind == "video" && p.AudioOnly && Sends(media) {
+			return "", ErrVideoNotAllowed
+		}
+		if p.restricts(kind) {
+			if err := p.filterCodecs(media); err != nil {
+				return "", err
+			}
+		}
+		if kind == "video" && p.MaxVideoBitrate > 0 {
+			limitBitrate(media, p.MaxVideoBitrate)
+		}
+		if kind == "video" && !p.Simulcast {
+			stripSimulcast(media)
+		}
+	}
+
+	rewritten, err := description.Marshal()
+	if err != nil {
+		return "", err
+	}
+	return string(rewritten), nil
+}
+
+func (p Policy) permitsAll() bool {
+	return len(p.AllowedCodecs) == 0 && p.MaxVideoBitrate == 0 && p.Simulcast && !p.AudioOnly && !p.ReceiveOnly
+}
+
+// restricts reports whether the policy lists a codec of the kind of media,
+// so that e.g. allowing only VP8 leaves audio alone
+func (p Policy) restricts(kind string) bool {
+	known := audioCodecs
+	if kind == "video" {
+		known = videoCodecs
+	}
+
+	return slices.ContainsFunc(p.AllowedCodecs, func(name string) bool {
+		return slices.ContainsFunc(known, func(codec string) bool {
+			return strings.EqualFold(codec, name)
+		})
+	})
+}
+
+func (p Policy) allows(codec Codec) bool {
+	return slices.ContainsFunc(p.AllowedCodecs, func(name string) bool {
+		return strings.EqualFold(name, codec.Name)
+	})
+}
+
+// filterCodecs drops the payload types of codecs the policy doesn't allow,
+// along with the retransmission payload types pointing at them
+func (p Policy) filterCodecs(media *pionsdp.MediaDescription) error {
+	codecs := Codecs(media)
+	kept := make(map[string]bool)
+
+	for _, codec := range codecs {
+		if p.allows(codec) {
+			kept[codec.PayloadType] = true
+		}
+	}
+	if len(kept) == 0 {
+		return ErrCodecNotAllowed
+	}
+
+	for _, codec := range codecs {
+		if slices.Contains(auxiliaryCodecs, strings.ToLower(codec.Name)) {
+			kept[codec.PayloadType] = true
+		}
+	}
+	for _, codec := range codecs {
+		if strings.EqualFold(codec.Name, "rtx") && kept[fmtpParameter(media, codec.PayloadType, "apt")] {
+			kept[codec.PayloadType] = true
+		}
+	}
+
+	media.MediaName.Formats = slices.DeleteFunc(media.MediaName.Formats, func(payloadType string) bool {
+		return !kept[payloadType]
+	})
+	media.Attributes = slices.DeleteFunc(media.Attributes, func(attribute pionsdp.Attribute) bool {
+		payloadType, ok := attributePayloadType(attribute)
+		return ok && payloadType != "*" && !kept[payloadType]
+	})
+	return nil
+}
+
+// limitBitrate caps the section's bandwidth, keeping a lower limit set by
+// the browser. Firefox signals it in bps with b=TIAS, others in kbps with b=AS.
+func limitBitrate(media *pionsdp.MediaDescription, kbps int) {
+	limited := false
+	for i, bandwidth := range media.Bandwidth {
+		switch bandwidth.Type {
+		case "AS":
+			media.Bandwidth[i].Bandwidth = min(bandwidth.Bandwidth, uint64(kbps))
+			limited = true
+		case "TIAS":
+			media.Bandwidth[i].Bandwidth = min(bandwidth.Bandwidth, uint64(kbps)*1000)
+		}
+	}
+
+	if !limited {
+		media.Bandwidth = append(media.Bandwidth, pionsdp.Bandwidth{Type: "AS", Bandwidth: uint64(kbps)})
+	}
+}
+
+// stripSimulcast leaves only the first encoding of a simulcast section. RID
+// based simulcast loses its rids, SSRC groups their extra SSRCs along with
+// the retransmission SSRCs paired with them.
+func stripSimulcast(media *pionsdp.MediaDescription) {
+	dropped := make(map[string]bool)
+	for _, attribute := range media.Attributes {
+		if attribute.Key == "ssrc-group" && strings.HasPrefix(attribute.Value, "SIM ") {
+			for _, ssrc := range strings.Fields(attribute.Value)[2:] {
+				dropped[ssrc] = true
+			}
+		}
+	}
+	for _, attribute := range media.Attributes {
+		if attribute.Key == "ssrc-group" && strings.HasPrefix(attribute.Value, "FID ") {
+			ssrcs := strings.Fields(attribute.Value)
+			if len(ssrcs) == 3 && dropped[ssrcs[1]] {
+				dropped[ssrcs[2]] = true
+			}
+		}
+	}
+
+	media.Attributes = slices.DeleteFunc(media.Attributes, func(attribute pionsdp.Attribute) bool {
+		switch attribute.Key {
+		case "simulcast", "rid":
+			return true
+		case "ssrc":
+			ssrc, _, _ := strings.Cut(attribute.Value, " ")
+			return dropped[ssrc]
+		case "ssrc-group":
+			ssrcs := strings.Fields(attribute.Value)
+			return len(ssrcs) > 0 && ssrcs[0] == "SIM" || len(ssrcs) > 1 && dropped[ssrcs[1]]
+		default:
+			return false
+		}
+	})
+}
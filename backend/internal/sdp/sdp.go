@@ -0,0 +1,143 @@
+// Package sdp inspects the session descriptions exchanged by meeting clients
+// and enforces a room's media policy on them
+package sdp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	pionsdp "github.com/pion/sdp/v3"
+)
+
+var (
+	ErrInvalidSDP      = errors.New("session description can't be parsed")
+	ErrVideoNotAllowed = errors.New("video is not allowed in this room")
+	ErrCodecNotAllowed = errors.New("none of the offered codecs are allowed in this room")
+	ErrSendNotAllowed  = errors.New("sending media is not allowed")
+)
+
+// Codec is a payload type negotiated by a media section
+type Codec struct {
+	PayloadType string
+	Name        string // As in a=rtpmap, e.g. "opus" or "VP8"
+	ClockRate   string
+}
+
+// staticCodecs are the payload types browsers may use without a=rtpmap
+var staticCodecs = map[string]Codec{
+	"0":  {PayloadType: "0", Name: "PCMU", ClockRate: "8000"},
+	"8":  {PayloadType: "8", Name: "PCMA", ClockRate: "8000"},
+	"9":  {PayloadType: "9", Name: "G722", ClockRate: "8000"},
+	"13": {PayloadType: "13", Name: "CN", ClockRate: "8000"},
+}
+
+// Parse reads a session description as sent by a browser
+func Parse(raw string) (*pionsdp.SessionDescription, error) {
+	var description pionsdp.SessionDescription
+	if err := description.UnmarshalString(raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSDP, err)
+	}
+	return &description, nil
+}
+
+// Codecs lists the payload types of a media section in order of preference
+func Codecs(media *pionsdp.MediaDescription) []Codec {
+	rtpmaps := make(map[string]Codec)
+	for _, attribute := range media.Attributes {
+		if attribute.Key != "rtpmap" {
+			continue
+		}
+
+		payloadType, encoding, ok := strings.Cut(attribute.Value, " ")
+		if !ok {
+			continue
+		}
+		parts := strings.Split(encoding, "/")
+		codec := Codec{PayloadType: payloadType, Name: parts[0]}
+		if len(parts) > 1 {
+			codec.ClockRate = parts[1]
+		}
+		rtpmaps[payloadType] = codec
+	}
+
+	codecs := make([]Codec, 0, len(media.MediaName.Formats))
+	for _, payloadType := range media.MediaName.Formats {
+		if codec, ok := rtpmaps[payloadType]; ok {
+			codecs = append(codecs, codec)
+		} else if codec, ok := staticCodecs[payloadType]; ok {
+			codecs = append(codecs, codec)
+		}
+	}
+	return codecs
+}
+
+// Direction returns the media section's a=sendrecv, a=sendonly, a=recvonly
+// or a=inactive attribute, sections without one send and receive
+func Direction(media *pionsdp.MediaDescription) string {
+	for _, attribute := range media.Attributes {
+		switch attribute.Key {
+		case "sendrecv", "sendonly", "recvonly", "inactive":
+			return attribute.Key
+		}
+	}
+	return "sendrecv"
+}
+
+// Sends reports whether the description's author sends media in the section
+func Sends(media *pionsdp.MediaDescription) bool {
+	if media.MediaName.Port.Value == 0 {
+		return false
+	}
+
+	direction := Direction(media)
+	return direction == "sendrecv" || direction == "sendonly"
+}
+
+// Simulcast reports whether the section sends several encodings of a track,
+// either with RID based simulcast or with an SSRC group
+func Simulcast(media *pionsdp.MediaDescription) bool {
+	for _, attribute := range media.Attributes {
+		if attribute.Key == "simulcast" {
+			return true
+		}
+		if attribute.Key == "ssrc-group" && strings.HasPrefix(attribute.Value, "SIM ") {
+			return true
+		}
+	}
+	return false
+}
+
+// attributePayloadType returns the payload type an a=rtpmap, a=fmtp or
+// a=rtcp-fb attribute applies to
+func attributePayloadType(attribute pionsdp.Attribute) (string, bool) {
+	switch attribute.Key {
+	case "rtpmap", "fmtp", "rtcp-fb":
+		payloadType, _, _ := strings.Cut(attribute.Value, " ")
+		return payloadType, true
+	default:
+		return "", false
+	}
+}
+
+// fmtpParameter looks up a format parameter of a payload type, e.g. the
+// apt of a retransmission payload type
+func fmtpParameter(media *pionsdp.MediaDescription, payloadType string, name string) string {
+	for _, attribute := range media.Attributes {
+		if attribute.Key != "fmtp" {
+			continue
+		}
+
+		pt, parameters, _ := strings.Cut(attribute.Value, " ")
+		if pt != payloadType {
+			continue
+		}
+		for _, parameter := range strings.Split(parameters, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(parameter), "=")
+			if key == name {
+				return value
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/repository/attachments"
+	"github.com/serozhenka/shary/internal/repository/rooms"
+	"github.com/serozhenka/shary/internal/storage"
+)
+
+var (
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	ErrAttachmentType     = errors.New("attachment type is not allowed")
+	ErrAttachmentNotFound = errors.New("attachment not found")
+)
+
+type AttachmentLimits struct {
+	MaxSize      int64
+	AllowedTypes []string // MIME types, as sniffed from the file contents
+}
+
+type AttachmentService struct {
+	store           storage.BlobStore
+	attachmentsRepo attachments.Repository
+	roomsRepo       rooms.Repository
+	links           downloadSigner
+	limits          AttachmentLimits
+}
+
+func NewAttachmentService(
+	store storage.BlobStore,
+	attachmentsRepo attachments.Repository,
+	roomsRepo rooms.Repository,
+	secret string,
+	limits AttachmentLimits,
+) *AttachmentService {
+	return &AttachmentService{
+		store:           store,
+		attachmentsRepo: attachmentsRepo,
+		roomsRepo:       roomsRepo,
+		links:           downloadSigner{secret: []byte(secret), kind: "attachment"},
+		limits:          limits,
+	}
+}
+
+// MaxSize returns the largest accepted attachment in bytes
+func (s *AttachmentService) MaxSize() int64 {
+	return s.limits.MaxSize
+}
+
+// Upload stores a file posted by a room participant
+func (s *AttachmentService) Upload(ctx context.Context, userID uint, roomID uint, fileName string, size int64, body io.Reader) (*models.Attachment, error) {
+	if _, err := s.roomsRepo.GetParticipant(roomID, userID); err != nil {
+		return nil, rooms.ErrRoomNotFound
+	}
+
+	if size > s.limits.MaxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	// Don't trust the client's content type, sniff it from the first bytes
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	head = head[:n]
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil || !slices.Contains(s.limits.AllowedTypes, contentType) {
+		return nil, ErrAttachmentType
+	}
+
+	key := fmt.Sprintf("rooms/%d/%s", roomID, ksuid.New().String())
+	err = s.store.Put(ctx, key, io.MultiReader(bytes.NewReader(head), body), size, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.attachmentsRepo.CreateAttachment(models.Attachment{
+		RoomID:      roomID,
+		UploaderID:  userID,
+		FileName:    filepath.Base(fileName),
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+	})
+	if err != nil {
+		s.store.Delete(ctx, key)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// DownloadURL returns a short-lived link to the attachment for a room participant
+func (s *AttachmentService) DownloadURL(userID uint, roomID uint, attachmentID uint) (string, time.Time, error) {
+	if _, err := s.roomsRepo.GetParticipant(roomID, userID); err != nil {
+		return "", time.Time{}, rooms.ErrRoomNotFound
+	}
+
+	attachment, err := s.attachmentsRepo.GetAttachment(attachmentID)
+	if err != nil || attachment.RoomID != roomID {
+		return "", time.Time{}, ErrAttachmentNotFound
+	}
+
+	url, expiresAt := s.links.url("/attachments", attachment.ID, userID)
+	return url, expiresAt, nil
+}
+
+// Open checks a signed download link and opens the attachment, the link's
+// user must still be a participant of the attachment's room
+func (s *AttachmentService) Open(ctx context.Context, attachmentID uint, userID uint, expires int64, signature string) (*Download, error) {
+	if err := s.links.verify(attachmentID, userID, expires, signature); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.attachmentsRepo.GetAttachment(attachmentID)
+	if err != nil {
+		return nil, ErrAttachmentNotFound
+	}
+
+	if _, err := s.roomsRepo.GetParticipant(attachment.RoomID, userID); err != nil {
+		return nil, ErrInvalidDownloadLink
+	}
+
+	body, err := s.store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+
+	return &Download{
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		FileName:    attachment.FileName,
+		Body:        body,
+	}, nil
+}
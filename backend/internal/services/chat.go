@@ -0,0 +1,236 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/repository/attachments"
+	"github.com/serozhenka/shary/internal/repository/chat"
+	"github.com/serozhenka/shary/internal/repository/rooms"
+)
+
+const (
+	MaxChatMessageLength = 4000
+	maxEmojiLength       = 32
+)
+
+var (
+	ErrInvalidMessage   = errors.New("message must be between 1 and 4000 characters")
+	ErrInvalidEmoji     = errors.New("invalid emoji")
+	ErrMessageNotFound  = errors.New("message not found")
+	ErrMessageForbidden = errors.New("you can't change this message")
+	ErrInvalidRecipient = errors.New("recipient is not a participant of the room")
+)
+
+type ChatService struct {
+	chatRepo        chat.Repository
+	roomsRepo       rooms.Repository
+	attachmentsRepo attachments.Repository
+}
+
+// MessageDraft holds what a participant posts to the chat
+type MessageDraft struct {
+	Body         string
+	ReplyTo      *uint
+	RecipientID  *uint // Private messages are only visible to the author and the recipient
+	AttachmentID *uint // Messages with an attachment may have an empty body
+}
+
+func NewChatService(chatRepo chat.Repository, roomsRepo rooms.Repository, attachmentsRepo attachments.Repository) *ChatService {
+	return &ChatService{
+		chatRepo:        chatRepo,
+		roomsRepo:       roomsRepo,
+		attachmentsRepo: attachmentsRepo,
+	}
+}
+
+// PostMessage stores a new message written by a room participant
+func (s *ChatService) PostMessage(userID uint, roomID uint, draft MessageDraft) (*models.ChatMessage, error) {
+	if _, err := s.roomsRepo.GetParticipant(roomID, userID); err != nil {
+		return nil, rooms.ErrRoomNotFound
+	}
+
+	var attachment *models.Attachment
+	if draft.AttachmentID != nil {
+		// Only the uploader may share an attachment, and only in its room
+		var err error
+		attachment, err = s.attachmentsRepo.GetAttachment(*draft.AttachmentID)
+		if err != nil || attachment.RoomID != roomID || attachment.UploaderID != userID {
+			return nil, ErrAttachmentNotFound
+		}
+	}
+
+	body := strings.TrimSpace(draft.Body)
+	if attachment == nil || body != "" {
+		var err error
+		body, err = validateBody(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if draft.RecipientID != nil {
+		if *draft.RecipientID == userID {
+			return nil, ErrInvalidRecipient
+		}
+		if _, err := s.roomsRepo.GetParticipant(roomID, *draft.RecipientID); err != nil {
+			return nil, ErrInvalidRecipient
+		}
+	}
+
+	// Replies may only reference messages of the same room
+	if draft.ReplyTo != nil {
+		if _, err := s.getRoomMessage(userID, roomID, *draft.ReplyTo); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.chatRepo.CreateMessage(models.ChatMessage{
+		RoomID:       roomID,
+		AuthorID:     userID,
+		Body:         body,
+		ReplyToID:    draft.ReplyTo,
+		RecipientID:  draft.RecipientID,
+		AttachmentID: draft.AttachmentID,
+		Attachment:   attachment,
+	})
+}
+
+// History returns a page of the room's messages visible to the user, see
+// chat.Repository.ListMessages
+func (s *ChatService) History(userID uint, roomID uint, before uint, limit int) ([]*models.ChatMessage, error) {
+	return s.chatRepo.ListMessages(roomID, userID, before, limit)
+}
+
+// EditMessage changes the body of a message, only its author may do so and
+// only while allowed to send messages to the room
+func (s *ChatService) EditMessage(userID uint, roomID uint, messageID uint, body string) (*models.ChatMessage, error) {
+	participant, err := s.roomsRepo.GetParticipant(roomID, userID)
+	if err != nil {
+		return nil, rooms.ErrRoomNotFound
+	}
+
+	if !participant.Role.Can(models.PermissionSendData) {
+		return nil, rooms.ErrPermissionDenied
+	}
+
+	message, err := s.getRoomMessage(userID, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.AuthorID != userID {
+		return nil, ErrMessageForbidden
+	}
+
+	body, err = validateBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.chatRepo.UpdateMessageBody(messageID, body)
+}
+
+// DeleteMessage soft deletes a message, its author and moderators may do so
+func (s *ChatService) DeleteMessage(userID uint, roomID uint, messageID uint) (*models.ChatMessage, error) {
+	participant, err := s.roomsRepo.GetParticipant(roomID, userID)
+	if err != nil {
+		return nil, rooms.ErrRoomNotFound
+	}
+
+	message, err := s.getRoomMessage(userID, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.AuthorID != userID && !participant.Role.Can(models.PermissionModerate) {
+		return nil, ErrMessageForbidden
+	}
+
+	return s.chatRepo.SoftDeleteMessage(messageID)
+}
+
+// React adds or removes the user's emoji reaction to a message
+func (s *ChatService) React(userID uint, roomID uint, messageID uint, emoji string, remove bool) (*models.ChatMessage, error) {
+	if _, err := s.roomsRepo.GetParticipant(roomID, userID); err != nil {
+		return nil, rooms.ErrRoomNotFound
+	}
+
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" || len(emoji) > maxEmojiLength {
+		return nil, ErrInvalidEmoji
+	}
+
+	if _, err := s.getRoomMessage(userID, roomID, messageID); err != nil {
+		return nil, err
+	}
+
+	if remove {
+		err := s.chatRepo.RemoveReaction(messageID, userID, emoji)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		err := s.chatRepo.AddReaction(messageID, userID, emoji)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.chatRepo.GetMessage(messageID)
+}
+
+// MarkRead records that the user has read the room's chat up to the message
+func (s *ChatService) MarkRead(userID uint, roomID uint, messageID uint) (*models.ChatMessage, error) {
+	message, err := s.chatRepo.GetMessage(messageID)
+	if err != nil {
+		if errors.Is(err, chat.ErrMessageNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+
+	// Deleted messages may still be the last one the user scrolled past
+	if message.RoomID != roomID || !message.VisibleTo(userID) {
+		return nil, ErrMessageNotFound
+	}
+
+	if err := s.chatRepo.MarkRead(roomID, userID, messageID); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// UnreadCounts returns the number of unread messages of each room, see
+// chat.Repository.UnreadCounts
+func (s *ChatService) UnreadCounts(userID uint, roomIDs []uint) (map[uint]int, error) {
+	return s.chatRepo.UnreadCounts(userID, roomIDs)
+}
+
+// getRoomMessage loads a message that belongs to the room, is visible to the
+// user and wasn't deleted
+func (s *ChatService) getRoomMessage(userID uint, roomID uint, messageID uint) (*models.ChatMessage, error) {
+	message, err := s.chatRepo.GetMessage(messageID)
+	if err != nil {
+		if errors.Is(err, chat.ErrMessageNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+
+	if message.RoomID != roomID || !message.VisibleTo(userID) || message.IsDeleted() {
+		return nil, ErrMessageNotFound
+	}
+
+	return message, nil
+}
+
+func validateBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || utf8.RuneCountInString(body) > MaxChatMessageLength {
+		return "", ErrInvalidMessage
+	}
+	return body, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// DefaultTURNCredentialTTL is how long issued TURN credentials stay valid
+const DefaultTURNCredentialTTL = 12 * time.Hour
+
+// ICEServer is a STUN or TURN server as the browser's RTCIceServer expects it
+type ICEServer struct {
+	URLs       []string `json:"urls"`
+	Username   string   `json:"username,omitempty"`
+	Credential string   `json:"credential,omitempty"`
+}
+
+type ICEConfig struct {
+	STUNURLs []string
+	TURNURLs []string
+	// TURNSecret is shared with the TURN server, which recomputes the
+	// credentials instead of storing them
+	TURNSecret    string
+	CredentialTTL time.Duration
+}
+
+type ICEService struct {
+	config ICEConfig
+}
+
+func NewICEService(config ICEConfig) *ICEService {
+	if config.CredentialTTL <= 0 {
+		config.CredentialTTL = DefaultTURNCredentialTTL
+	}
+
+	return &ICEService{
+		config: config,
+	}
+}
+
+// Servers returns the ICE servers for a user's connection along with when
+// its TURN credentials expire. TURN servers are left out without a secret.
+func (s *ICEService) Servers(userID uint) ([]ICEServer, time.Time) {
+	servers := make([]ICEServer, 0, 2)
+	if len(s.config.STUNURLs) > 0 {
+		servers = append(servers, ICEServer{URLs: s.config.STUNURLs})
+	}
+
+	expiresAt := time.Now().Add(s.config.CredentialTTL)
+	if len(s.config.TURNURLs) > 0 && s.config.TURNSecret != "" {
+		username, credential := s.turnCredentials(userID, expiresAt)
+		servers = append(servers, ICEServer{
+			URLs:       s.config.TURNURLs,
+			Username:   username,
+			Credential: credential,
+		})
+	}
+
+	return servers, expiresAt
+}
+
+// turnCredentials follows the TURN REST API convention: the username is the
+// expiry timestamp and the user, the password is the base64 encoded
+// HMAC-SHA1 of the username keyed with the shared secret
+func (s *ICEService) turnCredentials(userID uint, expiresAt time.Time) (string, string) {
+	username := fmt.Sprintf("%d:%d", expiresAt.Unix(), userID)
+
+	mac := hmac.New(sha1.New, []byte(s.config.TURNSecret))
+	mac.Write([]byte(username))
+	return username, base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
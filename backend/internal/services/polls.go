@@ -0,0 +1,266 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/repository/polls"
+	"github.com/serozhenka/shary/internal/repository/rooms"
+)
+
+const (
+	maxPollQuestion = 300
+	maxPollOption   = 100
+	minPollOptions  = 2
+	maxPollOptions  = 10
+	MaxPollDuration = 24 * time.Hour
+)
+
+var (
+	ErrInvalidPoll  = errors.New("poll needs a question and 2 to 10 distinct options")
+	ErrPollNotFound = errors.New("poll not found")
+	ErrPollClosed   = errors.New("poll is closed")
+	ErrInvalidVote  = errors.New("invalid vote")
+)
+
+type PollService struct {
+	pollsRepo polls.Repository
+	roomsRepo rooms.Repository
+}
+
+// PollDraft holds what a moderator asks when creating a poll
+type PollDraft struct {
+	Question    string
+	Options     []string
+	Anonymous   bool
+	MultiChoice bool
+	Duration    time.Duration // Zero keeps the poll open until it's closed
+}
+
+// PollResult is a poll together with its current tally
+type PollResult struct {
+	Poll        *models.Poll
+	Closed      bool
+	Votes       map[uint]int    // Option ID to number of votes
+	Voters      map[uint][]uint // Option ID to user IDs, nil for anonymous polls
+	TotalVoters int
+}
+
+func NewPollService(pollsRepo polls.Repository, roomsRepo rooms.Repository) *PollService {
+	return &PollService{
+		pollsRepo: pollsRepo,
+		roomsRepo: roomsRepo,
+	}
+}
+
+// CreatePoll opens a new poll in the room, only moderators may do so
+func (s *PollService) CreatePoll(userID uint, roomID uint, draft PollDraft) (*PollResult, error) {
+	if err := s.authorize(userID, roomID, models.PermissionModerate); err != nil {
+		return nil, err
+	}
+
+	question := strings.TrimSpace(draft.Question)
+	if question == "" || len(question) > maxPollQuestion {
+		return nil, ErrInvalidPoll
+	}
+
+	if len(draft.Options) < minPollOptions || len(draft.Options) > maxPollOptions {
+		return nil, ErrInvalidPoll
+	}
+
+	options := make([]models.PollOption, 0, len(draft.Options))
+	for i, text := range draft.Options {
+		text = strings.TrimSpace(text)
+		duplicate := slices.ContainsFunc(options, func(option models.PollOption) bool {
+			return option.Text == text
+		})
+		if text == "" || len(text) > maxPollOption || duplicate {
+			return nil, ErrInvalidPoll
+		}
+		options = append(options, models.PollOption{Position: i, Text: text})
+	}
+
+	if draft.Duration < 0 || draft.Duration > MaxPollDuration {
+		return nil, ErrInvalidPoll
+	}
+
+	var closesAt *time.Time
+	if draft.Duration > 0 {
+		deadline := time.Now().Add(draft.Duration)
+		closesAt = &deadline
+	}
+
+	poll, err := s.pollsRepo.CreatePoll(models.Poll{
+		RoomID:      roomID,
+		CreatorID:   userID,
+		Question:    question,
+		Anonymous:   draft.Anonymous,
+		MultiChoice: draft.MultiChoice,
+		ClosesAt:    closesAt,
+		Options:     options,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.results(poll)
+}
+
+// Vote replaces the user's choice on an open poll, an empty choice retracts
+// the vote
+func (s *PollService) Vote(userID uint, roomID uint, pollID uint, optionIDs []uint) (*PollResult, error) {
+	if err := s.authorize(userID, roomID, models.PermissionSendData); err != nil {
+		return nil, err
+	}
+
+	poll, err := s.getRoomPoll(roomID, pollID)
+	if err != nil {
+		return nil, err
+	}
+
+	if poll.IsClosed(time.Now()) {
+		return nil, ErrPollClosed
+	}
+
+	optionIDs = slices.Compact(slices.Sorted(slices.Values(optionIDs)))
+	if !poll.MultiChoice && len(optionIDs) > 1 {
+		return nil, ErrInvalidVote
+	}
+
+	for _, optionID := range optionIDs {
+		known := slices.ContainsFunc(poll.Options, func(option models.PollOption) bool {
+			return option.ID == optionID
+		})
+		if !known {
+			return nil, ErrInvalidVote
+		}
+	}
+
+	if err := s.pollsRepo.ReplaceVotes(poll.ID, userID, optionIDs); err != nil {
+		return nil, err
+	}
+
+	return s.results(poll)
+}
+
+// ClosePoll stops the voting on a poll, only moderators may do so
+func (s *PollService) ClosePoll(userID uint, roomID uint, pollID uint) (*PollResult, error) {
+	if err := s.authorize(userID, roomID, models.PermissionModerate); err != nil {
+		return nil, err
+	}
+
+	poll, err := s.getRoomPoll(roomID, pollID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.close(poll)
+}
+
+// ExpirePoll closes a poll once its timeout has passed, returning
+// ErrPollClosed if it was closed already
+func (s *PollService) ExpirePoll(pollID uint) (*PollResult, error) {
+	poll, err := s.pollsRepo.GetPoll(pollID)
+	if err != nil {
+		return nil, ErrPollNotFound
+	}
+
+	return s.close(poll)
+}
+
+// ListPolls returns the room's polls with their results, newest first
+func (s *PollService) ListPolls(userID uint, roomID uint) ([]*PollResult, error) {
+	if _, err := s.roomsRepo.GetParticipant(roomID, userID); err != nil {
+		return nil, rooms.ErrRoomNotFound
+	}
+
+	roomPolls, err := s.pollsRepo.ListPolls(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*PollResult, 0, len(roomPolls))
+	for _, poll := range roomPolls {
+		result, err := s.results(poll)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *PollService) close(poll *models.Poll) (*PollResult, error) {
+	closed, err := s.pollsRepo.ClosePoll(poll.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, ErrPollClosed
+	}
+
+	poll, err = s.pollsRepo.GetPoll(poll.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.results(poll)
+}
+
+// results tallies the votes of the poll
+func (s *PollService) results(poll *models.Poll) (*PollResult, error) {
+	votes, err := s.pollsRepo.ListVotes(poll.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PollResult{
+		Poll:   poll,
+		Closed: poll.IsClosed(time.Now()),
+		Votes:  make(map[uint]int, len(poll.Options)),
+	}
+	if !poll.Anonymous {
+		result.Voters = make(map[uint][]uint, len(poll.Options))
+	}
+
+	voters := make(map[uint]bool)
+	for _, vote := range votes {
+		result.Votes[vote.OptionID]++
+		if result.Voters != nil {
+			result.Voters[vote.OptionID] = append(result.Voters[vote.OptionID], vote.UserID)
+		}
+		voters[vote.UserID] = true
+	}
+	result.TotalVoters = len(voters)
+
+	return result, nil
+}
+
+func (s *PollService) authorize(userID uint, roomID uint, permission models.Permission) error {
+	participant, err := s.roomsRepo.GetParticipant(roomID, userID)
+	if err != nil {
+		return rooms.ErrRoomNotFound
+	}
+
+	if !participant.Role.Can(permission) {
+		return rooms.ErrPermissionDenied
+	}
+	return nil
+}
+
+func (s *PollService) getRoomPoll(roomID uint, pollID uint) (*models.Poll, error) {
+	poll, err := s.pollsRepo.GetPoll(pollID)
+	if err != nil {
+		if errors.Is(err, polls.ErrPollNotFound) {
+			return nil, ErrPollNotFound
+		}
+		return nil, err
+	}
+
+	if poll.RoomID != roomID {
+		return nil, ErrPollNotFound
+	}
+	return poll, nil
+}
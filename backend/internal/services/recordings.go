@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/repository/recordings"
+	"github.com/serozhenka/shary/internal/repository/rooms"
+	"github.com/serozhenka/shary/internal/storage"
+)
+
+var ErrRecordingNotFound = errors.New("recording not found")
+
+// recordingExtensions maps the content types of recorded tracks to file extensions
+var recordingExtensions = map[string]string{
+	"audio/ogg":  ".ogg",
+	"video/webm": ".webm",
+}
+
+type RecordingService struct {
+	store          storage.BlobStore
+	recordingsRepo recordings.Repository
+	roomsRepo      rooms.Repository
+	links          downloadSigner
+}
+
+func NewRecordingService(
+	store storage.BlobStore,
+	recordingsRepo recordings.Repository,
+	roomsRepo rooms.Repository,
+	secret string,
+) *RecordingService {
+	return &RecordingService{
+		store:          store,
+		recordingsRepo: recordingsRepo,
+		roomsRepo:      roomsRepo,
+		links:          downloadSigner{secret: []byte(secret), kind: "recording"},
+	}
+}
+
+// Start records a new recording of the room, only moderators may start one
+func (s *RecordingService) Start(userID uint, roomID uint) (*models.Recording, error) {
+	participant, err := s.roomsRepo.GetParticipant(roomID, userID)
+	if err != nil {
+		return nil, rooms.ErrRoomNotFound
+	}
+
+	if !participant.Role.Can(models.PermissionModerate) {
+		return nil, rooms.ErrPermissionDenied
+	}
+
+	return s.recordingsRepo.CreateRecording(models.Recording{
+		RoomID:      roomID,
+		StartedByID: userID,
+		Status:      models.RecordingInProgress,
+		StartedAt:   time.Now(),
+	})
+}
+
+// Finish marks the recording as completed, or failed, once all of its
+// tracks have been saved
+func (s *RecordingService) Finish(recordingID uint, status models.RecordingStatus) (*models.Recording, error) {
+	return s.recordingsRepo.FinishRecording(recordingID, status, time.Now())
+}
+
+// SaveTrack stores the file of a recorded participant track
+func (s *RecordingService) SaveTrack(
+	ctx context.Context,
+	recordingID uint,
+	userID uint,
+	kind string,
+	contentType string,
+	body io.Reader,
+	size int64,
+) (*models.RecordingTrack, error) {
+	recording, err := s.recordingsRepo.GetRecording(recordingID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf(
+		"recordings/%d/%d/%s%s",
+		recording.RoomID, recording.ID, ksuid.New().String(), recordingExtensions[contentType],
+	)
+	if err := s.store.Put(ctx, key, body, size, contentType); err != nil {
+		return nil, err
+	}
+
+	track, err := s.recordingsRepo.AddTrack(models.RecordingTrack{
+		RecordingID: recording.ID,
+		UserID:      userID,
+		Kind:        kind,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+	})
+	if err != nil {
+		s.store.Delete(ctx, key)
+		return nil, err
+	}
+
+	return track, nil
+}
+
+// ListRecordings returns the room's recordings to a participant, newest first
+func (s *RecordingService) ListRecordings(userID uint, roomID uint) ([]*models.Recording, error) {
+	if _, err := s.roomsRepo.GetParticipant(roomID, userID); err != nil {
+		return nil, rooms.ErrRoomNotFound
+	}
+
+	return s.recordingsRepo.ListRecordings(roomID)
+}
+
+// DownloadURL returns a short-lived link to a recorded track for a room participant
+func (s *RecordingService) DownloadURL(userID uint, roomID uint, recordingID uint, trackID uint) (string, time.Time, error) {
+	if _, err := s.roomsRepo.GetParticipant(roomID, userID); err != nil {
+		return "", time.Time{}, rooms.ErrRoomNotFound
+	}
+
+	recording, err := s.recordingsRepo.GetRecording(recordingID)
+	if err != nil || recording.RoomID != roomID {
+		return "", time.Time{}, ErrRecordingNotFound
+	}
+
+	track, err := s.recordingsRepo.GetTrack(trackID)
+	if err != nil || track.RecordingID != recording.ID {
+		return "", time.Time{}, ErrRecordingNotFound
+	}
+
+	url, expiresAt := s.links.url("/recordings/tracks", track.ID, userID)
+	return url, expiresAt, nil
+}
+
+// Open checks a signed download link and opens the recorded track, the
+// link's user must still be a participant of the recorded room
+func (s *RecordingService) Open(ctx context.Context, trackID uint, userID uint, expires int64, signature string) (*Download, error) {
+	if err := s.links.verify(trackID, userID, expires, signature); err != nil {
+		return nil, err
+	}
+
+	track, err := s.recordingsRepo.GetTrack(trackID)
+	if err != nil {
+		return nil, ErrRecordingNotFound
+	}
+
+	recording, err := s.recordingsRepo.GetRecording(track.RecordingID)
+	if err != nil {
+		return nil, ErrRecordingNotFound
+	}
+
+	if _, err := s.roomsRepo.GetParticipant(recording.RoomID, userID); err != nil {
+		return nil, ErrInvalidDownloadLink
+	}
+
+	body, err := s.store.Get(ctx, track.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, ErrRecordingNotFound
+		}
+		return nil, err
+	}
+
+	return &Download{
+		ContentType: track.ContentType,
+		Size:        track.Size,
+		FileName:    fmt.Sprintf("recording-%d-%s-%d%s", track.RecordingID, track.Kind, track.ID, recordingExtensions[track.ContentType]),
+		Body:        body,
+	}, nil
+}
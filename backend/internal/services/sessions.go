@@ -0,0 +1,169 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/repository/rooms"
+	"github.com/serozhenka/shary/internal/repository/sessions"
+	"github.com/serozhenka/shary/internal/whiteboard"
+)
+
+var (
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrWhiteboardNotFound = errors.New("whiteboard not found")
+)
+
+// FinalBoard is a whiteboard as it was left at the end of a session
+type FinalBoard struct {
+	Name     string // Of the meeting or breakout it was drawn in
+	Snapshot whiteboard.Snapshot
+}
+
+type SessionService struct {
+	sessionsRepo sessions.Repository
+	roomsRepo    rooms.Repository
+}
+
+func NewSessionService(sessionsRepo sessions.Repository, roomsRepo rooms.Repository) *SessionService {
+	return &SessionService{
+		sessionsRepo: sessionsRepo,
+		roomsRepo:    roomsRepo,
+	}
+}
+
+// Start records a new session of the room's meeting
+func (s *SessionService) Start(roomID uint) (*models.MeetingSession, error) {
+	return s.sessionsRepo.CreateSession(models.MeetingSession{
+		RoomID:    roomID,
+		StartedAt: time.Now(),
+	})
+}
+
+// End marks the session as over and keeps the connection quality its
+// clients reported, the transcript of what they said and what they drew
+func (s *SessionService) End(sessionID uint, summaries []models.QualitySummary, transcript []models.TranscriptSegment, boards []FinalBoard) error {
+	if _, err := s.sessionsRepo.EndSession(sessionID, time.Now()); err != nil {
+		return err
+	}
+
+	for i := range summaries {
+		summaries[i].SessionID = sessionID
+	}
+	if err := s.sessionsRepo.AddQualitySummaries(summaries); err != nil {
+		return err
+	}
+
+	for i := range transcript {
+		transcript[i].SessionID = sessionID
+	}
+	if err := s.sessionsRepo.AddTranscriptSegments(transcript); err != nil {
+		return err
+	}
+
+	whiteboards := make([]models.Whiteboard, 0, len(boards))
+	for _, board := range boards {
+		snapshot, err := json.Marshal(board.Snapshot)
+		if err != nil {
+			return err
+		}
+
+		whiteboards = append(whiteboards, models.Whiteboard{
+			SessionID: sessionID,
+			Name:      board.Name,
+			Elements:  len(board.Snapshot.Ops),
+			Snapshot:  string(snapshot),
+		})
+	}
+	return s.sessionsRepo.AddWhiteboards(whiteboards)
+}
+
+// ListSessions returns the room's sessions to a participant, newest first
+func (s *SessionService) ListSessions(userID uint, roomID uint) ([]*models.MeetingSession, error) {
+	if _, err := s.roomsRepo.GetParticipant(roomID, userID); err != nil {
+		return nil, rooms.ErrRoomNotFound
+	}
+
+	return s.sessionsRepo.ListSessions(roomID)
+}
+
+// QualitySummaries returns the connection quality reported during one of the
+// room's sessions, only moderators may see it
+func (s *SessionService) QualitySummaries(userID uint, roomID uint, sessionID uint) ([]models.QualitySummary, error) {
+	if _, err := s.getSession(userID, roomID, sessionID, models.PermissionModerate); err != nil {
+		return nil, err
+	}
+
+	return s.sessionsRepo.ListQualitySummaries(sessionID)
+}
+
+// Transcript returns what was said during one of the room's sessions to a
+// participant, along with the session the segments' times are relative to
+func (s *SessionService) Transcript(userID uint, roomID uint, sessionID uint) (*models.MeetingSession, []models.TranscriptSegment, error) {
+	session, err := s.getSession(userID, roomID, sessionID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	segments, err := s.sessionsRepo.ListTranscriptSegments(sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, segments, nil
+}
+
+// ListWhiteboards returns the boards left at the end of one of the room's
+// sessions to a participant
+func (s *SessionService) ListWhiteboards(userID uint, roomID uint, sessionID uint) ([]models.Whiteboard, error) {
+	if _, err := s.getSession(userID, roomID, sessionID, ""); err != nil {
+		return nil, err
+	}
+
+	return s.sessionsRepo.ListWhiteboards(sessionID)
+}
+
+// WhiteboardSVG draws one of the room's boards for a participant
+func (s *SessionService) WhiteboardSVG(userID uint, roomID uint, whiteboardID uint) ([]byte, error) {
+	board, err := s.sessionsRepo.GetWhiteboard(whiteboardID)
+	if err != nil {
+		if errors.Is(err, sessions.ErrWhiteboardNotFound) {
+			return nil, ErrWhiteboardNotFound
+		}
+		return nil, err
+	}
+
+	// Boards of other rooms don't exist as far as the user is concerned
+	if _, err := s.getSession(userID, roomID, board.SessionID, ""); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return nil, ErrWhiteboardNotFound
+		}
+		return nil, err
+	}
+
+	var snapshot whiteboard.Snapshot
+	if err := json.Unmarshal([]byte(board.Snapshot), &snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot.SVG(), nil
+}
+
+// getSession looks up a session of the room, making sure the user is a
+// participant holding the permission
+func (s *SessionService) getSession(userID uint, roomID uint, sessionID uint, permission models.Permission) (*models.MeetingSession, error) {
+	participant, err := s.roomsRepo.GetParticipant(roomID, userID)
+	if err != nil {
+		return nil, rooms.ErrRoomNotFound
+	}
+
+	if permission != "" && !participant.Role.Can(permission) {
+		return nil, rooms.ErrPermissionDenied
+	}
+
+	session, err := s.sessionsRepo.GetSession(sessionID)
+	if err != nil || session.RoomID != roomID {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
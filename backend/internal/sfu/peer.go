@@ -0,0 +1,315 @@
+package sfu
+
+import (
+	"log"
+	"sync"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/webrtc/v4"
+)
+
+// Peer is a client connected to the SFU over two peer connections, one
+// carrying its own media and one carrying everyone else's
+type Peer struct {
+	id      string
+	session *Session
+	signal  func(Signal)
+
+	publisher  *webrtc.PeerConnection
+	subscriber *webrtc.PeerConnection
+
+	mu          sync.Mutex
+	senders     map[*forwardedTrack]*subscription
+	paused      map[string]bool                      // Publishers whose video the client doesn't render
+	candidates  map[string][]webrtc.ICECandidateInit // Received before the remote description
+	negotiating bool                                 // Waiting for the answer to an offer
+	renegotiate bool                                 // Tracks changed while negotiating
+	closed      bool
+}
+
+// subscription is a forwarded track sent to the peer
+type subscription struct {
+	sender  *webrtc.RTPSender
+	offered bool // Included in an offer to the client
+	started bool // The client accepted it, so the sender is running
+	paused  bool
+}
+
+func newPeer(session *Session, id string, signal func(Signal)) (*Peer, error) {
+	publisher, err := session.api.NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		return nil, err
+	}
+
+	subscriber, err := session.api.NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		publisher.Close()
+		return nil, err
+	}
+
+	peer := &Peer{
+		id:         id,
+		session:    session,
+		signal:     signal,
+		publisher:  publisher,
+		subscriber: subscriber,
+		senders:    make(map[*forwardedTrack]*subscription),
+		candidates: make(map[string][]webrtc.ICECandidateInit),
+	}
+
+	publisher.OnICECandidate(peer.onCandidate(PublisherID))
+	subscriber.OnICECandidate(peer.onCandidate(SubscriberID))
+	publisher.OnTrack(func(remote *webrtc.TrackRemote, _ *webrtc.RTPReceiver) {
+		session.publish(peer, remote)
+	})
+
+	return peer, nil
+}
+
+func (p *Peer) onCandidate(target string) func(*webrtc.ICECandidate) {
+	return func(candidate *webrtc.ICECandidate) {
+		// Gathering is complete
+		if candidate == nil {
+			return
+		}
+
+		init := candidate.ToJSON()
+		p.signal(Signal{Target: target, Candidate: &init})
+	}
+}
+
+func (p *Peer) handle(signal Signal) error {
+	switch {
+	case signal.Description != nil:
+		if signal.Target == PublisherID && signal.Description.Type == webrtc.SDPTypeOffer {
+			return p.answer(*signal.Description)
+		}
+		if signal.Target == SubscriberID && signal.Description.Type == webrtc.SDPTypeAnswer {
+			return p.acceptAnswer(*signal.Description)
+		}
+	case signal.Candidate != nil:
+		if connection := p.connection(signal.Target); connection != nil {
+			return p.addCandidate(signal.Target, connection, *signal.Candidate)
+		}
+	}
+	return ErrUnexpectedSignal
+}
+
+func (p *Peer) connection(target string) *webrtc.PeerConnection {
+	switch target {
+	case PublisherID:
+		return p.publisher
+	case SubscriberID:
+		return p.subscriber
+	}
+	return nil
+}
+
+// answer accepts the media the client wants to publish
+func (p *Peer) answer(offer webrtc.SessionDescription) error {
+	if err := p.publisher.SetRemoteDescription(offer); err != nil {
+		return err
+	}
+	if err := p.flushCandidates(PublisherID, p.publisher); err != nil {
+		return err
+	}
+
+	answer, err := p.publisher.CreateAnswer(nil)
+	if err != nil {
+		return err
+	}
+	if err := p.publisher.SetLocalDescription(answer); err != nil {
+		return err
+	}
+
+	p.signal(Signal{Target: PublisherID, Description: &answer})
+	return nil
+}
+
+func (p *Peer) acceptAnswer(answer webrtc.SessionDescription) error {
+	if err := p.subscriber.SetRemoteDescription(answer); err != nil {
+		return err
+	}
+	if err := p.flushCandidates(SubscriberID, p.subscriber); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.negotiating = false
+	again := p.renegotiate
+	p.renegotiate = false
+	for _, sub := range p.senders {
+		sub.started = sub.started || sub.offered
+	}
+	p.applyPauses()
+	p.mu.Unlock()
+
+	if again {
+		p.negotiate()
+	}
+	return nil
+}
+
+func (p *Peer) addCandidate(target string, connection *webrtc.PeerConnection, candidate webrtc.ICECandidateInit) error {
+	p.mu.Lock()
+	if connection.RemoteDescription() == nil {
+		p.candidates[target] = append(p.candidates[target], candidate)
+		p.mu.Unlock()
+		return nil
+	}
+	p.mu.Unlock()
+
+	return connection.AddICECandidate(candidate)
+}
+
+// flushCandidates adds the candidates that arrived ahead of the remote description
+func (p *Peer) flushCandidates(target string, connection *webrtc.PeerConnection) error {
+	p.mu.Lock()
+	candidates := p.candidates[target]
+	delete(p.candidates, target)
+	p.mu.Unlock()
+
+	for _, candidate := range candidates {
+		if err := connection.AddICECandidate(candidate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// negotiate offers the current set of forwarded tracks to the client, or
+// schedules another round if an offer is still unanswered
+func (p *Peer) negotiate() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	if p.negotiating {
+		p.renegotiate = true
+		p.mu.Unlock()
+		return
+	}
+	p.negotiating = true
+	for _, sub := range p.senders {
+		sub.offered = true
+	}
+	p.mu.Unlock()
+
+	offer, err := p.subscriber.CreateOffer(nil)
+	if err == nil {
+		err = p.subscriber.SetLocalDescription(offer)
+	}
+	if err != nil {
+		log.Printf("Failed to offer tracks to %s: %v", p.id, err)
+
+		p.mu.Lock()
+		p.negotiating = false
+		p.mu.Unlock()
+		return
+	}
+
+	p.signal(Signal{Target: SubscriberID, Description: &offer})
+}
+
+// subscribe adds a forwarded track to the subscriber connection, the caller
+// negotiates afterwards
+func (p *Peer) subscribe(track *forwardedTrack) {
+	p.signal(Signal{Target: SubscriberID, Track: track.info()})
+
+	sender, err := p.subscriber.AddTrack(track.local)
+	if err != nil {
+		log.Printf("Failed to forward track %s to %s: %v", track.remote.ID(), p.id, err)
+		return
+	}
+
+	p.mu.Lock()
+	p.senders[track] = &subscription{sender: sender}
+	p.mu.Unlock()
+
+	go p.readRTCP(sender, track)
+	track.requestKeyframe()
+}
+
+// unsubscribe removes a forwarded track, reporting whether it was sent to the peer
+func (p *Peer) unsubscribe(track *forwardedTrack) bool {
+	p.mu.Lock()
+	sub, ok := p.senders[track]
+	delete(p.senders, track)
+	p.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	if err := p.subscriber.RemoveTrack(sub.sender); err != nil {
+		return false
+	}
+	return true
+}
+
+// pause stops forwarding the video of the given publishers, everyone
+// else's video is forwarded again
+func (p *Peer) pause(publisherIDs []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.paused = make(map[string]bool, len(publisherIDs))
+	for _, id := range publisherIDs {
+		p.paused[id] = true
+	}
+	p.applyPauses()
+}
+
+// applyPauses detaches paused tracks from their senders and reattaches the
+// others. Senders are only touched once negotiated, as a sender without a
+// track can't start. The caller must hold p.mu.
+func (p *Peer) applyPauses() {
+	for track, sub := range p.senders {
+		paused := p.paused[track.publisher.id] && track.remote.Kind() == webrtc.RTPCodecTypeVideo
+		if !sub.started || paused == sub.paused {
+			continue
+		}
+
+		var local webrtc.TrackLocal
+		if !paused {
+			local = track.local
+		}
+		if err := sub.sender.ReplaceTrack(local); err != nil {
+			log.Printf("Failed to pause track %s for %s: %v", track.remote.ID(), p.id, err)
+			continue
+		}
+
+		sub.paused = paused
+		if !paused {
+			// Decoding resumes with the next keyframe
+			track.requestKeyframe()
+		}
+	}
+}
+
+// readRTCP passes keyframe requests of the subscriber on to the publisher
+func (p *Peer) readRTCP(sender *webrtc.RTPSender, track *forwardedTrack) {
+	for {
+		packets, _, err := sender.ReadRTCP()
+		if err != nil {
+			return
+		}
+
+		for _, packet := range packets {
+			switch packet.(type) {
+			case *rtcp.PictureLossIndication, *rtcp.FullIntraRequest:
+				track.requestKeyframe()
+			}
+		}
+	}
+}
+
+func (p *Peer) close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	p.publisher.Close()
+	p.subscriber.Close()
+}
@@ -0,0 +1,58 @@
+package sfu
+
+import (
+	"slices"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+)
+
+// Recorder receives the packets of the tracks published while a session is
+// being recorded
+type Recorder interface {
+	// RecordTrack returns where to write the track's packets, or nil to skip it
+	RecordTrack(info TrackInfo, codec webrtc.RTPCodecParameters) PacketWriter
+}
+
+type PacketWriter interface {
+	WriteRTP(packet *rtp.Packet) error
+	Close() error
+}
+
+// StartRecording records the tracks being forwarded and any published later
+// until StopRecording is called
+func (s *Session) StartRecording(recorder Recorder) {
+	s.mu.Lock()
+	s.recorder = recorder
+	tracks := slices.Clone(s.tracks)
+	s.mu.Unlock()
+
+	for _, track := range tracks {
+		s.record(track, recorder)
+	}
+}
+
+// StopRecording closes the writers of every recorded track
+func (s *Session) StopRecording() {
+	s.mu.Lock()
+	s.recorder = nil
+	tracks := slices.Clone(s.tracks)
+	s.mu.Unlock()
+
+	for _, track := range tracks {
+		track.stopRecording()
+	}
+}
+
+func (s *Session) record(track *forwardedTrack, recorder Recorder) {
+	track.startRecording(recorder)
+
+	// Recording may have stopped while the writer was being created
+	s.mu.Lock()
+	stopped := s.recorder != recorder
+	s.mu.Unlock()
+
+	if stopped {
+		track.stopRecording()
+	}
+}
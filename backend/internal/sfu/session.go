@@ -0,0 +1,153 @@
+package sfu
+
+import (
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/pion/webrtc/v4"
+	"golang.org/x/exp/maps"
+)
+
+// Session forwards the tracks published by each peer to every other peer
+type Session struct {
+	api         *webrtc.API
+	peers       map[string]*Peer
+	tracks      []*forwardedTrack
+	recorder    Recorder
+	transcriber Recorder // Receives the audio tracks to caption them
+	mu          sync.Mutex
+}
+
+// AddPeer connects a client to the session, signal delivers the server's
+// negotiation messages to it
+func (s *Session) AddPeer(id string, signal func(Signal)) error {
+	peer, err := newPeer(s, id, signal)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if existing, ok := s.peers[id]; ok {
+		existing.close()
+	}
+	s.peers[id] = peer
+	tracks := slices.Clone(s.tracks)
+	s.mu.Unlock()
+
+	for _, track := range tracks {
+		peer.subscribe(track)
+	}
+	if len(tracks) > 0 {
+		peer.negotiate()
+	}
+	return nil
+}
+
+// RemovePeer disconnects a client, its tracks stop being forwarded
+func (s *Session) RemovePeer(id string) {
+	s.mu.Lock()
+	peer, ok := s.peers[id]
+	delete(s.peers, id)
+	s.mu.Unlock()
+
+	if ok {
+		peer.close()
+	}
+}
+
+// Signal hands a negotiation message from a client to its peer
+func (s *Session) Signal(id string, signal Signal) error {
+	s.mu.Lock()
+	peer, ok := s.peers[id]
+	s.mu.Unlock()
+
+	if !ok {
+		return ErrPeerNotFound
+	}
+	return peer.handle(signal)
+}
+
+// PauseVideo stops forwarding the video of the given publishers to a
+// subscriber that doesn't render it, the video of everyone else resumes
+func (s *Session) PauseVideo(subscriberID string, publisherIDs []string) error {
+	s.mu.Lock()
+	peer, ok := s.peers[subscriberID]
+	s.mu.Unlock()
+
+	if !ok {
+		return ErrPeerNotFound
+	}
+	peer.pause(publisherIDs)
+	return nil
+}
+
+// publish forwards a track received from the peer to everyone else until
+// the publisher stops sending it
+func (s *Session) publish(publisher *Peer, remote *webrtc.TrackRemote) {
+	local, err := webrtc.NewTrackLocalStaticRTP(remote.Codec().RTPCodecCapability, remote.ID(), remote.StreamID())
+	if err != nil {
+		log.Printf("Failed to forward track %s: %v", remote.ID(), err)
+		return
+	}
+
+	track := &forwardedTrack{
+		publisher: publisher,
+		remote:    remote,
+		local:     local,
+	}
+
+	s.mu.Lock()
+	if s.peers[publisher.id] != publisher {
+		s.mu.Unlock()
+		return
+	}
+	s.tracks = append(s.tracks, track)
+	subscribers := s.others(publisher)
+	recorder := s.recorder
+	transcriber := s.transcriber
+	s.mu.Unlock()
+
+	if recorder != nil {
+		s.record(track, recorder)
+	}
+	if transcriber != nil {
+		s.transcribe(track, transcriber)
+	}
+
+	for _, subscriber := range subscribers {
+		subscriber.subscribe(track)
+		subscriber.negotiate()
+	}
+
+	track.forward()
+	track.stopRecording()
+	track.transcription.close(track)
+	s.unpublish(track)
+}
+
+func (s *Session) unpublish(track *forwardedTrack) {
+	s.mu.Lock()
+	s.tracks = slices.DeleteFunc(s.tracks, func(t *forwardedTrack) bool {
+		return t == track
+	})
+	subscribers := maps.Values(s.peers)
+	s.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		if subscriber.unsubscribe(track) {
+			subscriber.negotiate()
+		}
+	}
+}
+
+// others returns every peer but the given one, the caller must hold s.mu
+func (s *Session) others(peer *Peer) []*Peer {
+	others := make([]*Peer, 0, len(s.peers))
+	for _, other := range s.peers {
+		if other != peer {
+			others = append(others, other)
+		}
+	}
+	return others
+}
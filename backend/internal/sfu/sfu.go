@@ -0,0 +1,94 @@
+package sfu
+
+import (
+	"errors"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/webrtc/v4"
+)
+
+// Pseudo client IDs addressing the server in offer, answer and iceCandidate
+// messages. Clients send their media over the publisher connection, which
+// they offer, and receive everyone else's over the subscriber connection,
+// which the server offers.
+const (
+	PublisherID  = "sfu:publish"
+	SubscriberID = "sfu:subscribe"
+)
+
+var (
+	ErrPeerNotFound     = errors.New("peer not found")
+	ErrUnexpectedSignal = errors.New("unexpected signal")
+)
+
+// IsServerID reports whether a signaling message is addressed to the SFU
+// rather than to another client
+func IsServerID(id string) bool {
+	return id == PublisherID || id == SubscriberID
+}
+
+type Config struct {
+	PublicIP string // Advertised instead of the host address when behind NAT
+	PortMin  uint16 // UDP port range for media, zero allows any port
+	PortMax  uint16
+}
+
+// SFU creates forwarding sessions sharing the same WebRTC settings
+type SFU struct {
+	api *webrtc.API
+}
+
+func New(config Config) (*SFU, error) {
+	mediaEngine := &webrtc.MediaEngine{}
+	if err := mediaEngine.RegisterDefaultCodecs(); err != nil {
+		return nil, err
+	}
+
+	interceptors := &interceptor.Registry{}
+	if err := webrtc.RegisterDefaultInterceptors(mediaEngine, interceptors); err != nil {
+		return nil, err
+	}
+
+	settings := webrtc.SettingEngine{}
+	if config.PublicIP != "" {
+		settings.SetNAT1To1IPs([]string{config.PublicIP}, webrtc.ICECandidateTypeHost)
+	}
+	if config.PortMin != 0 || config.PortMax != 0 {
+		if err := settings.SetEphemeralUDPPortRange(config.PortMin, config.PortMax); err != nil {
+			return nil, err
+		}
+	}
+
+	return &SFU{
+		api: webrtc.NewAPI(
+			webrtc.WithMediaEngine(mediaEngine),
+			webrtc.WithInterceptorRegistry(interceptors),
+			webrtc.WithSettingEngine(settings),
+		),
+	}, nil
+}
+
+// NewSession creates an empty session, one is used per meeting
+func (s *SFU) NewSession() *Session {
+	return &Session{
+		api:   s.api,
+		peers: make(map[string]*Peer),
+	}
+}
+
+// Signal is a negotiation message exchanged between the SFU and a client.
+// Exactly one of Description, Candidate and Track is set.
+type Signal struct {
+	Target      string // PublisherID or SubscriberID
+	Description *webrtc.SessionDescription
+	Candidate   *webrtc.ICECandidateInit
+	Track       *TrackInfo // Announces a track before it's offered to the subscriber
+}
+
+// TrackInfo tells subscribers whose media a forwarded track carries
+type TrackInfo struct {
+	PublisherID string
+	StreamID    string
+	TrackID     string
+	Kind        string
+}
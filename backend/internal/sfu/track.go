@@ -0,0 +1,131 @@
+package sfu
+
+import (
+	"errors"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+)
+
+// forwardedTrack relays the RTP packets of a published track to the
+// subscribers' copies of it
+type forwardedTrack struct {
+	publisher *Peer
+	remote    *webrtc.TrackRemote
+	local     *webrtc.TrackLocalStaticRTP
+
+	recording     tap
+	transcription tap
+}
+
+// tap hands a copy of the forwarded packets to a writer
+type tap struct {
+	writer PacketWriter
+	mu     sync.Mutex
+}
+
+func (t *forwardedTrack) info() *TrackInfo {
+	return &TrackInfo{
+		PublisherID: t.publisher.id,
+		StreamID:    t.remote.StreamID(),
+		TrackID:     t.remote.ID(),
+		Kind:        t.remote.Kind().String(),
+	}
+}
+
+// forward copies packets until the publisher stops sending
+func (t *forwardedTrack) forward() {
+	for {
+		packet, _, err := t.remote.ReadRTP()
+		if err != nil {
+			return
+		}
+
+		t.recording.write(t, packet)
+		t.transcription.write(t, packet)
+
+		// Closed pipes only mean a subscriber went away
+		if err := t.local.WriteRTP(packet); err != nil && !errors.Is(err, io.ErrClosedPipe) {
+			return
+		}
+	}
+}
+
+// requestKeyframe asks the publisher for a full frame so new subscribers
+// don't wait for the next one to start decoding
+func (t *forwardedTrack) requestKeyframe() {
+	if t.remote.Kind() != webrtc.RTPCodecTypeVideo {
+		return
+	}
+
+	t.publisher.publisher.WriteRTCP([]rtcp.Packet{
+		&rtcp.PictureLossIndication{MediaSSRC: uint32(t.remote.SSRC())},
+	})
+}
+
+func (t *forwardedTrack) startRecording(recorder Recorder) {
+	// Video can only be decoded from a keyframe onwards
+	if t.recording.open(t, recorder) {
+		t.requestKeyframe()
+	}
+}
+
+func (t *forwardedTrack) stopRecording() {
+	t.recording.close(t)
+}
+
+// open starts writing the track's packets where the recorder wants them,
+// it reports whether the recorder took the track
+func (tp *tap) open(track *forwardedTrack, recorder Recorder) bool {
+	writer := recorder.RecordTrack(*track.info(), track.remote.Codec())
+	if writer == nil {
+		return false
+	}
+
+	tp.mu.Lock()
+	previous := tp.writer
+	tp.writer = writer
+	tp.mu.Unlock()
+
+	if previous != nil {
+		closeWriter(track, previous)
+	}
+	return true
+}
+
+func (tp *tap) close(track *forwardedTrack) {
+	tp.mu.Lock()
+	writer := tp.writer
+	tp.writer = nil
+	tp.mu.Unlock()
+
+	if writer != nil {
+		closeWriter(track, writer)
+	}
+}
+
+func (tp *tap) write(track *forwardedTrack, packet *rtp.Packet) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	if tp.writer == nil {
+		return
+	}
+
+	// A broken writer mustn't affect forwarding
+	if err := tp.writer.WriteRTP(packet); err != nil {
+		log.Printf("Failed to write packets of track %s: %v", track.remote.ID(), err)
+		closeWriter(track, tp.writer)
+		tp.writer = nil
+	}
+}
+
+func closeWriter(track *forwardedTrack, writer PacketWriter) {
+	if err := writer.Close(); err != nil {
+		log.Printf("Failed to finish writing packets of track %s: %v", track.remote.ID(), err)
+	}
+}
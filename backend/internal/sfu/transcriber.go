@@ -0,0 +1,49 @@
+package sfu
+
+import (
+	"slices"
+
+	"github.com/pion/webrtc/v4"
+)
+
+// StartTranscribing hands the audio tracks being forwarded, and any
+// published later, to the transcriber until StopTranscribing is called. The
+// transcriber receives the tracks just like a Recorder.
+func (s *Session) StartTranscribing(transcriber Recorder) {
+	s.mu.Lock()
+	s.transcriber = transcriber
+	tracks := slices.Clone(s.tracks)
+	s.mu.Unlock()
+
+	for _, track := range tracks {
+		s.transcribe(track, transcriber)
+	}
+}
+
+// StopTranscribing closes the writers of every transcribed track
+func (s *Session) StopTranscribing() {
+	s.mu.Lock()
+	s.transcriber = nil
+	tracks := slices.Clone(s.tracks)
+	s.mu.Unlock()
+
+	for _, track := range tracks {
+		track.transcription.close(track)
+	}
+}
+
+func (s *Session) transcribe(track *forwardedTrack, transcriber Recorder) {
+	if track.remote.Kind() != webrtc.RTPCodecTypeAudio {
+		return
+	}
+	track.transcription.open(track, transcriber)
+
+	// Transcription may have stopped while the writer was being created
+	s.mu.Lock()
+	stopped := s.transcriber != transcriber
+	s.mu.Unlock()
+
+	if stopped {
+		track.transcription.close(track)
+	}
+}
@@ -0,0 +1,150 @@
+// Package speaker picks the dominant speaker of a meeting from the audio
+// levels its participants report
+package speaker
+
+import (
+	"slices"
+	"time"
+)
+
+// Config tunes how eagerly the dominant speaker changes
+type Config struct {
+	SpeechLevel float64       // Average audio level, between 0 and 1, that counts as speech
+	Window      time.Duration // Levels are averaged over this much time
+	SwitchDelay time.Duration // How long a challenger has to stay louder before taking over
+	Margin      float64       // How much louder than the dominant speaker a challenger has to be, 0.25 is 25%
+}
+
+// DefaultConfig keeps the window shorter than the switch delay, so a brief
+// interjection has faded from the averages before it could take over
+var DefaultConfig = Config{
+	SpeechLevel: 0.02,
+	Window:      500 * time.Millisecond,
+	SwitchDelay: 1500 * time.Millisecond,
+	Margin:      0.25,
+}
+
+type sample struct {
+	level float64
+	at    time.Time
+}
+
+// Detector follows the dominant speaker. The speaker only changes once a
+// challenger has been clearly louder for a while, so brief interjections and
+// noise don't make layouts flicker. It's not safe for concurrent use.
+type Detector struct {
+	config   Config
+	samples  map[string][]sample
+	dominant string
+
+	challenger   string
+	challengedAt time.Time
+}
+
+func NewDetector(config Config) *Detector {
+	return &Detector{
+		config:  config,
+		samples: map[string][]sample{},
+	}
+}
+
+// Dominant returns the dominant speaker, empty until someone has spoken
+func (d *Detector) Dominant() string {
+	return d.dominant
+}
+
+// Update records a speaker's audio level, it returns the dominant speaker
+// and whether it changed
+func (d *Detector) Update(id string, level float64, now time.Time) (string, bool) {
+	d.samples[id] = append(d.samples[id], sample{level: level, at: now})
+	return d.evaluate(now)
+}
+
+// Remove forgets a speaker, e.g. once it left, it returns the dominant
+// speaker and whether it changed. A dominant speaker that's removed is
+// replaced by the loudest remaining one right away.
+func (d *Detector) Remove(id string, now time.Time) (string, bool) {
+	delete(d.samples, id)
+	if d.challenger == id {
+		d.challenger = ""
+	}
+	if d.dominant != id {
+		return d.dominant, false
+	}
+
+	d.dominant = ""
+	d.challenger = ""
+	loudest, _ := d.loudest(now)
+	d.dominant = loudest
+	return d.dominant, true
+}
+
+func (d *Detector) evaluate(now time.Time) (string, bool) {
+	loudest, level := d.loudest(now)
+	if loudest == "" || loudest == d.dominant {
+		d.challenger = ""
+		return d.dominant, false
+	}
+
+	// Whoever speaks first doesn't have anyone to challenge
+	if d.dominant == "" {
+		d.dominant = loudest
+		return d.dominant, true
+	}
+
+	if level <= d.level(d.dominant, now)*(1+d.config.Margin) {
+		d.challenger = ""
+		return d.dominant, false
+	}
+
+	if d.challenger != loudest {
+		d.challenger = loudest
+		d.challengedAt = now
+	}
+	if now.Sub(d.challengedAt) < d.config.SwitchDelay {
+		return d.dominant, false
+	}
+
+	d.dominant = loudest
+	d.challenger = ""
+	return d.dominant, true
+}
+
+// loudest returns the speaker with the highest average level, ignoring
+// anyone below the speech level
+func (d *Detector) loudest(now time.Time) (string, float64) {
+	var loudest string
+	var loudestLevel float64
+
+	for id := range d.samples {
+		level := d.level(id, now)
+		if level < d.config.SpeechLevel {
+			continue
+		}
+		// Ties go to the lowest ID so the result doesn't depend on map order
+		if level > loudestLevel || (level == loudestLevel && id < loudest) {
+			loudest = id
+			loudestLevel = level
+		}
+	}
+	return loudest, loudestLevel
+}
+
+// level averages the speaker's levels within the window, forgetting older
+// ones
+func (d *Detector) level(id string, now time.Time) float64 {
+	samples := slices.DeleteFunc(d.samples[id], func(s sample) bool {
+		return now.Sub(s.at) > d.config.Window
+	})
+	d.samples[id] = samples
+
+	if len(samples) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, s := range samples {
+		sum += s.level
+	}
+	return sum / float64(len(samples))
+}
@@ -0,0 +1,53 @@
+package transcription
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Fake recognises chunks of UTF-8 text as speech so tests can script
+// captions, anything else like actual audio is ignored. A chunk ending in
+// "..." is an interim segment.
+type Fake struct{}
+
+func (Fake) Open(mimeType string, emit func(Segment)) (Stream, error) {
+	return &fakeStream{
+		openedAt: time.Now(),
+		emit:     emit,
+	}, nil
+}
+
+type fakeStream struct {
+	openedAt time.Time
+	emit     func(Segment)
+	start    time.Duration // Where the next segment starts, after the last final one
+	mu       sync.Mutex
+}
+
+func (s *fakeStream) Write(chunk []byte) error {
+	text := strings.TrimSpace(string(chunk))
+	if text == "" || !utf8.Valid(chunk) {
+		return nil
+	}
+
+	s.mu.Lock()
+	segment := Segment{
+		Text:  strings.TrimSuffix(text, "..."),
+		Start: s.start,
+		End:   time.Since(s.openedAt),
+		Final: !strings.HasSuffix(text, "..."),
+	}
+	if segment.Final {
+		s.start = segment.End
+	}
+	s.mu.Unlock()
+
+	s.emit(segment)
+	return nil
+}
+
+func (s *fakeStream) Close() error {
+	return nil
+}
@@ -0,0 +1,60 @@
+package transcription
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Cue is a caption shown from Start until End, relative to the start of the
+// session
+type Cue struct {
+	Start   time.Duration
+	End     time.Duration
+	Speaker string
+	Text    string
+}
+
+// SRT formats the cues as SubRip subtitles
+func SRT(cues []Cue) string {
+	var b strings.Builder
+	for i, cue := range cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n", i+1, timestamp(cue.Start, ","), timestamp(cue.End, ","))
+		if cue.Speaker != "" {
+			fmt.Fprintf(&b, "%s: ", cue.Speaker)
+		}
+		fmt.Fprintf(&b, "%s\n\n", cue.Text)
+	}
+	return b.String()
+}
+
+// WebVTT formats the cues as WebVTT captions, speakers are marked with voice
+// spans
+func WebVTT(cues []Cue) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "%s --> %s\n", timestamp(cue.Start, "."), timestamp(cue.End, "."))
+		if cue.Speaker != "" {
+			fmt.Fprintf(&b, "<v %s>", vttEscaper.Replace(cue.Speaker))
+		}
+		fmt.Fprintf(&b, "%s\n\n", vttEscaper.Replace(cue.Text))
+	}
+	return b.String()
+}
+
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// timestamp formats an offset as hours:minutes:seconds followed by the
+// milliseconds, SubRip separates them with a comma and WebVTT with a dot
+func timestamp(offset time.Duration, separator string) string {
+	offset = max(offset, 0)
+	return fmt.Sprintf(
+		"%02d:%02d:%02d%s%03d",
+		int(offset.Hours()),
+		int(offset.Minutes())%60,
+		int(offset.Seconds())%60,
+		separator,
+		offset.Milliseconds()%1000,
+	)
+}
@@ -0,0 +1,48 @@
+// Package transcription turns the speech of meeting participants into
+// captions
+package transcription
+
+import "time"
+
+// Segment is a piece of recognised speech. Interim segments are revised by
+// later ones until a final segment settles the text.
+type Segment struct {
+	Text  string
+	Start time.Duration // Offsets from when the stream was opened
+	End   time.Duration
+	Final bool
+}
+
+// Transcriber recognises speech, e.g. by handing audio to a speech-to-text
+// service
+type Transcriber interface {
+	// Open starts transcribing the audio of one speaker. Chunks of the given
+	// MIME type are written to the returned stream and recognised segments
+	// are passed to emit until the stream is closed.
+	Open(mimeType string, emit func(Segment)) (Stream, error)
+}
+
+// Stream consumes the audio of one speaker, either RTP payloads forwarded
+// by the SFU or chunks uploaded by the client
+type Stream interface {
+	Write(chunk []byte) error
+	Close() error
+}
+
+// Noop never recognises any speech, it's used when no speech-to-text
+// service is configured
+type Noop struct{}
+
+func (Noop) Open(mimeType string, emit func(Segment)) (Stream, error) {
+	return noopStream{}, nil
+}
+
+type noopStream struct{}
+
+func (noopStream) Write(chunk []byte) error {
+	return nil
+}
+
+func (noopStream) Close() error {
+	return nil
+}
@@ -0,0 +1,71 @@
+package whiteboard
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SVG draws the board's elements on a white background
+func (s Snapshot) SVG() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", Width, Height, Width, Height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", Width, Height)
+
+	for _, op := range s.Ops {
+		// Colors are validated when the op is applied, so they're safe to embed
+		stroke := fmt.Sprintf(`stroke="%s" stroke-width="%s" stroke-linecap="round" stroke-linejoin="round"`, op.Color, num(op.Size))
+		fill := op.Fill
+		if fill == "" {
+			fill = "none"
+		}
+
+		switch {
+		case op.Type == OpStroke:
+			commands := make([]string, 0, len(op.Points)+1)
+			for i, point := range op.Points {
+				command := "L"
+				if i == 0 {
+					command = "M"
+				}
+				commands = append(commands, fmt.Sprintf("%s %s %s", command, num(point.X), num(point.Y)))
+			}
+			// A single point is drawn as a dot
+			if len(op.Points) == 1 {
+				commands = append(commands, fmt.Sprintf("L %s %s", num(op.Points[0].X), num(op.Points[0].Y)))
+			}
+			fmt.Fprintf(&b, `<path d="%s" fill="none" %s/>`+"\n", strings.Join(commands, " "), stroke)
+		case op.Shape == ShapeRect:
+			from, to := op.Points[0], op.Points[1]
+			fmt.Fprintf(
+				&b, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s" %s/>`+"\n",
+				num(math.Min(from.X, to.X)), num(math.Min(from.Y, to.Y)),
+				num(math.Abs(to.X-from.X)), num(math.Abs(to.Y-from.Y)),
+				fill, stroke,
+			)
+		case op.Shape == ShapeEllipse:
+			from, to := op.Points[0], op.Points[1]
+			fmt.Fprintf(
+				&b, `<ellipse cx="%s" cy="%s" rx="%s" ry="%s" fill="%s" %s/>`+"\n",
+				num((from.X+to.X)/2), num((from.Y+to.Y)/2),
+				num(math.Abs(to.X-from.X)/2), num(math.Abs(to.Y-from.Y)/2),
+				fill, stroke,
+			)
+		case op.Shape == ShapeLine:
+			from, to := op.Points[0], op.Points[1]
+			fmt.Fprintf(
+				&b, `<line x1="%s" y1="%s" x2="%s" y2="%s" %s/>`+"\n",
+				num(from.X), num(from.Y), num(to.X), num(to.Y), stroke,
+			)
+		}
+	}
+
+	b.WriteString("</svg>\n")
+	return b.Bytes()
+}
+
+func num(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
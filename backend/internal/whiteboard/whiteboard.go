@@ -0,0 +1,184 @@
+// Package whiteboard keeps the drawings on a meeting's shared whiteboard
+package whiteboard
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// Boards are drawn in this coordinate space, clients scale it to their canvas
+const (
+	Width  = 1920
+	Height = 1080
+)
+
+const (
+	maxElements    = 5000
+	maxPoints      = 5000  // Of a stroke
+	maxBoardPoints = 50000 // Of every element on the board, bounds the snapshot sent to late joiners
+	maxSize        = 100
+	maxElementId   = 64
+)
+
+var (
+	ErrInvalidOp = errors.New("invalid whiteboard operation")
+	ErrBoardFull = errors.New("whiteboard is full")
+)
+
+type OpType string
+
+const (
+	OpStroke OpType = "stroke"
+	OpShape  OpType = "shape"
+	OpErase  OpType = "erase"
+	OpClear  OpType = "clear"
+)
+
+type ShapeKind string
+
+const (
+	ShapeRect    ShapeKind = "rect"
+	ShapeEllipse ShapeKind = "ellipse"
+	ShapeLine    ShapeKind = "line"
+)
+
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Op changes the board. Strokes and shapes add an element, erase removes
+// elements and clear removes every element.
+type Op struct {
+	Seq        uint64    `json:"seq"` // Assigned by the server in the order ops are applied
+	Type       OpType    `json:"type"`
+	ClientId   string    `json:"clientId,omitempty"`   // Who applied the op
+	ElementId  string    `json:"elementId,omitempty"`  // Chosen by the client for strokes and shapes
+	Shape      ShapeKind `json:"shape,omitempty"`      // "rect" | "ellipse" | "line"
+	Points     []Point   `json:"points,omitempty"`     // A stroke's path, or a shape's opposite corners or ends
+	Color      string    `json:"color,omitempty"`      // #rgb or #rrggbb
+	Fill       string    `json:"fill,omitempty"`       // Shapes only, unfilled when empty
+	Size       float64   `json:"size,omitempty"`       // Line width
+	ElementIds []string  `json:"elementIds,omitempty"` // Erased elements
+}
+
+// Snapshot is the state of a board, the ops of the elements still on it in
+// the order they were drawn
+type Snapshot struct {
+	Seq uint64 `json:"seq"` // Of the last op applied
+	Ops []Op   `json:"ops"`
+}
+
+// Board applies ops in the order they arrive. Rather than every op, it keeps
+// the elements still on the board, so late joiners only replay what's
+// visible. It's not safe for concurrent use.
+type Board struct {
+	seq      uint64
+	elements []Op
+	points   int // Of the elements
+}
+
+// Apply validates the op and applies it, it returns the op as applied,
+// numbered and stripped of fields its type doesn't use
+func (b *Board) Apply(op Op) (Op, error) {
+	op, err := normalize(op)
+	if err != nil {
+		return Op{}, err
+	}
+	op.Seq = b.seq + 1
+
+	switch op.Type {
+	case OpStroke, OpShape:
+		if len(b.elements) >= maxElements || b.points+len(op.Points) > maxBoardPoints {
+			return Op{}, ErrBoardFull
+		}
+		if slices.ContainsFunc(b.elements, func(element Op) bool { return element.ElementId == op.ElementId }) {
+			return Op{}, fmt.Errorf("%w: element %s already exists", ErrInvalidOp, op.ElementId)
+		}
+		b.elements = append(b.elements, op)
+		b.points += len(op.Points)
+	case OpErase:
+		// Erasing what someone else already erased isn't an error
+		b.elements = slices.DeleteFunc(b.elements, func(element Op) bool {
+			if slices.Contains(op.ElementIds, element.ElementId) {
+				b.points -= len(element.Points)
+				return true
+			}
+			return false
+		})
+	case OpClear:
+		b.elements = nil
+		b.points = 0
+	}
+
+	b.seq = op.Seq
+	return op, nil
+}
+
+func (b *Board) Snapshot() Snapshot {
+	return Snapshot{
+		Seq: b.seq,
+		Ops: slices.Clone(b.elements),
+	}
+}
+
+// Empty reports whether nothing is on the board
+func (b *Board) Empty() bool {
+	return len(b.elements) == 0
+}
+
+var colorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// normalize checks the fields the op's type uses and drops the others
+func normalize(op Op) (Op, error) {
+	switch op.Type {
+	case OpStroke:
+		if len(op.Points) == 0 || len(op.Points) > maxPoints {
+			return Op{}, fmt.Errorf("%w: strokes need between 1 and %d points", ErrInvalidOp, maxPoints)
+		}
+		return drawing(op, Op{Type: op.Type, ElementId: op.ElementId, Points: op.Points, Color: op.Color, Size: op.Size})
+	case OpShape:
+		if op.Shape != ShapeRect && op.Shape != ShapeEllipse && op.Shape != ShapeLine {
+			return Op{}, fmt.Errorf("%w: shapes are rect, ellipse or line", ErrInvalidOp)
+		}
+		if len(op.Points) != 2 {
+			return Op{}, fmt.Errorf("%w: shapes need 2 points", ErrInvalidOp)
+		}
+		if op.Fill != "" && !colorPattern.MatchString(op.Fill) {
+			return Op{}, fmt.Errorf("%w: fill must be a hex color", ErrInvalidOp)
+		}
+		return drawing(op, Op{Type: op.Type, ElementId: op.ElementId, Shape: op.Shape, Points: op.Points, Color: op.Color, Fill: op.Fill, Size: op.Size})
+	case OpErase:
+		if len(op.ElementIds) == 0 || len(op.ElementIds) > maxElements {
+			return Op{}, fmt.Errorf("%w: erase between 1 and %d elements", ErrInvalidOp, maxElements)
+		}
+		return Op{Type: op.Type, ClientId: op.ClientId, ElementIds: op.ElementIds}, nil
+	case OpClear:
+		return Op{Type: op.Type, ClientId: op.ClientId}, nil
+	default:
+		return Op{}, fmt.Errorf("%w: unknown type %q", ErrInvalidOp, op.Type)
+	}
+}
+
+// drawing checks what strokes and shapes have in common
+func drawing(op Op, normalized Op) (Op, error) {
+	if op.ElementId == "" || len(op.ElementId) > maxElementId {
+		return Op{}, fmt.Errorf("%w: element IDs must have between 1 and %d characters", ErrInvalidOp, maxElementId)
+	}
+	if !colorPattern.MatchString(op.Color) {
+		return Op{}, fmt.Errorf("%w: color must be a hex color", ErrInvalidOp)
+	}
+	if !(op.Size > 0 && op.Size <= maxSize) {
+		return Op{}, fmt.Errorf("%w: size must be between 0 and %d", ErrInvalidOp, maxSize)
+	}
+	for _, point := range op.Points {
+		if !(point.X >= 0 && point.X <= Width && point.Y >= 0 && point.Y <= Height) {
+			return Op{}, fmt.Errorf("%w: points must lie within %dx%d", ErrInvalidOp, Width, Height)
+		}
+	}
+
+	normalized.ClientId = op.ClientId
+	return normalized, nil
+}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AttachmentTestSuite struct {
+	TestSuite
+}
+
+func TestAttachmentTestSuite(t *testing.T) {
+	suite.Run(t, new(AttachmentTestSuite))
+}
+
+var testPDF = []byte("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\ntrailer << /Root 1 0 R >>\n%%EOF\n")
+
+// uploadAttachment posts a file to the room as a multipart form
+func (suite *AttachmentTestSuite) uploadAttachment(roomID uint, fileName string, content []byte, token string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", fileName)
+	suite.Require().NoError(err)
+	_, err = part.Write(content)
+	suite.Require().NoError(err)
+	suite.Require().NoError(writer.Close())
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("/rooms/%d/attachments", roomID), &body)
+	suite.Require().NoError(err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+// downloadURL requests a signed download link for the attachment
+func (suite *AttachmentTestSuite) downloadURL(roomID uint, attachmentID interface{}, token string) (int, string) {
+	w, err := suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/attachments/%v", roomID, attachmentID), nil, token)
+	suite.Require().NoError(err)
+	if w.Code != http.StatusOK {
+		return w.Code, ""
+	}
+
+	var response map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response["data"].(map[string]interface{})["url"].(string)
+}
+
+func (suite *AttachmentTestSuite) download(url string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("GET", url, nil)
+	suite.Require().NoError(err)
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+// Test: Participants upload attachments and download them through signed links
+func (suite *AttachmentTestSuite) TestUploadAndDownload() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	outsider := suite.createTestUser("outsider", "outsider@example.com", "password123")
+	outsiderToken := suite.loginTestUser(outsider.Email, "password123")
+
+	w := suite.uploadAttachment(room.ID, "../notes.pdf", testPDF, ownerToken)
+	suite.Equal(http.StatusCreated, w.Code)
+
+	var response map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+	suite.Equal("notes.pdf", data["file_name"])
+	suite.Equal("application/pdf", data["content_type"])
+	suite.Equal(float64(len(testPDF)), data["size"])
+
+	// Outsiders can neither upload nor get a link
+	w = suite.uploadAttachment(room.ID, "notes.pdf", testPDF, outsiderToken)
+	suite.Equal(http.StatusNotFound, w.Code)
+
+	status, _ := suite.downloadURL(room.ID, data["id"], outsiderToken)
+	suite.Equal(http.StatusNotFound, status)
+
+	status, url := suite.downloadURL(room.ID, data["id"], memberToken)
+	suite.Require().Equal(http.StatusOK, status)
+
+	w = suite.download(url)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal(testPDF, w.Body.Bytes())
+	suite.Equal("application/pdf", w.Header().Get("Content-Type"))
+	suite.Contains(w.Header().Get("Content-Disposition"), "notes.pdf")
+
+	// Links can't be reused for another user
+	tampered := strings.Replace(url, fmt.Sprintf("user=%d", member.ID), fmt.Sprintf("user=%d", outsider.ID), 1)
+	suite.Equal(http.StatusForbidden, suite.download(tampered).Code)
+
+	// Links stop working once the user leaves the room
+	w, err := suite.makeRequest("POST", fmt.Sprintf("/rooms/%d/leave", room.ID), nil, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal(http.StatusForbidden, suite.download(url).Code)
+}
+
+// Test: Uploads are limited in size and type
+func (suite *AttachmentTestSuite) TestUploadLimits() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	// The type is sniffed from the contents, not taken from the file name
+	w := suite.uploadAttachment(room.ID, "notes.pdf", []byte("<html><body>hi</body></html>"), ownerToken)
+	suite.Equal(http.StatusUnsupportedMediaType, w.Code)
+
+	w = suite.uploadAttachment(room.ID, "big.txt", bytes.Repeat([]byte("a"), 2<<20), ownerToken)
+	suite.Equal(http.StatusRequestEntityTooLarge, w.Code)
+
+	w = suite.uploadAttachment(room.ID, "notes.txt", []byte("plain notes"), ownerToken)
+	suite.Equal(http.StatusCreated, w.Code)
+}
+
+// Test: Chat messages can reference attachments uploaded to the room
+func (suite *AttachmentTestSuite) TestChatMessageWithAttachment() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	w := suite.uploadAttachment(room.ID, "notes.pdf", testPDF, ownerToken)
+	suite.Require().Equal(http.StatusCreated, w.Code)
+
+	var response map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	attachmentID := response["data"].(map[string]interface{})["id"]
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+
+	// Only the uploader may share the attachment
+	suite.sendMessage(memberConn, "data", map[string]interface{}{"message": "mine", "attachmentId": attachmentID})
+	suite.Equal("not_found", suite.readMessage(memberConn, "error")["code"])
+
+	// The body is optional when sharing a file
+	suite.sendMessage(ownerConn, "data", map[string]interface{}{"attachmentId": attachmentID})
+	message := suite.readMessage(memberConn, "data")
+	suite.Empty(message["message"])
+	attachment := message["attachment"].(map[string]interface{})
+	suite.Equal(attachmentID, attachment["id"])
+	suite.Equal("notes.pdf", attachment["fileName"])
+
+	w, err := suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/messages", room.ID), nil, memberToken)
+	suite.NoError(err)
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	messages := response["data"].([]interface{})
+	suite.Len(messages, 1)
+	suite.Equal("application/pdf", messages[0].(map[string]interface{})["attachment"].(map[string]interface{})["content_type"])
+}
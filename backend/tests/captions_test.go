@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/serozhenka/shary/internal/transcription"
+	"github.com/stretchr/testify/suite"
+)
+
+type CaptionsTestSuite struct {
+	TestSuite
+}
+
+func TestCaptionsTestSuite(t *testing.T) {
+	suite.Run(t, new(CaptionsTestSuite))
+}
+
+// Test: Transcripts are formatted as SubRip and WebVTT subtitles
+func (suite *CaptionsTestSuite) TestFormatsSubtitles() {
+	cues := []transcription.Cue{
+		{Start: 1500 * time.Millisecond, End: 3 * time.Second, Speaker: "alice", Text: "Hello everyone"},
+		{Start: time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond, End: time.Hour + 2*time.Minute + 5*time.Second, Speaker: "bob", Text: "Fish & <chips>"},
+	}
+
+	suite.Equal(
+		"1\n00:00:01,500 --> 00:00:03,000\nalice: Hello everyone\n\n"+
+			"2\n01:02:03,045 --> 01:02:05,000\nbob: Fish & <chips>\n\n",
+		transcription.SRT(cues),
+	)
+	suite.Equal(
+		"WEBVTT\n\n"+
+			"00:00:01.500 --> 00:00:03.000\n<v alice>Hello everyone\n\n"+
+			"01:02:03.045 --> 01:02:05.000\n<v bob>Fish &amp; &lt;chips&gt;\n\n",
+		transcription.WebVTT(cues),
+	)
+	suite.Equal("WEBVTT\n\n", transcription.WebVTT(nil))
+}
+
+// Test: Uploaded audio is captioned for everyone and kept in the session's transcript
+func (suite *CaptionsTestSuite) TestCaptionsUploadedAudio() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	outsider := suite.createTestUser("outsider", "outsider@example.com", "password123")
+	outsiderToken := suite.loginTestUser(outsider.Email, "password123")
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	memberClientID := suite.readMessage(ownerConn, "client_joined")["clientId"]
+
+	suite.sendMessage(memberConn, "captionAudio", map[string]interface{}{"mimeType": "audio/webm", "data": []byte{}})
+	suite.Equal("invalid_audio", suite.readMessage(memberConn, "error")["code"])
+
+	// The fake transcriber recognises text as speech, []byte is sent as base64
+	suite.sendMessage(memberConn, "captionAudio", map[string]interface{}{"mimeType": "audio/webm", "data": []byte("Hello every...")})
+	caption := suite.readMessage(ownerConn, "caption")
+	suite.Equal(memberClientID, caption["clientId"])
+	suite.Equal("member", caption["username"])
+	suite.Equal("Hello every", caption["text"])
+	suite.Equal(false, caption["final"])
+
+	suite.sendMessage(memberConn, "captionAudio", map[string]interface{}{"mimeType": "audio/webm", "data": []byte("Hello everyone")})
+	caption = suite.readMessage(ownerConn, "caption")
+	suite.Equal("Hello everyone", caption["text"])
+	suite.Equal(true, caption["final"])
+	// Speakers see their own captions too
+	suite.Equal(false, suite.readMessage(memberConn, "caption")["final"])
+	suite.Equal(true, suite.readMessage(memberConn, "caption")["final"])
+
+	suite.sendMessage(ownerConn, "captionAudio", map[string]interface{}{"mimeType": "audio/webm", "data": []byte("Welcome <b>")})
+	suite.Equal("Welcome <b>", suite.readMessage(memberConn, "caption")["text"])
+
+	memberConn.Close()
+	suite.readMessage(ownerConn, "client_left")
+	ownerConn.Close()
+
+	var sessionID interface{}
+	suite.Eventually(func() bool {
+		w, err := suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/sessions", room.ID), nil, memberToken)
+		suite.Require().NoError(err)
+
+		var response struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+		if len(response.Data) != 1 || response.Data[0]["ended_at"] == nil {
+			return false
+		}
+		sessionID = response.Data[0]["id"]
+		return true
+	}, 2*time.Second, 20*time.Millisecond)
+
+	transcriptURL := fmt.Sprintf("/rooms/%d/sessions/%v/transcript", room.ID, sessionID)
+	w, err := suite.makeRequest("GET", transcriptURL, nil, memberToken)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	var response struct {
+		Data []struct {
+			UserID  uint   `json:"user_id"`
+			Speaker string `json:"speaker"`
+			Text    string `json:"text"`
+		} `json:"data"`
+	}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Require().Len(response.Data, 2)
+	suite.Equal(member.ID, response.Data[0].UserID)
+	suite.Equal("member", response.Data[0].Speaker)
+	suite.Equal("Hello everyone", response.Data[0].Text)
+	suite.Equal("owner", response.Data[1].Speaker)
+
+	w, err = suite.makeRequest("GET", transcriptURL+"?format=srt", nil, memberToken)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Header().Get("Content-Type"), "application/x-subrip")
+	suite.Regexp(`^1\n00:00:\d\d,\d{3} --> 00:00:\d\d,\d{3}\nmember: Hello everyone\n\n2\n`, w.Body.String())
+
+	w, err = suite.makeRequest("GET", transcriptURL+"?format=vtt", nil, memberToken)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Header().Get("Content-Type"), "text/vtt")
+	suite.Regexp(`^WEBVTT\n\n00:00:\d\d\.\d{3} --> 00:00:\d\d\.\d{3}\n<v member>Hello everyone\n\n`, w.Body.String())
+	suite.Contains(w.Body.String(), "<v owner>Welcome &lt;b&gt;")
+
+	w, err = suite.makeRequest("GET", transcriptURL+"?format=pdf", nil, memberToken)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, w.Code)
+
+	w, err = suite.makeRequest("GET", transcriptURL, nil, outsiderToken)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
+// Test: Audio forwarded by the SFU is captioned
+func (suite *CaptionsTestSuite) TestCaptionsForwardedAudio() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+	suite.enableSFU(room.ID, ownerToken)
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, init := suite.joinMeeting(memberToken, room.ID)
+	ownerClientID := init["clients"].([]interface{})[0].(map[string]interface{})["id"]
+	suite.readMessage(ownerConn, "client_joined")
+
+	publisher := newSFUTestPeer(suite.T(), ownerConn)
+	publisher.publishFrames("owner-media", map[string][]byte{
+		webrtc.MimeTypeOpus: []byte("Can everyone hear me"),
+	})
+
+	// Negotiating with the SFU takes longer than readMessage waits
+	memberConn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	defer memberConn.SetReadDeadline(time.Time{})
+	for {
+		var message struct {
+			Type    string                 `json:"type"`
+			Payload map[string]interface{} `json:"payload"`
+		}
+		suite.Require().NoError(memberConn.ReadJSON(&message), "waiting for 'caption' message")
+		if message.Type != "caption" {
+			continue
+		}
+
+		suite.Equal(ownerClientID, message.Payload["clientId"])
+		suite.Equal("Can everyone hear me", message.Payload["text"])
+		break
+	}
+}
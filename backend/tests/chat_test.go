@@ -0,0 +1,438 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/serozhenka/shary/internal/models"
+	"github.com/stretchr/testify/suite"
+)
+
+type ChatTestSuite struct {
+	TestSuite
+}
+
+func TestChatTestSuite(t *testing.T) {
+	suite.Run(t, new(ChatTestSuite))
+}
+
+// Test: Chat messages are persisted and replayed to late joiners
+func (suite *ChatTestSuite) TestChatHistoryForLateJoiners() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	suite.Empty(suite.readMessage(ownerConn, "chatHistory")["messages"])
+
+	suite.sendMessage(ownerConn, "data", map[string]interface{}{"message": "first"})
+	first := suite.readMessage(ownerConn, "data")
+	suite.NotZero(first["id"])
+	suite.NotEmpty(first["createdAt"])
+	suite.Equal("owner", first["username"])
+
+	suite.sendMessage(ownerConn, "data", map[string]interface{}{"message": "reply", "replyTo": first["id"]})
+	reply := suite.readMessage(ownerConn, "data")
+	suite.Equal(first["id"], reply["replyTo"])
+
+	// Replies must reference a message of the same room
+	suite.sendMessage(ownerConn, "data", map[string]interface{}{"message": "reply", "replyTo": 999})
+	suite.Equal("not_found", suite.readMessage(ownerConn, "error")["code"])
+
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	history := suite.readMessage(memberConn, "chatHistory")["messages"].([]interface{})
+	suite.Len(history, 2)
+	suite.Equal("first", history[0].(map[string]interface{})["message"])
+	suite.Equal("reply", history[1].(map[string]interface{})["message"])
+}
+
+// Test: Chat history is paginated with a cursor
+func (suite *ChatTestSuite) TestListingMessages() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	outsider := suite.createTestUser("outsider", "outsider@example.com", "password123")
+	outsiderToken := suite.loginTestUser(outsider.Email, "password123")
+
+	for i := 1; i <= 5; i++ {
+		_, err := suite.chatRepo.CreateMessage(models.ChatMessage{
+			RoomID:   room.ID,
+			AuthorID: owner.ID,
+			Body:     fmt.Sprintf("message %d", i),
+		})
+		suite.Require().NoError(err)
+	}
+
+	url := fmt.Sprintf("/rooms/%d/messages?limit=3", room.ID)
+	w, err := suite.makeRequest("GET", url, nil, ownerToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	suite.NoError(err)
+
+	data := response["data"].([]interface{})
+	suite.Len(data, 3)
+	suite.Equal("message 3", data[0].(map[string]interface{})["body"])
+	suite.Equal("message 5", data[2].(map[string]interface{})["body"])
+	suite.Equal("3", response["next_cursor"])
+
+	url = fmt.Sprintf("/rooms/%d/messages?limit=3&before=%s", room.ID, response["next_cursor"])
+	w, err = suite.makeRequest("GET", url, nil, ownerToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	suite.NoError(err)
+
+	data = response["data"].([]interface{})
+	suite.Len(data, 2)
+	suite.Equal("message 1", data[0].(map[string]interface{})["body"])
+	suite.Nil(response["next_cursor"])
+
+	// Non-participants can't read the history
+	w, err = suite.makeRequest("GET", url, nil, outsiderToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
+// Test: Messages can be edited by their author, deleted and reacted to over WebSocket
+func (suite *ChatTestSuite) TestEditingDeletingAndReacting() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+
+	suite.sendMessage(memberConn, "data", map[string]interface{}{"message": "helo"})
+	id := suite.readMessage(memberConn, "data")["id"]
+
+	// Only the author may edit
+	suite.sendMessage(ownerConn, "chatEdit", map[string]interface{}{"messageId": id, "message": "hijacked"})
+	suite.Equal("forbidden", suite.readMessage(ownerConn, "error")["code"])
+
+	suite.sendMessage(memberConn, "chatEdit", map[string]interface{}{"messageId": id, "message": "hello"})
+	edited := suite.readMessage(ownerConn, "chatEdited")
+	suite.Equal("hello", edited["message"])
+	suite.NotEmpty(edited["editedAt"])
+
+	suite.sendMessage(ownerConn, "chatReact", map[string]interface{}{"messageId": id, "emoji": "👍"})
+	reacted := suite.readMessage(memberConn, "chatReacted")
+	reactions := reacted["reactions"].([]interface{})
+	suite.Len(reactions, 1)
+	suite.Equal("👍", reactions[0].(map[string]interface{})["emoji"])
+	suite.Equal([]interface{}{float64(owner.ID)}, reactions[0].(map[string]interface{})["userIds"])
+
+	// Moderators may delete someone else's message, which keeps a tombstone
+	suite.sendMessage(ownerConn, "chatDelete", map[string]interface{}{"messageId": id})
+	deleted := suite.readMessage(memberConn, "chatDeleted")
+	suite.Equal(true, deleted["deleted"])
+	suite.Empty(deleted["message"])
+
+	suite.sendMessage(memberConn, "chatEdit", map[string]interface{}{"messageId": id, "message": "again"})
+	suite.Equal("not_found", suite.readMessage(memberConn, "error")["code"])
+
+	message, err := suite.chatRepo.GetMessage(uint(id.(float64)))
+	suite.Require().NoError(err)
+	suite.True(message.IsDeleted())
+}
+
+// Test: Messages are limited in characters rather than bytes
+func (suite *ChatTestSuite) TestMessageLengthCountsCharacters() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+
+	suite.sendMessage(ownerConn, "data", map[string]interface{}{"message": strings.Repeat("é", 4000)})
+	suite.Equal(strings.Repeat("é", 4000), suite.readMessage(ownerConn, "data")["message"])
+
+	suite.sendMessage(ownerConn, "data", map[string]interface{}{"message": strings.Repeat("é", 4001)})
+	suite.Equal("invalid_message", suite.readMessage(ownerConn, "error")["code"])
+}
+
+// Test: Chat messages can be edited, reacted to and deleted over REST
+func (suite *ChatTestSuite) TestChatRestEndpoints() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	message, err := suite.chatRepo.CreateMessage(models.ChatMessage{
+		RoomID:   room.ID,
+		AuthorID: owner.ID,
+		Body:     "helo",
+	})
+	suite.Require().NoError(err)
+
+	url := fmt.Sprintf("/rooms/%d/messages/%d", room.ID, message.ID)
+
+	w, err := suite.makeRequest("PUT", url, map[string]string{"body": "hello"}, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusForbidden, w.Code)
+
+	w, err = suite.makeRequest("PUT", url, map[string]string{"body": "hello"}, ownerToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	suite.NoError(err)
+	suite.Equal("hello", response["data"].(map[string]interface{})["body"])
+
+	w, err = suite.makeRequest("PUT", url+"/reactions/🎉", nil, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	suite.NoError(err)
+	suite.Len(response["data"].(map[string]interface{})["reactions"], 1)
+
+	w, err = suite.makeRequest("DELETE", url+"/reactions/🎉", nil, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	suite.NoError(err)
+	suite.Empty(response["data"].(map[string]interface{})["reactions"])
+
+	// Members can't delete other people's messages
+	w, err = suite.makeRequest("DELETE", url, nil, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusForbidden, w.Code)
+
+	w, err = suite.makeRequest("DELETE", url, nil, ownerToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	w, err = suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/messages", room.ID), nil, ownerToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	suite.NoError(err)
+	data := response["data"].([]interface{})
+	suite.Len(data, 1)
+	suite.Equal(true, data[0].(map[string]interface{})["deleted"])
+	suite.Empty(data[0].(map[string]interface{})["body"])
+}
+
+// Test: Authors can't edit their messages once removed from the room
+func (suite *ChatTestSuite) TestEditingRequiresParticipation() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	message, err := suite.chatRepo.CreateMessage(models.ChatMessage{
+		RoomID:   room.ID,
+		AuthorID: member.ID,
+		Body:     "helo",
+	})
+	suite.Require().NoError(err)
+
+	url := fmt.Sprintf("/rooms/%d/messages/%d", room.ID, message.ID)
+
+	w, err := suite.makeRequest("DELETE", fmt.Sprintf("/rooms/%d/users/%d", room.ID, member.ID), nil, ownerToken)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, w.Code)
+
+	w, err = suite.makeRequest("PUT", url, map[string]string{"body": "hello"}, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusNotFound, w.Code)
+
+	edited, err := suite.chatRepo.GetMessage(message.ID)
+	suite.Require().NoError(err)
+	suite.Equal("helo", edited.Body)
+}
+
+// Test: Changes made over REST reach participants in breakouts
+func (suite *ChatTestSuite) TestRestUpdatesReachBreakouts() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	message, err := suite.chatRepo.CreateMessage(models.ChatMessage{
+		RoomID:   room.ID,
+		AuthorID: owner.ID,
+		Body:     "helo",
+	})
+	suite.Require().NoError(err)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	suite.readMessage(ownerConn, "client_joined")
+
+	suite.sendMessage(ownerConn, "breakoutCreate", map[string]interface{}{"count": 1, "random": true})
+	suite.readMessage(memberConn, "moveToBreakout")
+	suite.readMessage(memberConn, "init")
+
+	url := fmt.Sprintf("/rooms/%d/messages/%d", room.ID, message.ID)
+	w, err := suite.makeRequest("PUT", url, map[string]string{"body": "hello"}, ownerToken)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, w.Code)
+
+	suite.Equal("hello", suite.readMessage(memberConn, "chatEdited")["message"])
+	suite.Equal("hello", suite.readMessage(ownerConn, "chatEdited")["message"])
+}
+
+// Test: Private messages only reach and are only listed for the two users involved
+func (suite *ChatTestSuite) TestPrivateMessages() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	other := suite.createTestUser("other", "other@example.com", "password123")
+	otherToken := suite.loginTestUser(other.Email, "password123")
+	suite.addUserToRoom(room.ID, other.Email)
+
+	outsider := suite.createTestUser("outsider", "outsider@example.com", "password123")
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	otherConn, _ := suite.joinMeeting(otherToken, room.ID)
+	memberClientId := suite.readMessage(ownerConn, "client_joined")["clientId"]
+
+	suite.sendMessage(ownerConn, "data", map[string]interface{}{"message": "psst", "clientId": memberClientId})
+	sent := suite.readMessage(ownerConn, "data")
+	suite.Equal(float64(member.ID), sent["recipientId"])
+
+	received := suite.readMessage(memberConn, "data")
+	suite.Equal("psst", received["message"])
+	suite.Equal(float64(member.ID), received["recipientId"])
+
+	// Targeting by user ID works too, but only for participants of the room
+	suite.sendMessage(memberConn, "data", map[string]interface{}{"message": "hi", "userId": owner.ID})
+	suite.Equal("hi", suite.readMessage(ownerConn, "data")["message"])
+
+	suite.sendMessage(ownerConn, "data", map[string]interface{}{"message": "hi", "userId": outsider.ID})
+	suite.Equal("invalid_recipient", suite.readMessage(ownerConn, "error")["code"])
+
+	// The third participant only sees public messages
+	suite.sendMessage(ownerConn, "data", map[string]interface{}{"message": "public"})
+	suite.Equal("public", suite.readMessage(otherConn, "data")["message"])
+
+	w, err := suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/messages", room.ID), nil, otherToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	suite.NoError(err)
+	suite.Len(response["data"], 1)
+
+	w, err = suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/messages", room.ID), nil, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	suite.NoError(err)
+	suite.Len(response["data"], 3)
+}
+
+// Test: Typing notifications are relayed to others and rate limited
+func (suite *ChatTestSuite) TestTypingIndicators() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+
+	suite.sendMessage(memberConn, "typing", map[string]interface{}{"typing": true})
+	suite.sendMessage(memberConn, "typing", map[string]interface{}{"typing": true})
+	suite.sendMessage(memberConn, "typing", map[string]interface{}{"typing": false})
+
+	typing := suite.readMessage(ownerConn, "typing")
+	suite.Equal(true, typing["typing"])
+	suite.Equal("member", typing["username"])
+
+	// The repeated notification was dropped
+	suite.Equal(false, suite.readMessage(ownerConn, "typing")["typing"])
+}
+
+// Test: Read markers are shared as receipts and drive unread counts
+func (suite *ChatTestSuite) TestReadReceipts() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	var ids []uint
+	for i := 1; i <= 3; i++ {
+		message, err := suite.chatRepo.CreateMessage(models.ChatMessage{
+			RoomID:   room.ID,
+			AuthorID: owner.ID,
+			Body:     fmt.Sprintf("message %d", i),
+		})
+		suite.Require().NoError(err)
+		ids = append(ids, message.ID)
+	}
+
+	unreadCount := func(token string) interface{} {
+		w, err := suite.makeRequest("GET", "/rooms", nil, token)
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+		return response["data"].([]interface{})[0].(map[string]interface{})["unread_count"]
+	}
+
+	// Own messages never count as unread
+	suite.Equal(float64(0), unreadCount(ownerToken))
+	suite.Equal(float64(3), unreadCount(memberToken))
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+
+	suite.sendMessage(memberConn, "chatRead", map[string]interface{}{"messageId": ids[1]})
+	receipt := suite.readMessage(ownerConn, "chatRead")
+	suite.Equal(float64(member.ID), receipt["userId"])
+	suite.Equal(float64(ids[1]), receipt["messageId"])
+	suite.Equal(float64(1), unreadCount(memberToken))
+
+	// Markers never move backwards
+	suite.sendMessage(memberConn, "chatRead", map[string]interface{}{"messageId": ids[0]})
+	suite.readMessage(ownerConn, "chatRead")
+	suite.Equal(float64(1), unreadCount(memberToken))
+
+	suite.sendMessage(memberConn, "chatRead", map[string]interface{}{"messageId": 999})
+	suite.Equal("not_found", suite.readMessage(memberConn, "error")["code"])
+}
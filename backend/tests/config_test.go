@@ -6,24 +6,51 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/serozhenka/shary/internal/http/middlewares"
+	attachmentRoutes "github.com/serozhenka/shary/internal/http/routes/attachments"
 	authRoutes "github.com/serozhenka/shary/internal/http/routes/auth"
+	recordingRoutes "github.com/serozhenka/shary/internal/http/routes/recordings"
 	roomRoutes "github.com/serozhenka/shary/internal/http/routes/rooms"
+	rtcRoutes "github.com/serozhenka/shary/internal/http/routes/rtc"
+	"github.com/serozhenka/shary/internal/http/routes/ws"
 	"github.com/serozhenka/shary/internal/models"
+	"github.com/serozhenka/shary/internal/repository/attachments"
+	"github.com/serozhenka/shary/internal/repository/chat"
+	"github.com/serozhenka/shary/internal/repository/polls"
+	"github.com/serozhenka/shary/internal/repository/recordings"
 	"github.com/serozhenka/shary/internal/repository/rooms"
+	"github.com/serozhenka/shary/internal/repository/sessions"
 	"github.com/serozhenka/shary/internal/repository/users"
 	"github.com/serozhenka/shary/internal/services"
+	"github.com/serozhenka/shary/internal/sfu"
+	"github.com/serozhenka/shary/internal/storage"
+	"github.com/serozhenka/shary/internal/transcription"
 	"github.com/stretchr/testify/suite"
 )
 
+// testTURNSecret is shared with the TURN server in the RTC tests
+const testTURNSecret = "test-turn-secret"
+
 type TestSuite struct {
 	suite.Suite
 	router      *gin.Engine
 	authService *services.AuthService
 	roomRepo    rooms.Repository
 	userRepo    users.Repository
+	chatRepo    chat.Repository
+	chatService *services.ChatService
+	attachments *services.AttachmentService
+	polls       *services.PollService
+	recordings  *services.RecordingService
+	sessions    *services.SessionService
+	ice         *services.ICEService
+	meetings    ws.MeetingManager
+	server      *httptest.Server
 }
 
 func (suite *TestSuite) SetupSuite() {
@@ -32,10 +59,20 @@ func (suite *TestSuite) SetupSuite() {
 
 	// Initialize in-memory repositories
 	suite.userRepo = users.NewInMemoryRepository()
-	suite.roomRepo = rooms.NewInMemoryRepository()
+	suite.roomRepo = rooms.NewInMemoryRepository(suite.userRepo)
+	suite.chatRepo = chat.NewInMemoryRepository(suite.userRepo)
+	suite.meetings = ws.NewInMemoryMeetingManager()
 
 	// Initialize services
 	suite.authService = services.NewAuthService("test-jwt-secret-key-for-testing-only", suite.userRepo)
+	suite.setupAttachments()
+	suite.polls = services.NewPollService(polls.NewInMemoryRepository(), suite.roomRepo)
+	suite.sessions = services.NewSessionService(sessions.NewInMemoryRepository(), suite.roomRepo)
+	suite.ice = services.NewICEService(services.ICEConfig{
+		STUNURLs:   []string{"stun:stun.example.com:3478"},
+		TURNURLs:   []string{"turn:turn.example.com:3478"},
+		TURNSecret: testTURNSecret,
+	})
 
 	// Setup router
 	suite.setupRouter()
@@ -44,17 +81,57 @@ func (suite *TestSuite) SetupSuite() {
 func (suite *TestSuite) SetupTest() {
 	// Clean up by creating fresh repositories before each test
 	suite.userRepo = users.NewInMemoryRepository()
-	suite.roomRepo = rooms.NewInMemoryRepository()
+	suite.roomRepo = rooms.NewInMemoryRepository(suite.userRepo)
+	suite.chatRepo = chat.NewInMemoryRepository(suite.userRepo)
+	suite.meetings = ws.NewInMemoryMeetingManager()
 
 	// Re-initialize auth service with fresh user repository
 	suite.authService = services.NewAuthService("test-jwt-secret-key-for-testing-only", suite.userRepo)
+	suite.setupAttachments()
+	suite.polls = services.NewPollService(polls.NewInMemoryRepository(), suite.roomRepo)
+	suite.sessions = services.NewSessionService(sessions.NewInMemoryRepository(), suite.roomRepo)
+	suite.ice = services.NewICEService(services.ICEConfig{
+		STUNURLs:   []string{"stun:stun.example.com:3478"},
+		TURNURLs:   []string{"turn:turn.example.com:3478"},
+		TURNSecret: testTURNSecret,
+	})
 
 	// Re-setup router with fresh repositories
 	suite.setupRouter()
 }
 
+// setupAttachments creates the chat, attachment and recording services over a
+// local blob store in a temporary directory
+func (suite *TestSuite) setupAttachments() {
+	attachmentsRepo := attachments.NewInMemoryRepository()
+	store, err := storage.NewLocalStore(suite.T().TempDir())
+	suite.Require().NoError(err)
+
+	suite.chatService = services.NewChatService(suite.chatRepo, suite.roomRepo, attachmentsRepo)
+	suite.attachments = services.NewAttachmentService(
+		store,
+		attachmentsRepo,
+		suite.roomRepo,
+		"test-jwt-secret-key-for-testing-only",
+		services.AttachmentLimits{
+			MaxSize:      1 << 20,
+			AllowedTypes: []string{"application/pdf", "image/png", "text/plain"},
+		},
+	)
+	suite.recordings = services.NewRecordingService(
+		store,
+		recordings.NewInMemoryRepository(),
+		suite.roomRepo,
+		"test-jwt-secret-key-for-testing-only",
+	)
+}
+
 func (suite *TestSuite) TearDownTest() {
-	// No cleanup needed for in-memory repositories
+	// Close the WebSocket test server, if one was started
+	if suite.server != nil {
+		suite.server.Close()
+		suite.server = nil
+	}
 }
 
 func (suite *TestSuite) TearDownSuite() {
@@ -77,14 +154,51 @@ func (suite *TestSuite) setupRouter() {
 	protectedAuthGroup.Use(middlewares.AuthMiddleware(suite.authService))
 	authRoutes.SetupProtectedRouter(protectedAuthGroup, authCtx)
 
+	// RTC routes
+	rtcGroup := router.Group("/rtc")
+	rtcGroup.Use(middlewares.AuthMiddleware(suite.authService))
+	rtcRoutes.SetupRouter(rtcGroup, &rtcRoutes.RouterCtx{ICEService: suite.ice})
+
 	// Room routes (all protected)
 	roomGroup := router.Group("/rooms")
 	roomGroup.Use(middlewares.AuthMiddleware(suite.authService))
 	roomCtx := &roomRoutes.RouterCtx{
-		Repo: suite.roomRepo,
+		Repo:              suite.roomRepo,
+		ChatService:       suite.chatService,
+		AttachmentService: suite.attachments,
+		PollService:       suite.polls,
+		RecordingService:  suite.recordings,
+		SessionService:    suite.sessions,
+		MeetingManager:    suite.meetings,
 	}
 	roomRoutes.SetupRouter(roomGroup, roomCtx)
 
+	// Attachment downloads (authorized by signed links)
+	attachmentRoutes.SetupRouter(router.Group("/attachments"), &attachmentRoutes.RouterCtx{
+		AttachmentService: suite.attachments,
+	})
+	recordingRoutes.SetupRouter(router.Group("/recordings"), &recordingRoutes.RouterCtx{
+		RecordingService: suite.recordings,
+	})
+
+	forwarder, err := sfu.New(sfu.Config{})
+	suite.Require().NoError(err)
+
+	// WebSocket route (handles auth via query params)
+	ws.SetupRouter(router.Group("/ws"), &ws.RouterCtx{
+		RoomsRepo:        suite.roomRepo,
+		ChatService:      suite.chatService,
+		PollService:      suite.polls,
+		RecordingService: suite.recordings,
+		SessionService:   suite.sessions,
+		MeetingManager:   suite.meetings,
+		AuthService:      suite.authService,
+		ICEService:       suite.ice,
+		Upgrader:         &websocket.Upgrader{},
+		SFU:              forwarder,
+		Transcriber:      transcription.Fake{},
+	})
+
 	suite.router = router
 }
 
@@ -151,3 +265,75 @@ func (suite *TestSuite) addUserToRoom(roomID uint, userEmail string) {
 	err := suite.roomRepo.AddUserToRoomByStringID(1, fmt.Sprintf("%d", roomID), userEmail)
 	suite.Require().NoError(err)
 }
+
+// connectToMeeting opens a WebSocket connection to the room's meeting
+func (suite *TestSuite) connectToMeeting(token string, roomID uint) (*websocket.Conn, *http.Response, error) {
+	if suite.server == nil {
+		suite.server = httptest.NewServer(suite.router)
+	}
+
+	url := fmt.Sprintf(
+		"ws%s/ws?token=%s&roomId=%d",
+		strings.TrimPrefix(suite.server.URL, "http"), token, roomID,
+	)
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		suite.T().Cleanup(func() { conn.Close() })
+	}
+	return conn, resp, err
+}
+
+// joinMeeting connects to the room's meeting and consumes the init message
+func (suite *TestSuite) joinMeeting(token string, roomID uint) (*websocket.Conn, map[string]interface{}) {
+	conn, _, err := suite.connectToMeeting(token, roomID)
+	suite.Require().NoError(err)
+	return conn, suite.readMessage(conn, "init")
+}
+
+func (suite *TestSuite) sendMessage(conn *websocket.Conn, messageType string, payload interface{}) {
+	err := conn.WriteJSON(map[string]interface{}{
+		"type":    messageType,
+		"payload": payload,
+	})
+	suite.Require().NoError(err)
+}
+
+// readMessage skips incoming messages until one of the given type arrives
+func (suite *TestSuite) readMessage(conn *websocket.Conn, messageType string) map[string]interface{} {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+
+	for {
+		var message struct {
+			Type    string                 `json:"type"`
+			Payload map[string]interface{} `json:"payload"`
+		}
+		err := conn.ReadJSON(&message)
+		suite.Require().NoError(err, "waiting for '%s' message", messageType)
+
+		if message.Type == messageType {
+			return message.Payload
+		}
+	}
+}
+
+// messageTypesUntil reads messages until one of the given type arrives and
+// returns the types of the messages that came before it
+func (suite *TestSuite) messageTypesUntil(conn *websocket.Conn, messageType string) ([]string, map[string]interface{}) {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+
+	var types []string
+	for {
+		var message struct {
+			Type    string                 `json:"type"`
+			Payload map[string]interface{} `json:"payload"`
+		}
+		suite.Require().NoError(conn.ReadJSON(&message), "waiting for '%s' message", messageType)
+
+		if message.Type == messageType {
+			return types, message.Payload
+		}
+		types = append(types, message.Type)
+	}
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/serozhenka/shary/internal/sfu"
+	"github.com/stretchr/testify/suite"
+)
+
+type LayersTestSuite struct {
+	TestSuite
+}
+
+func TestLayersTestSuite(t *testing.T) {
+	suite.Run(t, new(LayersTestSuite))
+}
+
+// viewport subscribes to the given peers, rendered at the given height
+func viewport(height int, clientIDs ...interface{}) map[string]interface{} {
+	subscriptions := []map[string]interface{}{}
+	for _, clientID := range clientIDs {
+		subscriptions = append(subscriptions, map[string]interface{}{
+			"clientId": clientID,
+			"width":    height * 16 / 9,
+			"height":   height,
+		})
+	}
+	return map[string]interface{}{"subscriptions": subscriptions}
+}
+
+// Test: Publishers learn at which layer each peer renders them
+func (suite *LayersTestSuite) TestRelaysLayerPreferences() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	other := suite.createTestUser("other", "other@example.com", "password123")
+	otherToken := suite.loginTestUser(other.Email, "password123")
+	suite.addUserToRoom(room.ID, other.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, init := suite.joinMeeting(memberToken, room.ID)
+	ownerClientID := init["clients"].([]interface{})[0].(map[string]interface{})["id"]
+	memberClientID := suite.readMessage(ownerConn, "client_joined")["clientId"]
+	otherConn, _ := suite.joinMeeting(otherToken, room.ID)
+	suite.readMessage(memberConn, "client_joined")
+
+	suite.sendMessage(memberConn, "viewportSubscription", map[string]interface{}{
+		"subscriptions": []map[string]interface{}{{"clientId": ownerClientID, "width": 320, "height": -1}},
+	})
+	suite.Equal("invalid_viewport", suite.readMessage(memberConn, "error")["code"])
+
+	// The owner is rendered as a thumbnail, the other member not at all
+	suite.sendMessage(memberConn, "viewportSubscription", viewport(180, ownerClientID))
+	preference := suite.readMessage(ownerConn, "layerPreference")
+	suite.Equal(memberClientID, preference["clientId"])
+	suite.Equal("low", preference["layer"])
+	suite.Equal(float64(180), preference["maxHeight"])
+	preference = suite.readMessage(otherConn, "layerPreference")
+	suite.Equal(memberClientID, preference["clientId"])
+	suite.Equal("off", preference["layer"])
+	suite.Equal(float64(0), preference["maxHeight"])
+
+	// Unchanged layers aren't sent again
+	suite.sendMessage(memberConn, "viewportSubscription", viewport(180, ownerClientID))
+	suite.sendMessage(memberConn, "viewportSubscription", viewport(720, ownerClientID))
+	preference = suite.readMessage(ownerConn, "layerPreference")
+	suite.Equal("high", preference["layer"])
+	suite.Equal(float64(720), preference["maxHeight"])
+}
+
+// Test: The SFU stops forwarding video nobody renders and hints the publisher
+func (suite *LayersTestSuite) TestSFUPausesHiddenVideo() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+	suite.enableSFU(room.ID, ownerToken)
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, init := suite.joinMeeting(memberToken, room.ID)
+	ownerClientID := init["clients"].([]interface{})[0].(map[string]interface{})["id"]
+	suite.readMessage(ownerConn, "client_joined")
+
+	publisher := newSFUTestPeer(suite.T(), ownerConn)
+	subscriber := newSFUTestPeer(suite.T(), memberConn)
+	publisher.publish("owner-camera")
+
+	var track *webrtc.TrackRemote
+	select {
+	case track = <-subscriber.tracks:
+	case <-time.After(10 * time.Second):
+		suite.FailNow("track was not forwarded")
+	}
+	track.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err := track.ReadRTP()
+	suite.Require().NoError(err)
+
+	expectLayer := func(layer string) {
+		select {
+		case preference := <-publisher.layers:
+			suite.Equal(sfu.PublisherID, preference["clientId"])
+			suite.Equal(layer, preference["layer"])
+		case <-time.After(5 * time.Second):
+			suite.FailNow("layer preference was not sent")
+		}
+	}
+
+	subscriber.send("viewportSubscription", viewport(0))
+	expectLayer("off")
+
+	// Packets already on their way drain before the track goes quiet
+	paused := false
+	for range 100 {
+		track.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+		if _, _, err := track.ReadRTP(); err != nil {
+			paused = true
+			break
+		}
+	}
+	suite.True(paused, "video kept being forwarded")
+
+	subscriber.send("viewportSubscription", viewport(360, ownerClientID))
+	expectLayer("medium")
+	track.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = track.ReadRTP()
+	suite.NoError(err)
+}
@@ -0,0 +1,555 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/serozhenka/shary/internal/repository/rooms"
+	"github.com/stretchr/testify/suite"
+)
+
+type MeetingTestSuite struct {
+	TestSuite
+}
+
+func TestMeetingTestSuite(t *testing.T) {
+	suite.Run(t, new(MeetingTestSuite))
+}
+
+// Test: Viewers can't publish media
+func (suite *MeetingTestSuite) TestViewerCannotPublish() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	viewer := suite.createTestUser("viewer", "viewer@example.com", "password123")
+	viewerToken := suite.loginTestUser(viewer.Email, "password123")
+	suite.addUserToRoom(room.ID, viewer.Email)
+	_, err := suite.roomRepo.UpdateParticipantRole(owner.ID, room.ID, viewer.ID, "viewer")
+	suite.Require().NoError(err)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	viewerConn, init := suite.joinMeeting(viewerToken, room.ID)
+	suite.Equal("viewer", init["role"])
+
+	suite.sendMessage(viewerConn, "screenShareStarted", map[string]interface{}{})
+	errorPayload := suite.readMessage(viewerConn, "error")
+	suite.Equal("forbidden", errorPayload["code"])
+
+	// Chat is still allowed
+	suite.sendMessage(viewerConn, "data", map[string]interface{}{"message": "hello"})
+	data := suite.readMessage(ownerConn, "data")
+	suite.Equal("hello", data["message"])
+}
+
+// Test: Moderators can mute, stop screen shares and kick participants
+func (suite *MeetingTestSuite) TestModeratorControls() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, init := suite.joinMeeting(memberToken, room.ID)
+	ownerClientID := init["clients"].([]interface{})[0].(map[string]interface{})["id"]
+	memberClientID := suite.readMessage(ownerConn, "client_joined")["clientId"]
+
+	// Members can't moderate
+	suite.sendMessage(memberConn, "kickParticipant", map[string]interface{}{"clientId": ownerClientID})
+	suite.Equal("forbidden", suite.readMessage(memberConn, "error")["code"])
+
+	suite.sendMessage(ownerConn, "muteParticipant", map[string]interface{}{
+		"clientId":  memberClientID,
+		"trackKind": "audio",
+	})
+	mute := suite.readMessage(memberConn, "mute")
+	suite.Equal("audio", mute["trackKind"])
+	suite.Equal(ownerClientID, mute["clientId"])
+
+	suite.sendMessage(ownerConn, "stopScreenShare", map[string]interface{}{"clientId": memberClientID})
+	suite.readMessage(memberConn, "stopScreenShare")
+
+	suite.sendMessage(ownerConn, "kickParticipant", map[string]interface{}{
+		"clientId": memberClientID,
+		"reason":   "disruptive",
+	})
+	kicked := suite.readMessage(memberConn, "kicked")
+	suite.Equal("disruptive", kicked["reason"])
+	suite.Equal(memberClientID, suite.readMessage(ownerConn, "client_left")["clientId"])
+}
+
+// Test: Locked meetings reject new joins except for moderators
+func (suite *MeetingTestSuite) TestLockedMeeting() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	suite.sendMessage(ownerConn, "lockMeeting", map[string]interface{}{"locked": true})
+	suite.True(suite.readMessage(ownerConn, "meetingLocked")["locked"].(bool))
+
+	_, resp, err := suite.connectToMeeting(memberToken, room.ID)
+	suite.Error(err)
+	suite.Equal(http.StatusLocked, resp.StatusCode)
+
+	suite.sendMessage(ownerConn, "lockMeeting", map[string]interface{}{"locked": false})
+	suite.False(suite.readMessage(ownerConn, "meetingLocked")["locked"].(bool))
+
+	_, init := suite.joinMeeting(memberToken, room.ID)
+	suite.False(init["locked"].(bool))
+}
+
+// Test: Lobby makes members wait for a moderator to admit or deny them
+func (suite *MeetingTestSuite) TestLobby() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	lobbyEnabled := true
+	_, err := suite.roomRepo.UpdateRoomSettings(owner.ID, room.ID, rooms.RoomSettings{LobbyEnabled: &lobbyEnabled})
+	suite.Require().NoError(err)
+
+	// Moderators skip the lobby
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+
+	memberConn, _, err := suite.connectToMeeting(memberToken, room.ID)
+	suite.Require().NoError(err)
+	suite.Equal("waiting", suite.readMessage(memberConn, "lobbyStatus")["status"])
+
+	request := suite.readMessage(ownerConn, "lobbyJoinRequest")
+	suite.Equal("member", request["username"])
+
+	// Waiting clients can't talk to the meeting
+	suite.sendMessage(memberConn, "data", map[string]interface{}{"message": "let me in"})
+	suite.Equal("lobby", suite.readMessage(memberConn, "error")["code"])
+
+	suite.sendMessage(ownerConn, "admit", map[string]interface{}{"clientId": request["clientId"]})
+	init := suite.readMessage(memberConn, "init")
+	suite.Len(init["clients"], 1)
+	suite.Equal("admitted", suite.readMessage(memberConn, "lobbyStatus")["status"])
+	suite.Equal(request["clientId"], suite.readMessage(ownerConn, "client_joined")["clientId"])
+
+	// A second attempt gets denied
+	deniedConn, _, err := suite.connectToMeeting(memberToken, room.ID)
+	suite.Require().NoError(err)
+	request = suite.readMessage(ownerConn, "lobbyJoinRequest")
+
+	suite.sendMessage(ownerConn, "deny", map[string]interface{}{"clientId": request["clientId"], "reason": "duplicate"})
+	denied := suite.readMessage(deniedConn, "lobbyStatus")
+	suite.Equal("waiting", denied["status"])
+	denied = suite.readMessage(deniedConn, "lobbyStatus")
+	suite.Equal("denied", denied["status"])
+	suite.Equal("duplicate", denied["reason"])
+	suite.Equal("denied", suite.readMessage(ownerConn, "lobbyJoinResolved")["status"])
+}
+
+// Test: Full meetings reject publishers or let them in as viewers
+func (suite *MeetingTestSuite) TestMeetingCapacity() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	maxParticipants := 1
+	_, err := suite.roomRepo.UpdateRoomSettings(owner.ID, room.ID, rooms.RoomSettings{MaxParticipants: &maxParticipants})
+	suite.Require().NoError(err)
+
+	suite.joinMeeting(ownerToken, room.ID)
+
+	memberConn, _, err := suite.connectToMeeting(memberToken, room.ID)
+	suite.Require().NoError(err)
+	suite.Equal("room_full", suite.readMessage(memberConn, "error")["code"])
+
+	overflowAsViewers := true
+	_, err = suite.roomRepo.UpdateRoomSettings(owner.ID, room.ID, rooms.RoomSettings{OverflowAsViewers: &overflowAsViewers})
+	suite.Require().NoError(err)
+
+	_, init := suite.joinMeeting(memberToken, room.ID)
+	suite.Equal("viewer", init["role"])
+}
+
+// Test: Participants queue up to speak and moderators manage the queue
+func (suite *MeetingTestSuite) TestHandQueue() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	alice := suite.createTestUser("alice", "alice@example.com", "password123")
+	aliceToken := suite.loginTestUser(alice.Email, "password123")
+	suite.addUserToRoom(room.ID, alice.Email)
+
+	bob := suite.createTestUser("bob", "bob@example.com", "password123")
+	bobToken := suite.loginTestUser(bob.Email, "password123")
+	suite.addUserToRoom(room.ID, bob.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	aliceConn, _ := suite.joinMeeting(aliceToken, room.ID)
+	aliceId := suite.readMessage(ownerConn, "client_joined")["clientId"]
+	bobConn, _ := suite.joinMeeting(bobToken, room.ID)
+	bobId := suite.readMessage(ownerConn, "client_joined")["clientId"]
+
+	queueIds := func(payload map[string]interface{}) []interface{} {
+		ids := []interface{}{}
+		for _, hand := range payload["queue"].([]interface{}) {
+			ids = append(ids, hand.(map[string]interface{})["clientId"])
+		}
+		return ids
+	}
+
+	suite.sendMessage(aliceConn, "raiseHand", map[string]interface{}{})
+	suite.Equal([]interface{}{aliceId}, queueIds(suite.readMessage(ownerConn, "handQueue")))
+
+	suite.sendMessage(bobConn, "raiseHand", map[string]interface{}{})
+	suite.Equal([]interface{}{aliceId, bobId}, queueIds(suite.readMessage(ownerConn, "handQueue")))
+
+	// Late joiners get the queue with init
+	lateConn, init := suite.joinMeeting(ownerToken, room.ID)
+	hands := init["hands"].([]interface{})
+	suite.Len(hands, 2)
+	suite.Equal("alice", hands[0].(map[string]interface{})["username"])
+
+	// Only moderators reorder the queue or lower other hands
+	suite.sendMessage(aliceConn, "moveHand", map[string]interface{}{"clientId": bobId, "position": 0})
+	suite.Equal("forbidden", suite.readMessage(aliceConn, "error")["code"])
+	suite.sendMessage(aliceConn, "lowerHand", map[string]interface{}{"clientId": bobId})
+	suite.Equal("forbidden", suite.readMessage(aliceConn, "error")["code"])
+
+	suite.sendMessage(ownerConn, "moveHand", map[string]interface{}{"clientId": bobId, "position": 0})
+	suite.Equal([]interface{}{bobId, aliceId}, queueIds(suite.readMessage(aliceConn, "handQueue")))
+
+	suite.sendMessage(bobConn, "lowerHand", map[string]interface{}{})
+	suite.Equal([]interface{}{aliceId}, queueIds(suite.readMessage(ownerConn, "handQueue")))
+
+	// Leaving the meeting lowers the hand
+	aliceConn.Close()
+	suite.Empty(queueIds(suite.readMessage(ownerConn, "handQueue")))
+
+	suite.sendMessage(bobConn, "raiseHand", map[string]interface{}{})
+	suite.readMessage(ownerConn, "handQueue")
+	suite.sendMessage(lateConn, "clearHands", map[string]interface{}{})
+	suite.Empty(queueIds(suite.readMessage(ownerConn, "handQueue")))
+}
+
+// Test: Late joiners learn the media state of clients already in the meeting
+func (suite *MeetingTestSuite) TestMediaStateInInit() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+
+	suite.sendMessage(memberConn, "trackMuted", map[string]interface{}{"trackKind": "audio"})
+	suite.sendMessage(memberConn, "streamMetadata", map[string]interface{}{"streamId": "cam", "streamType": "media"})
+	suite.sendMessage(memberConn, "streamMetadata", map[string]interface{}{"streamId": "desk", "streamType": "screen"})
+	suite.sendMessage(memberConn, "screenShareStarted", map[string]interface{}{})
+	suite.readMessage(ownerConn, "screenShareStarted")
+
+	memberState := func(init map[string]interface{}) map[string]interface{} {
+		for _, client := range init["clients"].([]interface{}) {
+			client := client.(map[string]interface{})
+			if client["userId"] == float64(member.ID) {
+				return client
+			}
+		}
+		suite.FailNow("member missing from init")
+		return nil
+	}
+
+	_, init := suite.joinMeeting(ownerToken, room.ID)
+	state := memberState(init)
+	suite.Equal([]interface{}{"audio"}, state["mutedTracks"])
+	suite.Equal(map[string]interface{}{"cam": "media", "desk": "screen"}, state["streams"])
+	suite.Equal(true, state["screenSharing"])
+
+	suite.sendMessage(memberConn, "trackUnmuted", map[string]interface{}{"trackKind": "audio"})
+	suite.sendMessage(memberConn, "screenShareStopped", map[string]interface{}{})
+	suite.Equal("audio", suite.readMessage(ownerConn, "trackUnmuted")["trackKind"])
+	suite.readMessage(ownerConn, "screenShareStopped")
+
+	_, init = suite.joinMeeting(ownerToken, room.ID)
+	state = memberState(init)
+	suite.Nil(state["mutedTracks"])
+	suite.Equal(map[string]interface{}{"cam": "media"}, state["streams"])
+	suite.Nil(state["screenSharing"])
+}
+
+// Test: Clients unmute by negotiating a new track on their media stream, as
+// announcing the stream again happens whenever someone joins
+func (suite *MeetingTestSuite) TestRenegotiationUnmutes() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, init := suite.joinMeeting(memberToken, room.ID)
+	ownerClientID := init["clients"].([]interface{})[0].(map[string]interface{})["id"]
+	suite.readMessage(ownerConn, "client_joined")
+
+	mutedTracks := func() interface{} {
+		_, init := suite.joinMeeting(ownerToken, room.ID)
+		for _, client := range init["clients"].([]interface{}) {
+			client := client.(map[string]interface{})
+			if client["userId"] == float64(member.ID) {
+				return client["mutedTracks"]
+			}
+		}
+		suite.FailNow("member missing from init")
+		return nil
+	}
+
+	// The stream ID of the answer's audio track
+	metadata := map[string]interface{}{"streamId": "4e3d2c1b-0a9f-4e8d-b7c6-a5b4c3d2e1f0", "streamType": "media"}
+	suite.sendMessage(memberConn, "streamMetadata", metadata)
+	suite.sendMessage(memberConn, "trackMuted", map[string]interface{}{"trackKind": "audio"})
+	suite.readMessage(ownerConn, "trackMuted")
+
+	suite.sendMessage(memberConn, "streamMetadata", metadata)
+	suite.readMessage(ownerConn, "streamMetadata")
+	suite.Equal([]interface{}{"audio"}, mutedTracks())
+
+	suite.sendMessage(memberConn, "answer", map[string]interface{}{
+		"messageId": "answer-1",
+		"clientId":  ownerClientID,
+		"value":     map[string]interface{}{"type": "answer", "sdp": suite.loadSDP("safari_audio_answer.sdp")},
+	})
+	suite.Equal("audio", suite.readMessage(ownerConn, "trackUnmuted")["trackKind"])
+	suite.Equal("answer-1", suite.readMessage(ownerConn, "answer")["messageId"])
+	suite.Nil(mutedTracks())
+}
+
+// Test: Moderators split the meeting into breakouts and bring everyone back
+func (suite *MeetingTestSuite) TestBreakouts() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	alice := suite.createTestUser("alice", "alice@example.com", "password123")
+	aliceToken := suite.loginTestUser(alice.Email, "password123")
+	suite.addUserToRoom(room.ID, alice.Email)
+
+	bob := suite.createTestUser("bob", "bob@example.com", "password123")
+	bobToken := suite.loginTestUser(bob.Email, "password123")
+	suite.addUserToRoom(room.ID, bob.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	aliceConn, _ := suite.joinMeeting(aliceToken, room.ID)
+	aliceId := suite.readMessage(ownerConn, "client_joined")["clientId"]
+	bobConn, _ := suite.joinMeeting(bobToken, room.ID)
+	bobId := suite.readMessage(ownerConn, "client_joined")["clientId"]
+
+	suite.sendMessage(aliceConn, "breakoutCreate", map[string]interface{}{"count": 2, "random": true})
+	suite.Equal("forbidden", suite.readMessage(aliceConn, "error")["code"])
+
+	suite.sendMessage(ownerConn, "breakoutCreate", map[string]interface{}{
+		"count":       2,
+		"assignments": map[string]interface{}{aliceId.(string): 5},
+	})
+	suite.Equal("invalid_breakout", suite.readMessage(ownerConn, "error")["code"])
+
+	// Alice is placed manually, Bob randomly into the emptier breakout
+	suite.sendMessage(ownerConn, "breakoutCreate", map[string]interface{}{
+		"count":       2,
+		"assignments": map[string]interface{}{aliceId.(string): 0},
+		"random":      true,
+	})
+
+	move := suite.readMessage(aliceConn, "moveToBreakout")
+	suite.Equal("Breakout 1", move["name"])
+	suite.NotEmpty(move["breakoutId"])
+	init := suite.readMessage(aliceConn, "init")
+	suite.Empty(init["clients"])
+
+	suite.Equal("Breakout 2", suite.readMessage(bobConn, "moveToBreakout")["name"])
+	suite.readMessage(bobConn, "init")
+
+	breakouts := suite.readMessage(ownerConn, "breakouts")["breakouts"].([]interface{})
+	suite.Require().Len(breakouts, 2)
+	suite.Equal([]interface{}{aliceId}, breakouts[0].(map[string]interface{})["clientIds"])
+	suite.Equal([]interface{}{bobId}, breakouts[1].(map[string]interface{})["clientIds"])
+
+	// Only one set of breakouts runs at a time
+	suite.sendMessage(ownerConn, "breakoutCreate", map[string]interface{}{"count": 1, "random": true})
+	suite.Equal("breakouts_running", suite.readMessage(ownerConn, "error")["code"])
+
+	suite.sendMessage(ownerConn, "breakoutBroadcast", map[string]interface{}{"message": "Two minutes left"})
+	suite.Equal("Two minutes left", suite.readMessage(aliceConn, "breakoutBroadcast")["message"])
+	suite.Equal("Two minutes left", suite.readMessage(bobConn, "breakoutBroadcast")["message"])
+
+	// Chat stays within the breakout
+	suite.sendMessage(bobConn, "data", map[string]interface{}{"message": "only room two"})
+	suite.sendMessage(aliceConn, "data", map[string]interface{}{"message": "only room one"})
+	suite.Equal("only room one", suite.readMessage(aliceConn, "data")["message"])
+
+	suite.sendMessage(aliceConn, "breakoutEnd", map[string]interface{}{})
+	suite.Equal("forbidden", suite.readMessage(aliceConn, "error")["code"])
+
+	suite.sendMessage(ownerConn, "breakoutEnd", map[string]interface{}{})
+	move = suite.readMessage(aliceConn, "moveToBreakout")
+	suite.Empty(move["breakoutId"])
+	suite.Equal("Test Room", move["name"])
+	init = suite.readMessage(aliceConn, "init")
+	suite.NotEmpty(init["clients"])
+
+	suite.readMessage(bobConn, "moveToBreakout")
+	suite.Empty(suite.readMessage(ownerConn, "breakouts")["breakouts"])
+
+	suite.sendMessage(ownerConn, "breakoutEnd", map[string]interface{}{})
+	suite.Equal("not_found", suite.readMessage(ownerConn, "error")["code"])
+}
+
+// Test: Participants moved into breakouts are still listed as online
+func (suite *MeetingTestSuite) TestParticipantsInBreakouts() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	suite.readMessage(ownerConn, "client_joined")
+
+	suite.sendMessage(ownerConn, "breakoutCreate", map[string]interface{}{"count": 1, "random": true})
+	suite.readMessage(memberConn, "moveToBreakout")
+	suite.readMessage(ownerConn, "breakouts")
+
+	w, err := suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/participants", room.ID), nil, ownerToken)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, w.Code)
+
+	var response struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Require().Len(response.Data, 2)
+	for _, participant := range response.Data {
+		suite.Equal(true, participant["online"], participant["username"])
+		suite.Equal(float64(1), participant["clients"], participant["username"])
+	}
+}
+
+// Test: Meetings stay locked while everyone is in breakouts, and unlock once
+// the last client leaves
+func (suite *MeetingTestSuite) TestLockSurvivesBreakouts() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, init := suite.joinMeeting(memberToken, room.ID)
+	ownerId := init["clients"].([]interface{})[0].(map[string]interface{})["id"]
+	memberId := suite.readMessage(ownerConn, "client_joined")["clientId"]
+
+	suite.sendMessage(ownerConn, "lockMeeting", map[string]interface{}{"locked": true})
+	suite.True(suite.readMessage(ownerConn, "meetingLocked")["locked"].(bool))
+
+	suite.sendMessage(ownerConn, "breakoutCreate", map[string]interface{}{
+		"count":       1,
+		"assignments": map[string]interface{}{ownerId.(string): 0, memberId.(string): 0},
+	})
+	suite.readMessage(ownerConn, "moveToBreakout")
+	suite.readMessage(memberConn, "moveToBreakout")
+	suite.readMessage(ownerConn, "breakouts")
+
+	suite.sendMessage(ownerConn, "breakoutEnd", map[string]interface{}{})
+	suite.readMessage(ownerConn, "moveToBreakout")
+	suite.True(suite.readMessage(ownerConn, "init")["locked"].(bool))
+
+	ownerConn.Close()
+	memberConn.Close()
+	suite.Eventually(func() bool {
+		conn, _, err := suite.connectToMeeting(memberToken, room.ID)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+// Test: Meetings pick up a room's new name once someone connects
+func (suite *MeetingTestSuite) TestFollowsRoomRename() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+
+	w, err := suite.makeRequest("PUT", fmt.Sprintf("/rooms/%d", room.ID), map[string]interface{}{
+		"name": "Renamed Room",
+	}, ownerToken)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, w.Code)
+
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	suite.readMessage(ownerConn, "client_joined")
+
+	suite.sendMessage(ownerConn, "breakoutCreate", map[string]interface{}{"count": 1, "random": true})
+	suite.readMessage(memberConn, "moveToBreakout")
+	suite.readMessage(ownerConn, "breakouts")
+
+	suite.sendMessage(ownerConn, "breakoutEnd", map[string]interface{}{})
+	suite.Equal("Renamed Room", suite.readMessage(memberConn, "moveToBreakout")["name"])
+}
+
+// Test: Timed breakouts bring everyone back on their own
+func (suite *MeetingTestSuite) TestTimedBreakouts() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	memberId := suite.readMessage(ownerConn, "client_joined")["clientId"]
+
+	suite.sendMessage(ownerConn, "breakoutCreate", map[string]interface{}{
+		"count":           1,
+		"random":          true,
+		"durationSeconds": 1,
+	})
+	suite.NotNil(suite.readMessage(memberConn, "moveToBreakout")["endsAt"])
+	suite.readMessage(ownerConn, "client_left")
+
+	suite.Empty(suite.readMessage(memberConn, "moveToBreakout")["breakoutId"])
+	suite.Equal(memberId, suite.readMessage(ownerConn, "client_joined")["clientId"])
+}
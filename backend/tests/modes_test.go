@@ -0,0 +1,252 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ModesTestSuite struct {
+	TestSuite
+}
+
+func TestModesTestSuite(t *testing.T) {
+	suite.Run(t, new(ModesTestSuite))
+}
+
+func (suite *ModesTestSuite) setMode(roomID uint, token string, mode string) {
+	w, err := suite.makeRequest("PATCH", fmt.Sprintf("/rooms/%d/settings", roomID), map[string]interface{}{
+		"mode": mode,
+	}, token)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), fmt.Sprintf(`"mode":"%s"`, mode))
+}
+
+// Test: Only known modes can be set
+func (suite *ModesTestSuite) TestUpdateMode() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	w, err := suite.makeRequest("GET", fmt.Sprintf("/rooms/%d", room.ID), nil, ownerToken)
+	suite.Require().NoError(err)
+	suite.Contains(w.Body.String(), `"mode":"meeting"`)
+
+	w, err = suite.makeRequest("PATCH", fmt.Sprintf("/rooms/%d/settings", room.ID), map[string]interface{}{
+		"mode": "broadcast",
+	}, ownerToken)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, w.Code)
+
+	suite.setMode(room.ID, ownerToken, "webinar")
+	suite.setMode(room.ID, ownerToken, "audio_only")
+
+	_, init := suite.joinMeeting(ownerToken, room.ID)
+	suite.Equal("audio_only", init["mode"])
+	suite.NotContains(init, "attendeeCount")
+}
+
+// Test: Audio-only rooms reject video tracks
+func (suite *ModesTestSuite) TestAudioOnlyRejectsVideo() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+	suite.setMode(room.ID, ownerToken, "audio_only")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	memberClientID := suite.readMessage(ownerConn, "client_joined")["clientId"]
+
+	suite.sendMessage(memberConn, "trackUnmuted", map[string]interface{}{"trackKind": "video"})
+	suite.Equal("video_not_allowed", suite.readMessage(memberConn, "error")["code"])
+
+	suite.sendMessage(memberConn, "streamMetadata", map[string]interface{}{"streamId": "screen-1", "streamType": "screen"})
+	suite.Equal("video_not_allowed", suite.readMessage(memberConn, "error")["code"])
+
+	suite.sendMessage(memberConn, "screenShareStarted", map[string]interface{}{})
+	suite.Equal("video_not_allowed", suite.readMessage(memberConn, "error")["code"])
+
+	suite.sendMessage(ownerConn, "offer", map[string]interface{}{
+		"messageId": "offer-1",
+		"clientId":  memberClientID,
+		"value":     map[string]interface{}{"type": "offer", "sdp": suite.loadSDP("chrome_offer.sdp")},
+	})
+	suite.Equal("sdp_rejected", suite.readMessage(ownerConn, "error")["code"])
+
+	// Audio keeps flowing, receiving video is harmless
+	suite.sendMessage(memberConn, "trackUnmuted", map[string]interface{}{"trackKind": "audio"})
+	suite.Equal(memberClientID, suite.readMessage(ownerConn, "trackUnmuted")["clientId"])
+
+	suite.sendMessage(ownerConn, "answer", map[string]interface{}{
+		"messageId": "answer-1",
+		"clientId":  memberClientID,
+		"value":     map[string]interface{}{"type": "answer", "sdp": suite.loadSDP("safari_audio_answer.sdp")},
+	})
+	suite.Equal("answer-1", suite.readMessage(memberConn, "answer")["messageId"])
+}
+
+// Test: Only webinar hosts publish and attendees don't see each other
+func (suite *ModesTestSuite) TestWebinar() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+	suite.setMode(room.ID, ownerToken, "webinar")
+
+	first := suite.createTestUser("first", "first@example.com", "password123")
+	firstToken := suite.loginTestUser(first.Email, "password123")
+	suite.addUserToRoom(room.ID, first.Email)
+
+	second := suite.createTestUser("second", "second@example.com", "password123")
+	secondToken := suite.loginTestUser(second.Email, "password123")
+	suite.addUserToRoom(room.ID, second.Email)
+
+	ownerConn, init := suite.joinMeeting(ownerToken, room.ID)
+	suite.Equal("owner", init["role"])
+	suite.Equal("webinar", init["mode"])
+	suite.Equal(float64(0), init["attendeeCount"])
+
+	firstConn, init := suite.joinMeeting(firstToken, room.ID)
+	suite.Equal("viewer", init["role"])
+	suite.Len(init["clients"], 1)
+	suite.Equal(float64(1), init["attendeeCount"])
+	suite.readMessage(firstConn, "attendeeCount")
+	suite.Equal("first", suite.readMessage(ownerConn, "client_joined")["username"])
+	suite.Equal(float64(1), suite.readMessage(ownerConn, "attendeeCount")["count"])
+
+	secondConn, init := suite.joinMeeting(secondToken, room.ID)
+	clients := init["clients"].([]interface{})
+	suite.Require().Len(clients, 1)
+	suite.Equal("owner", clients[0].(map[string]interface{})["username"])
+	suite.Equal(float64(2), init["attendeeCount"])
+	suite.Equal("second", suite.readMessage(ownerConn, "client_joined")["username"])
+
+	types, count := suite.messageTypesUntil(firstConn, "attendeeCount")
+	suite.NotContains(types, "client_joined")
+	suite.Equal(float64(2), count["count"])
+
+	// Attendees only receive
+	suite.sendMessage(secondConn, "trackUnmuted", map[string]interface{}{"trackKind": "audio"})
+	suite.Equal("forbidden", suite.readMessage(secondConn, "error")["code"])
+
+	suite.sendMessage(secondConn, "offer", map[string]interface{}{
+		"messageId": "offer-1",
+		"clientId":  "owner",
+		"value":     map[string]interface{}{"type": "offer", "sdp": suite.loadSDP("chrome_offer.sdp")},
+	})
+	suite.Equal("sdp_rejected", suite.readMessage(secondConn, "error")["code"])
+
+	secondConn.Close()
+	types, count = suite.messageTypesUntil(firstConn, "attendeeCount")
+	suite.NotContains(types, "client_left")
+	suite.Equal(float64(1), count["count"])
+	suite.readMessage(ownerConn, "client_left")
+}
+
+// Test: Webinar attendees don't learn about each other's hands, typing or votes
+func (suite *ModesTestSuite) TestWebinarHidesAttendeeActivity() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+	suite.setMode(room.ID, ownerToken, "webinar")
+
+	first := suite.createTestUser("first", "first@example.com", "password123")
+	firstToken := suite.loginTestUser(first.Email, "password123")
+	suite.addUserToRoom(room.ID, first.Email)
+
+	second := suite.createTestUser("second", "second@example.com", "password123")
+	secondToken := suite.loginTestUser(second.Email, "password123")
+	suite.addUserToRoom(room.ID, second.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	firstConn, _ := suite.joinMeeting(firstToken, room.ID)
+	secondConn, _ := suite.joinMeeting(secondToken, room.ID)
+	suite.readMessage(ownerConn, "client_joined")
+	secondId := suite.readMessage(ownerConn, "client_joined")["clientId"]
+
+	suite.sendMessage(secondConn, "raiseHand", map[string]interface{}{})
+	queue := suite.readMessage(ownerConn, "handQueue")
+	suite.Equal(secondId, queue["clientId"])
+	suite.Len(queue["queue"], 1)
+
+	queue = suite.readMessage(firstConn, "handQueue")
+	suite.NotContains(queue, "clientId")
+	suite.Empty(queue["queue"])
+
+	suite.sendMessage(secondConn, "typing", map[string]interface{}{"typing": true})
+	suite.Equal(secondId, suite.readMessage(ownerConn, "typing")["clientId"])
+
+	// Hosts' hands are still shown, to late attendees too
+	suite.sendMessage(ownerConn, "raiseHand", map[string]interface{}{})
+	types, queue := suite.messageTypesUntil(firstConn, "handQueue")
+	suite.NotContains(types, "typing")
+	suite.Len(queue["queue"], 1)
+
+	_, init := suite.joinMeeting(firstToken, room.ID)
+	suite.Len(init["hands"], 1)
+
+	suite.sendMessage(ownerConn, "pollCreate", map[string]interface{}{
+		"question": "Lunch?",
+		"options":  []string{"Pizza", "Sushi"},
+	})
+	options := optionIds(suite.readMessage(secondConn, "pollCreated"))
+	poll := suite.readMessage(firstConn, "pollCreated")
+
+	suite.sendMessage(secondConn, "pollVote", map[string]interface{}{"pollId": poll["id"], "optionIds": options[:1]})
+	suite.readMessage(firstConn, "pollUpdated")
+	suite.sendMessage(firstConn, "pollVote", map[string]interface{}{"pollId": poll["id"], "optionIds": options[:1]})
+
+	updated := suite.readMessage(firstConn, "pollUpdated")
+	suite.Equal([]interface{}{float64(2), float64(0)}, optionVotes(updated))
+	voters := updated["options"].([]interface{})[0].(map[string]interface{})["voterIds"]
+	suite.Equal([]interface{}{float64(first.ID)}, voters)
+
+	suite.readMessage(ownerConn, "pollUpdated")
+	updated = suite.readMessage(ownerConn, "pollUpdated")
+	voters = updated["options"].([]interface{})[0].(map[string]interface{})["voterIds"]
+	suite.ElementsMatch([]interface{}{float64(first.ID), float64(second.ID)}, voters)
+}
+
+// Test: Live meetings only turn into webinars and back once they're empty
+func (suite *ModesTestSuite) TestWebinarWaitsForEmptyMeeting() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	suite.setMode(room.ID, ownerToken, "webinar")
+
+	// Members joining the running meeting keep publishing like everyone else
+	memberConn, init := suite.joinMeeting(memberToken, room.ID)
+	suite.Equal("meeting", init["mode"])
+	suite.Equal("member", init["role"])
+	suite.NotContains(init, "attendeeCount")
+	suite.readMessage(ownerConn, "client_joined")
+
+	memberConn.Close()
+	suite.readMessage(ownerConn, "client_left")
+	ownerConn.Close()
+
+	suite.Eventually(func() bool {
+		w, err := suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/participants", room.ID), nil, ownerToken)
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, w.Code)
+		return !strings.Contains(w.Body.String(), `"online":true`)
+	}, 2*time.Second, 20*time.Millisecond)
+
+	_, init = suite.joinMeeting(memberToken, room.ID)
+	suite.Equal("webinar", init["mode"])
+	suite.Equal("viewer", init["role"])
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PollTestSuite struct {
+	TestSuite
+}
+
+func TestPollTestSuite(t *testing.T) {
+	suite.Run(t, new(PollTestSuite))
+}
+
+// optionIds returns the IDs of the poll's options in order
+func optionIds(poll map[string]interface{}) []interface{} {
+	ids := []interface{}{}
+	for _, option := range poll["options"].([]interface{}) {
+		ids = append(ids, option.(map[string]interface{})["id"])
+	}
+	return ids
+}
+
+// optionVotes returns the vote counts of the poll's options in order
+func optionVotes(poll map[string]interface{}) []interface{} {
+	votes := []interface{}{}
+	for _, option := range poll["options"].([]interface{}) {
+		votes = append(votes, option.(map[string]interface{})["votes"])
+	}
+	return votes
+}
+
+// Test: Moderators run polls and everyone sees the live tally
+func (suite *PollTestSuite) TestPollVoting() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	suite.readMessage(ownerConn, "client_joined")
+
+	// Members can't create polls
+	suite.sendMessage(memberConn, "pollCreate", map[string]interface{}{
+		"question": "Lunch?",
+		"options":  []string{"Pizza", "Sushi"},
+	})
+	suite.Equal("forbidden", suite.readMessage(memberConn, "error")["code"])
+
+	suite.sendMessage(ownerConn, "pollCreate", map[string]interface{}{
+		"question": "Lunch?",
+		"options":  []string{"Pizza", "Pizza"},
+	})
+	suite.Equal("invalid_poll", suite.readMessage(ownerConn, "error")["code"])
+
+	suite.sendMessage(ownerConn, "pollCreate", map[string]interface{}{
+		"question": "Lunch?",
+		"options":  []string{"Pizza", "Sushi", "Tacos"},
+	})
+	poll := suite.readMessage(memberConn, "pollCreated")
+	suite.Equal("Lunch?", poll["question"])
+	suite.Equal(false, poll["closed"])
+	options := optionIds(poll)
+	suite.Require().Len(options, 3)
+
+	// Single choice polls take one option at a time
+	suite.sendMessage(memberConn, "pollVote", map[string]interface{}{
+		"pollId":    poll["id"],
+		"optionIds": options[:2],
+	})
+	suite.Equal("invalid_vote", suite.readMessage(memberConn, "error")["code"])
+
+	suite.sendMessage(memberConn, "pollVote", map[string]interface{}{
+		"pollId":    poll["id"],
+		"optionIds": options[1:2],
+	})
+	updated := suite.readMessage(ownerConn, "pollUpdated")
+	suite.Equal([]interface{}{float64(0), float64(1), float64(0)}, optionVotes(updated))
+	suite.Equal(float64(1), updated["totalVoters"])
+	voters := updated["options"].([]interface{})[1].(map[string]interface{})["voterIds"]
+	suite.Equal([]interface{}{float64(member.ID)}, voters)
+
+	// Voting again replaces the previous choice
+	suite.sendMessage(memberConn, "pollVote", map[string]interface{}{
+		"pollId":    poll["id"],
+		"optionIds": options[2:],
+	})
+	updated = suite.readMessage(ownerConn, "pollUpdated")
+	suite.Equal([]interface{}{float64(0), float64(0), float64(1)}, optionVotes(updated))
+
+	suite.sendMessage(memberConn, "pollClose", map[string]interface{}{"pollId": poll["id"]})
+	suite.Equal("forbidden", suite.readMessage(memberConn, "error")["code"])
+
+	suite.sendMessage(ownerConn, "pollClose", map[string]interface{}{"pollId": poll["id"]})
+	suite.Equal(true, suite.readMessage(memberConn, "pollClosed")["closed"])
+
+	suite.sendMessage(memberConn, "pollVote", map[string]interface{}{
+		"pollId":    poll["id"],
+		"optionIds": options[:1],
+	})
+	suite.Equal("poll_closed", suite.readMessage(memberConn, "error")["code"])
+
+	// Results stay available after the meeting
+	w, err := suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/polls", room.ID), nil, memberToken)
+	suite.NoError(err)
+	suite.Require().Equal(http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	polls := response["data"].([]interface{})
+	suite.Require().Len(polls, 1)
+	result := polls[0].(map[string]interface{})
+	suite.Equal(true, result["closed"])
+	suite.Equal(float64(1), result["total_voters"])
+	suite.Equal([]interface{}{float64(0), float64(0), float64(1)}, optionVotes(result))
+}
+
+// Test: Anonymous multi choice polls hide voters and close on their own
+func (suite *PollTestSuite) TestAnonymousTimedPoll() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	suite.readMessage(ownerConn, "client_joined")
+
+	suite.sendMessage(ownerConn, "pollCreate", map[string]interface{}{
+		"question":        "Which days work?",
+		"options":         []string{"Monday", "Tuesday", "Friday"},
+		"anonymous":       true,
+		"multiChoice":     true,
+		"durationSeconds": 1,
+	})
+	poll := suite.readMessage(memberConn, "pollCreated")
+	suite.NotNil(poll["closesAt"])
+	options := optionIds(poll)
+
+	suite.sendMessage(memberConn, "pollVote", map[string]interface{}{
+		"pollId":    poll["id"],
+		"optionIds": options[:2],
+	})
+	updated := suite.readMessage(ownerConn, "pollUpdated")
+	suite.Equal([]interface{}{float64(1), float64(1), float64(0)}, optionVotes(updated))
+	for _, option := range updated["options"].([]interface{}) {
+		suite.NotContains(option.(map[string]interface{}), "voterIds")
+	}
+
+	start := time.Now()
+	closed := suite.readMessage(memberConn, "pollClosed")
+	suite.Equal(true, closed["closed"])
+	suite.Less(time.Since(start), 2*time.Second)
+
+	w, err := suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/polls", room.ID), nil, ownerToken)
+	suite.NoError(err)
+
+	var response map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	option := response["data"].([]interface{})[0].(map[string]interface{})["options"].([]interface{})[0]
+	suite.NotContains(option.(map[string]interface{}), "voter_ids")
+}
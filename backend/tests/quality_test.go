@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QualityTestSuite struct {
+	TestSuite
+}
+
+func TestQualityTestSuite(t *testing.T) {
+	suite.Run(t, new(QualityTestSuite))
+}
+
+func (suite *QualityTestSuite) getData(url string, token string) (int, interface{}) {
+	w, err := suite.makeRequest("GET", url, nil, token)
+	suite.Require().NoError(err)
+
+	var response map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response["data"]
+}
+
+// Test: Stats are aggregated for moderators and poor connections are hinted
+func (suite *QualityTestSuite) TestReportsConnectionQuality() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	liveURL := fmt.Sprintf("/rooms/%d/live/quality", room.ID)
+	code, data := suite.getData(liveURL, ownerToken)
+	suite.Equal(http.StatusOK, code)
+	suite.Empty(data.(map[string]interface{})["clients"])
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	memberClientID := suite.readMessage(ownerConn, "client_joined")["clientId"]
+
+	suite.sendMessage(memberConn, "rtcStats", map[string]interface{}{
+		"peers": []map[string]interface{}{{"clientId": "peer", "rtt": 40, "packetLoss": 2}},
+	})
+	suite.Equal("invalid_stats", suite.readMessage(memberConn, "error")["code"])
+
+	suite.sendMessage(memberConn, "rtcStats", map[string]interface{}{
+		"peers": []map[string]interface{}{{"clientId": "peer", "rtt": 40, "packetLoss": 0.01, "jitter": 5, "bitrate": 1200}},
+	})
+	suite.sendMessage(memberConn, "rtcStats", map[string]interface{}{
+		"peers": []map[string]interface{}{{"clientId": "peer", "rtt": 900, "packetLoss": 0.02, "jitter": 12, "bitrate": 300}},
+	})
+	hint := suite.readMessage(ownerConn, "poorConnection")
+	suite.Equal(memberClientID, hint["clientId"])
+	suite.Equal(true, hint["poor"])
+	suite.Equal(float64(900), hint["rtt"])
+	suite.Equal(true, suite.readMessage(memberConn, "poorConnection")["poor"])
+
+	code, data = suite.getData(liveURL, ownerToken)
+	suite.Equal(http.StatusOK, code)
+	live := data.(map[string]interface{})
+	suite.NotZero(live["session_id"])
+	clients := live["clients"].([]interface{})
+	suite.Require().Len(clients, 1)
+	client := clients[0].(map[string]interface{})
+	suite.Equal(memberClientID, client["client_id"])
+	suite.Equal(true, client["poor"])
+	peer := client["peers"].([]interface{})[0].(map[string]interface{})
+	suite.Equal(float64(900), peer["rtt"])
+	suite.Equal(float64(300), peer["bitrate"])
+
+	code, _ = suite.getData(liveURL, memberToken)
+	suite.Equal(http.StatusForbidden, code)
+
+	suite.sendMessage(memberConn, "rtcStats", map[string]interface{}{
+		"peers": []map[string]interface{}{{"clientId": "peer", "rtt": 50, "packetLoss": 0, "jitter": 4, "bitrate": 1500}},
+	})
+	hint = suite.readMessage(memberConn, "poorConnection")
+	suite.Equal(false, hint["poor"])
+}
+
+// Test: The reported stats are summed up once the session ends
+func (suite *QualityTestSuite) TestPersistsSessionSummaries() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+
+	for _, rtt := range []float64{100, 300} {
+		suite.sendMessage(memberConn, "rtcStats", map[string]interface{}{
+			"peers": []map[string]interface{}{{"clientId": "peer", "rtt": rtt, "packetLoss": 0.01, "jitter": 10, "bitrate": 800}},
+		})
+	}
+	// Stats are handled in order, so the chat message arrives after them
+	suite.sendMessage(memberConn, "data", map[string]interface{}{"message": "bye"})
+	suite.readMessage(ownerConn, "data")
+
+	memberConn.Close()
+	suite.readMessage(ownerConn, "client_left")
+	ownerConn.Close()
+
+	sessionsURL := fmt.Sprintf("/rooms/%d/sessions", room.ID)
+	var session map[string]interface{}
+	suite.Eventually(func() bool {
+		_, data := suite.getData(sessionsURL, memberToken)
+		sessions := data.([]interface{})
+		if len(sessions) != 1 {
+			return false
+		}
+		session = sessions[0].(map[string]interface{})
+		return session["ended_at"] != nil
+	}, 2*time.Second, 20*time.Millisecond)
+
+	qualityURL := fmt.Sprintf("/rooms/%d/sessions/%v/quality", room.ID, session["id"])
+	code, _ := suite.getData(qualityURL, memberToken)
+	suite.Equal(http.StatusForbidden, code)
+
+	code, data := suite.getData(qualityURL, ownerToken)
+	suite.Equal(http.StatusOK, code)
+	summaries := data.([]interface{})
+	suite.Require().Len(summaries, 1)
+	summary := summaries[0].(map[string]interface{})
+	suite.Equal(float64(member.ID), summary["user_id"])
+	suite.Equal(float64(2), summary["reports"])
+	suite.Equal(float64(200), summary["avg_rtt"])
+	suite.Equal(float64(300), summary["max_rtt"])
+	suite.Equal(float64(800), summary["avg_bitrate"])
+
+	code, _ = suite.getData(fmt.Sprintf("/rooms/%d/sessions/999/quality", room.ID), ownerToken)
+	suite.Equal(http.StatusNotFound, code)
+}
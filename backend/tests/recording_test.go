@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecordingTestSuite struct {
+	TestSuite
+}
+
+func TestRecordingTestSuite(t *testing.T) {
+	suite.Run(t, new(RecordingTestSuite))
+}
+
+// Test: Each published track is recorded into its own file
+func (suite *RecordingTestSuite) TestRecordsPublishedTracks() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+	suite.enableSFU(room.ID, ownerToken)
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	suite.readMessage(ownerConn, "client_joined")
+
+	// Only moderators may record
+	suite.sendMessage(memberConn, "startRecording", map[string]interface{}{})
+	suite.Equal("forbidden", suite.readMessage(memberConn, "error")["code"])
+
+	publisher := newSFUTestPeer(suite.T(), ownerConn)
+	publisher.send("startRecording", map[string]interface{}{})
+	started := suite.readMessage(memberConn, "recordingStarted")
+	suite.NotZero(started["recordingId"])
+	suite.NotEmpty(started["clientId"])
+
+	// Late joiners learn about the running recording
+	lateConn, init := suite.joinMeeting(memberToken, room.ID)
+	suite.Equal(started["recordingId"], init["recordingId"])
+
+	// Packets are recorded before they're forwarded, so once the late joiner
+	// receives both tracks they're in the recording too
+	subscriber := newSFUTestPeer(suite.T(), lateConn)
+	publisher.publishTracks("owner-media", webrtc.MimeTypeOpus, webrtc.MimeTypeVP8)
+	for range 2 {
+		select {
+		case track := <-subscriber.tracks:
+			track.SetReadDeadline(time.Now().Add(5 * time.Second))
+			_, _, err := track.ReadRTP()
+			suite.Require().NoError(err)
+		case <-time.After(10 * time.Second):
+			suite.FailNow("tracks were not forwarded")
+		}
+	}
+
+	publisher.send("stopRecording", map[string]interface{}{})
+	stopped := suite.readMessage(memberConn, "recordingStopped")
+	suite.Equal(started["recordingId"], stopped["recordingId"])
+	suite.Equal("completed", stopped["status"])
+
+	w, err := suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/recordings", room.ID), nil, memberToken)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, w.Code)
+
+	var response struct {
+		Data []struct {
+			ID        uint       `json:"id"`
+			Status    string     `json:"status"`
+			StoppedAt *time.Time `json:"stopped_at"`
+			Tracks    []struct {
+				ID          uint   `json:"id"`
+				UserID      uint   `json:"user_id"`
+				Kind        string `json:"kind"`
+				ContentType string `json:"content_type"`
+			} `json:"tracks"`
+		} `json:"data"`
+	}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Require().Len(response.Data, 1)
+
+	recording := response.Data[0]
+	suite.Equal("completed", recording.Status)
+	suite.NotNil(recording.StoppedAt)
+	suite.Require().Len(recording.Tracks, 2)
+
+	// Containers are told apart by their magic bytes
+	magic := map[string][]byte{
+		"audio/ogg":  []byte("OggS"),
+		"video/webm": {0x1a, 0x45, 0xdf, 0xa3},
+	}
+	kinds := map[string]string{}
+	for _, track := range recording.Tracks {
+		suite.Equal(owner.ID, track.UserID)
+		kinds[track.Kind] = track.ContentType
+
+		w, err := suite.makeRequest(
+			"GET",
+			fmt.Sprintf("/rooms/%d/recordings/%d/tracks/%d", room.ID, recording.ID, track.ID),
+			nil,
+			memberToken,
+		)
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, w.Code)
+
+		var link struct {
+			Data struct {
+				URL string `json:"url"`
+			} `json:"data"`
+		}
+		suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &link))
+
+		req := httptest.NewRequest("GET", link.Data.URL, nil)
+		download := httptest.NewRecorder()
+		suite.router.ServeHTTP(download, req)
+		suite.Require().Equal(http.StatusOK, download.Code)
+		suite.Equal(track.ContentType, download.Header().Get("Content-Type"))
+		suite.True(bytes.HasPrefix(download.Body.Bytes(), magic[track.ContentType]))
+	}
+	suite.Equal(map[string]string{"audio": "audio/ogg", "video": "video/webm"}, kinds)
+}
+
+// Test: Recording needs the SFU and outsiders can't list recordings
+func (suite *RecordingTestSuite) TestRecordingRequiresSFU() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	suite.sendMessage(ownerConn, "startRecording", map[string]interface{}{})
+	suite.Equal("recording_unavailable", suite.readMessage(ownerConn, "error")["code"])
+
+	suite.sendMessage(ownerConn, "stopRecording", map[string]interface{}{})
+	suite.Equal("not_recording", suite.readMessage(ownerConn, "error")["code"])
+
+	outsider := suite.createTestUser("outsider", "outsider@example.com", "password123")
+	outsiderToken := suite.loginTestUser(outsider.Email, "password123")
+
+	w, err := suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/recordings", room.ID), nil, outsiderToken)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, w.Code)
+
+	w, err = suite.makeRequest("GET", "/recordings/tracks/1?user=1&expires=9999999999&signature=bad", nil, "")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusForbidden, w.Code)
+}
@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/serozhenka/shary/internal/http/routes/rooms"
+	"github.com/serozhenka/shary/internal/http/routes/ws"
+	"github.com/serozhenka/shary/internal/messages"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -397,3 +399,267 @@ func (suite *RoomsTestSuite) TestUnauthorizedAccessToRooms() {
 		})
 	}
 }
+
+// Additional test: Changing participant roles
+func (suite *RoomsTestSuite) TestChangingParticipantRoles() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	moderator := suite.createTestUser("moderator", "moderator@example.com", "password123")
+	moderatorToken := suite.loginTestUser(moderator.Email, "password123")
+	suite.addUserToRoom(room.ID, moderator.Email)
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	tests := []struct {
+		name           string
+		token          string
+		userID         uint
+		role           string
+		expectedStatus int
+	}{
+		{"owner_promotes_moderator", ownerToken, moderator.ID, "moderator", http.StatusOK},
+		{"member_cannot_change_roles", memberToken, moderator.ID, "viewer", http.StatusForbidden},
+		{"moderator_demotes_member", moderatorToken, member.ID, "viewer", http.StatusOK},
+		{"moderator_cannot_promote_to_moderator", moderatorToken, member.ID, "moderator", http.StatusForbidden},
+		{"moderator_cannot_demote_owner", moderatorToken, owner.ID, "member", http.StatusForbidden},
+		{"owner_role_cannot_be_assigned", ownerToken, member.ID, "owner", http.StatusBadRequest},
+		{"unknown_role", ownerToken, member.ID, "admin", http.StatusBadRequest},
+		{"unknown_participant", ownerToken, 999, "member", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			url := fmt.Sprintf("/rooms/%d/users/%d/role", room.ID, tt.userID)
+			w, err := suite.makeRequest("PUT", url, rooms.UpdateParticipantRoleRequest{Role: tt.role}, tt.token)
+			suite.NoError(err)
+			suite.Equal(tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				participant, err := suite.roomRepo.GetParticipant(room.ID, tt.userID)
+				suite.NoError(err)
+				suite.Equal(tt.role, string(participant.Role))
+			}
+		})
+	}
+}
+
+// Additional test: Role permissions are enforced on room updates
+func (suite *RoomsTestSuite) TestRoomEditingByRole() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	room := suite.createTestRoom(owner.ID, "Original Room Name")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	url := fmt.Sprintf("/rooms/%d", room.ID)
+	w, err := suite.makeRequest("PUT", url, rooms.UpdateRoomRequest{Name: "Member Update"}, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusForbidden, w.Code)
+
+	_, err = suite.roomRepo.UpdateParticipantRole(owner.ID, room.ID, member.ID, "moderator")
+	suite.Require().NoError(err)
+
+	w, err = suite.makeRequest("PUT", url, rooms.UpdateRoomRequest{Name: "Moderator Update"}, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	w, err = suite.makeRequest("DELETE", url, nil, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusForbidden, w.Code)
+}
+
+// Additional test: Transferring room ownership
+func (suite *RoomsTestSuite) TestTransferringOwnership() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	newOwner := suite.createTestUser("newowner", "newowner@example.com", "password123")
+	newOwnerToken := suite.loginTestUser(newOwner.Email, "password123")
+	suite.addUserToRoom(room.ID, newOwner.Email)
+
+	url := fmt.Sprintf("/rooms/%d/owner", room.ID)
+
+	// Non-owners cannot transfer ownership
+	w, err := suite.makeRequest("PUT", url, rooms.TransferOwnershipRequest{UserID: newOwner.ID}, newOwnerToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusForbidden, w.Code)
+
+	w, err = suite.makeRequest("PUT", url, rooms.TransferOwnershipRequest{UserID: newOwner.ID}, ownerToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	suite.NoError(err)
+
+	data := response["data"].(map[string]interface{})
+	suite.Equal(float64(newOwner.ID), data["owner_id"])
+	suite.False(data["is_owner"].(bool))
+
+	previousOwner, err := suite.roomRepo.GetParticipant(room.ID, owner.ID)
+	suite.NoError(err)
+	suite.Equal("moderator", string(previousOwner.Role))
+
+	// The new owner can now delete the room
+	w, err = suite.makeRequest("DELETE", fmt.Sprintf("/rooms/%d", room.ID), nil, newOwnerToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+}
+
+// Additional test: Removing a participant from a room
+func (suite *RoomsTestSuite) TestRemovingUserFromRoom() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	// The removed member is connected to the live meeting
+	meet := suite.meetings.CreateMeeting(fmt.Sprintf("%d", room.ID))
+	client := ws.NewClient("member-client", member.ID, member.Username, "member", nil)
+	meet.Join(client)
+	<-client.Messages // init
+
+	// Members cannot remove the owner
+	url := fmt.Sprintf("/rooms/%d/users/%d", room.ID, owner.ID)
+	w, err := suite.makeRequest("DELETE", url, nil, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusForbidden, w.Code)
+
+	url = fmt.Sprintf("/rooms/%d/users/%d", room.ID, member.ID)
+	w, err = suite.makeRequest("DELETE", url, nil, ownerToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	_, err = suite.roomRepo.GetParticipant(room.ID, member.ID)
+	suite.Error(err)
+	suite.Equal(0, meet.GetParticipantCount())
+
+	kicked := <-client.Messages
+	suite.Equal(messages.OutboundKicked, kicked.Type)
+
+	// The removed member no longer has access to the room
+	w, err = suite.makeRequest("GET", fmt.Sprintf("/rooms/%d", room.ID), nil, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
+// Additional test: Leaving a room
+func (suite *RoomsTestSuite) TestLeavingRoom() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	url := fmt.Sprintf("/rooms/%d/leave", room.ID)
+
+	// The owner has to transfer ownership first
+	w, err := suite.makeRequest("POST", url, nil, ownerToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusConflict, w.Code)
+
+	w, err = suite.makeRequest("POST", url, nil, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	w, err = suite.makeRequest("GET", "/rooms", nil, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	suite.NoError(err)
+	suite.Empty(response["data"])
+
+	// Leaving twice is not possible
+	w, err = suite.makeRequest("POST", url, nil, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
+// Additional test: Listing room participants with presence
+func (suite *RoomsTestSuite) TestListingParticipants() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	outsider := suite.createTestUser("outsider", "outsider@example.com", "password123")
+	outsiderToken := suite.loginTestUser(outsider.Email, "password123")
+
+	// Only the member is in the call
+	meet := suite.meetings.CreateMeeting(fmt.Sprintf("%d", room.ID))
+	meet.Join(ws.NewClient("member-client", member.ID, member.Username, "member", nil))
+
+	url := fmt.Sprintf("/rooms/%d/participants", room.ID)
+	w, err := suite.makeRequest("GET", url, nil, ownerToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	suite.NoError(err)
+
+	data := response["data"].([]interface{})
+	suite.Len(data, 2)
+
+	participants := make(map[string]map[string]interface{})
+	for _, participantData := range data {
+		participant := participantData.(map[string]interface{})
+		participants[participant["username"].(string)] = participant
+	}
+
+	suite.Equal("owner", participants["owner"]["role"])
+	suite.False(participants["owner"]["online"].(bool))
+	suite.Equal("member", participants["member"]["role"])
+	suite.True(participants["member"]["online"].(bool))
+	suite.Contains(participants["member"], "joined_at")
+
+	// Non-participants cannot see who is in the room
+	w, err = suite.makeRequest("GET", url, nil, outsiderToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
+// Additional test: Updating room settings
+func (suite *RoomsTestSuite) TestUpdatingRoomSettings() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	url := fmt.Sprintf("/rooms/%d/settings", room.ID)
+	body := map[string]interface{}{"lobby_enabled": true}
+
+	w, err := suite.makeRequest("PATCH", url, body, memberToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusForbidden, w.Code)
+
+	w, err = suite.makeRequest("PATCH", url, body, ownerToken)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	suite.NoError(err)
+
+	data := response["data"].(map[string]interface{})
+	suite.True(data["lobby_enabled"].(bool))
+	suite.Equal(room.Name, data["name"])
+}
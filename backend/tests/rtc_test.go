@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/serozhenka/shary/internal/services"
+	"github.com/stretchr/testify/suite"
+)
+
+type RTCTestSuite struct {
+	TestSuite
+}
+
+func TestRTCTestSuite(t *testing.T) {
+	suite.Run(t, new(RTCTestSuite))
+}
+
+// verifyTURNCredentials checks the credentials the way a TURN server
+// configured with the shared secret does
+func (suite *RTCTestSuite) verifyTURNCredentials(username, credential string, userID uint) {
+	parts := strings.SplitN(username, ":", 2)
+	suite.Require().Len(parts, 2)
+
+	expires, err := strconv.ParseInt(parts[0], 10, 64)
+	suite.Require().NoError(err)
+	suite.Greater(expires, time.Now().Unix())
+	suite.LessOrEqual(expires, time.Now().Add(services.DefaultTURNCredentialTTL).Unix())
+	suite.Equal(strconv.FormatUint(uint64(userID), 10), parts[1])
+
+	mac := hmac.New(sha1.New, []byte(testTURNSecret))
+	mac.Write([]byte(username))
+	suite.Equal(base64.StdEncoding.EncodeToString(mac.Sum(nil)), credential)
+}
+
+// Test: GET /rtc/config issues STUN servers and TURN credentials
+func (suite *RTCTestSuite) TestGetConfig() {
+	user := suite.createTestUser("user", "user@example.com", "password123")
+	token := suite.loginTestUser(user.Email, "password123")
+
+	w, err := suite.makeRequest("GET", "/rtc/config", nil, token)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, w.Code)
+
+	var response struct {
+		Data struct {
+			IceServers []services.ICEServer `json:"ice_servers"`
+			ExpiresAt  time.Time            `json:"expires_at"`
+		} `json:"data"`
+	}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Require().Len(response.Data.IceServers, 2)
+	suite.WithinDuration(time.Now().Add(services.DefaultTURNCredentialTTL), response.Data.ExpiresAt, time.Minute)
+
+	stun := response.Data.IceServers[0]
+	suite.Equal([]string{"stun:stun.example.com:3478"}, stun.URLs)
+	suite.Empty(stun.Username)
+
+	turn := response.Data.IceServers[1]
+	suite.Equal([]string{"turn:turn.example.com:3478"}, turn.URLs)
+	suite.verifyTURNCredentials(turn.Username, turn.Credential, user.ID)
+
+	w, err = suite.makeRequest("GET", "/rtc/config", nil, "")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusUnauthorized, w.Code)
+}
+
+// Test: Meeting clients receive their ICE servers in init
+func (suite *RTCTestSuite) TestInitIncludesIceServers() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	_, init := suite.joinMeeting(ownerToken, room.ID)
+
+	servers := init["iceServers"].([]interface{})
+	suite.Require().Len(servers, 2)
+
+	turn := servers[1].(map[string]interface{})
+	suite.Equal([]interface{}{"turn:turn.example.com:3478"}, turn["urls"])
+	suite.verifyTURNCredentials(turn["username"].(string), turn["credential"].(string), owner.ID)
+}
+
+// Test: Clients moved to a breakout receive fresh TURN credentials
+func (suite *RTCTestSuite) TestBreakoutInitIncludesIceServers() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	suite.readMessage(ownerConn, "client_joined")
+
+	suite.sendMessage(ownerConn, "breakoutCreate", map[string]interface{}{"count": 1, "random": true})
+	suite.readMessage(memberConn, "moveToBreakout")
+	init := suite.readMessage(memberConn, "init")
+
+	servers := init["iceServers"].([]interface{})
+	suite.Require().Len(servers, 2)
+
+	turn := servers[1].(map[string]interface{})
+	suite.verifyTURNCredentials(turn["username"].(string), turn["credential"].(string), member.ID)
+}
+
+// Test: TURN servers are left out when no secret is configured
+func (suite *RTCTestSuite) TestTURNRequiresSecret() {
+	ice := services.NewICEService(services.ICEConfig{
+		STUNURLs: []string{"stun:stun.example.com:3478"},
+		TURNURLs: []string{"turn:turn.example.com:3478"},
+	})
+
+	servers, _ := ice.Servers(1)
+	suite.Require().Len(servers, 1)
+	suite.Equal([]string{"stun:stun.example.com:3478"}, servers[0].URLs)
+}
@@ -0,0 +1,331 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pionsdp "github.com/pion/sdp/v3"
+	"github.com/serozhenka/shary/internal/sdp"
+	"github.com/stretchr/testify/suite"
+)
+
+type SDPTestSuite struct {
+	TestSuite
+}
+
+func TestSDPTestSuite(t *testing.T) {
+	suite.Run(t, new(SDPTestSuite))
+}
+
+// loadSDP reads a browser session description from testdata, with the CRLF
+// line endings browsers send
+func (suite *TestSuite) loadSDP(name string) string {
+	raw, err := os.ReadFile(filepath.Join("testdata", "sdp", name))
+	suite.Require().NoError(err)
+	return strings.ReplaceAll(string(raw), "\n", "\r\n")
+}
+
+func (suite *SDPTestSuite) section(description *pionsdp.SessionDescription, kind string) *pionsdp.MediaDescription {
+	for _, media := range description.MediaDescriptions {
+		if media.MediaName.Media == kind {
+			return media
+		}
+	}
+	suite.FailNow("missing media section", kind)
+	return nil
+}
+
+func codecNames(media *pionsdp.MediaDescription) []string {
+	names := make([]string, 0)
+	for _, codec := range sdp.Codecs(media) {
+		names = append(names, codec.Name)
+	}
+	return names
+}
+
+func attributeValues(media *pionsdp.MediaDescription, key string) []string {
+	values := make([]string, 0)
+	for _, attribute := range media.Attributes {
+		if attribute.Key == key {
+			values = append(values, attribute.Value)
+		}
+	}
+	return values
+}
+
+func bandwidth(media *pionsdp.MediaDescription, kind string) uint64 {
+	for _, bandwidth := range media.Bandwidth {
+		if bandwidth.Type == kind {
+			return bandwidth.Bandwidth
+		}
+	}
+	return 0
+}
+
+// Test: Browser descriptions are parsed into their codecs and directions
+func (suite *SDPTestSuite) TestParseBrowserDescriptions() {
+	tests := []struct {
+		file       string
+		audio      []string
+		video      []string
+		videoSends bool
+		simulcast  bool
+	}{
+		{
+			file:       "chrome_offer.sdp",
+			audio:      []string{"opus", "red", "G722", "PCMU", "PCMA", "CN", "telephone-event", "telephone-event"},
+			videoSends: true,
+		},
+		{
+			file:       "firefox_offer.sdp",
+			audio:      []string{"opus", "G722", "PCMU", "PCMA", "telephone-event"},
+			video:      []string{"VP8", "rtx", "VP9", "rtx", "H264", "rtx", "H264", "rtx", "ulpfec", "red", "rtx"},
+			videoSends: true,
+			simulcast:  true,
+		},
+		{
+			file:       "chrome_simulcast_offer.sdp",
+			video:      []string{"VP8", "rtx", "VP9", "rtx"},
+			videoSends: true,
+			simulcast:  true,
+		},
+		{
+			file:       "chrome_legacy_simulcast_offer.sdp",
+			video:      []string{"VP8", "rtx"},
+			videoSends: true,
+			simulcast:  true,
+		},
+		{
+			file:  "safari_audio_answer.sdp",
+			audio: []string{"opus", "red", "telephone-event"},
+			video: []string{"VP8", "rtx"},
+		},
+	}
+
+	for _, test := range tests {
+		suite.Run(test.file, func() {
+			description, err := sdp.Parse(suite.loadSDP(test.file))
+			suite.Require().NoError(err)
+
+			if test.audio != nil {
+				suite.Equal(test.audio, codecNames(suite.section(description, "audio")))
+			}
+			video := suite.section(description, "video")
+			if test.video != nil {
+				suite.Equal(test.video, codecNames(video))
+			}
+			suite.Equal(test.videoSends, sdp.Sends(video))
+			suite.Equal(test.simulcast, sdp.Simulcast(video))
+		})
+	}
+
+	_, err := sdp.Parse("not a session description")
+	suite.ErrorIs(err, sdp.ErrInvalidSDP)
+}
+
+// Test: Policies rewrite or reject browser descriptions
+func (suite *SDPTestSuite) TestApplyPolicy() {
+	permissive := sdp.Policy{Simulcast: true}
+
+	tests := []struct {
+		name   string
+		file   string
+		policy sdp.Policy
+		err    error
+		check  func(description *pionsdp.SessionDescription)
+	}{
+		{
+			name:   "permissive policy leaves the description untouched",
+			file:   "chrome_offer.sdp",
+			policy: permissive,
+		},
+		{
+			name:   "codecs are narrowed down with their rtx and fec",
+			file:   "chrome_offer.sdp",
+			policy: sdp.Policy{AllowedCodecs: []string{"opus", "vp8"}, Simulcast: true},
+			check: func(description *pionsdp.SessionDescription) {
+				audio := suite.section(description, "audio")
+				suite.Equal([]string{"opus", "red", "CN", "telephone-event", "telephone-event"}, codecNames(audio))
+				suite.Equal([]string{"111", "63", "13", "110", "126"}, audio.MediaName.Formats)
+
+				video := suite.section(description, "video")
+				suite.Equal([]string{"VP8", "rtx", "red", "rtx", "ulpfec"}, codecNames(video))
+				suite.Equal([]string{"96", "97", "116", "117", "118"}, video.MediaName.Formats)
+				suite.NotContains(attributeValues(video, "rtcp-fb"), "102 nack")
+				suite.NotContains(attributeValues(video, "fmtp"), "103 apt=102")
+				suite.Contains(attributeValues(video, "fmtp"), "97 apt=96")
+			},
+		},
+		{
+			name:   "video-only allow lists leave audio alone",
+			file:   "chrome_offer.sdp",
+			policy: sdp.Policy{AllowedCodecs: []string{"VP8"}, Simulcast: true},
+			check: func(description *pionsdp.SessionDescription) {
+				original, err := sdp.Parse(suite.loadSDP("chrome_offer.sdp"))
+				suite.Require().NoError(err)
+				suite.Equal(
+					codecNames(suite.section(original, "audio")),
+					codecNames(suite.section(description, "audio")),
+				)
+				suite.Equal([]string{"VP8", "rtx", "red", "rtx", "ulpfec"}, codecNames(suite.section(description, "video")))
+			},
+		},
+		{
+			name:   "firefox codecs are narrowed down",
+			file:   "firefox_offer.sdp",
+			policy: sdp.Policy{AllowedCodecs: []string{"opus", "H264"}, Simulcast: true},
+			check: func(description *pionsdp.SessionDescription) {
+				suite.Equal([]string{"opus", "telephone-event"}, codecNames(suite.section(description, "audio")))
+				suite.Equal(
+					[]string{"H264", "rtx", "H264", "rtx", "ulpfec", "red", "rtx"},
+					codecNames(suite.section(description, "video")),
+				)
+			},
+		},
+		{
+			name:   "offers without an allowed codec are rejected",
+			file:   "chrome_simulcast_offer.sdp",
+			policy: sdp.Policy{AllowedCodecs: []string{"H264"}, Simulcast: true},
+			err:    sdp.ErrCodecNotAllowed,
+		},
+		{
+			name:   "video bitrate is capped with b=AS",
+			file:   "chrome_offer.sdp",
+			policy: sdp.Policy{MaxVideoBitrate: 800, Simulcast: true},
+			check: func(description *pionsdp.SessionDescription) {
+				suite.Equal(uint64(800), bandwidth(suite.section(description, "video"), "AS"))
+				suite.Zero(bandwidth(suite.section(description, "audio"), "AS"))
+			},
+		},
+		{
+			name:   "firefox TIAS is capped as well",
+			file:   "firefox_offer.sdp",
+			policy: sdp.Policy{MaxVideoBitrate: 1000, Simulcast: true},
+			check: func(description *pionsdp.SessionDescription) {
+				video := suite.section(description, "video")
+				suite.Equal(uint64(1000), bandwidth(video, "AS"))
+				suite.Equal(uint64(1000000), bandwidth(video, "TIAS"))
+			},
+		},
+		{
+			name:   "rid simulcast is stripped",
+			file:   "chrome_simulcast_offer.sdp",
+			policy: sdp.Policy{},
+			check: func(description *pionsdp.SessionDescription) {
+				video := suite.section(description, "video")
+				suite.False(sdp.Simulcast(video))
+				suite.Empty(attributeValues(video, "rid"))
+				suite.True(sdp.Sends(video))
+			},
+		},
+		{
+			name:   "ssrc group simulcast keeps its first layer",
+			file:   "chrome_legacy_simulcast_offer.sdp",
+			policy: sdp.Policy{},
+			check: func(description *pionsdp.SessionDescription) {
+				video := suite.section(description, "video")
+				suite.False(sdp.Simulcast(video))
+				suite.Equal([]string{"FID 1111111111 2222222222"}, attributeValues(video, "ssrc-group"))
+				suite.Equal(
+					[]string{"1111111111 cname:pQ7nZ2kL", "2222222222 cname:pQ7nZ2kL"},
+					attributeValues(video, "ssrc"),
+				)
+			},
+		},
+		{
+			name:   "audio-only rooms reject sent video",
+			file:   "chrome_offer.sdp",
+			policy: sdp.Policy{AudioOnly: true, Simulcast: true},
+			err:    sdp.ErrVideoNotAllowed,
+		},
+		{
+			name:   "audio-only rooms accept receive-only video",
+			file:   "safari_audio_answer.sdp",
+			policy: sdp.Policy{AudioOnly: true, Simulcast: true},
+			check: func(description *pionsdp.SessionDescription) {
+				suite.Equal("recvonly", sdp.Direction(suite.section(description, "video")))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			raw := suite.loadSDP(test.file)
+			rewritten, err := test.policy.Apply(raw)
+			if test.err != nil {
+				suite.ErrorIs(err, test.err)
+				return
+			}
+			suite.Require().NoError(err)
+
+			if test.check == nil {
+				suite.Equal(raw, rewritten)
+				return
+			}
+
+			// The rewritten description must still be valid and keep every section
+			description, err := sdp.Parse(rewritten)
+			suite.Require().NoError(err)
+			original, err := sdp.Parse(raw)
+			suite.Require().NoError(err)
+			suite.Len(description.MediaDescriptions, len(original.MediaDescriptions))
+			test.check(description)
+		})
+	}
+}
+
+// Test: Offers relayed between peers follow the room's media policy
+func (suite *SDPTestSuite) TestRoomPolicyIsEnforced() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	settingsURL := fmt.Sprintf("/rooms/%d/settings", room.ID)
+	w, err := suite.makeRequest("PATCH", settingsURL, map[string]interface{}{
+		"allowed_codecs": []string{"Theora"},
+	}, ownerToken)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, w.Code)
+
+	w, err = suite.makeRequest("PATCH", settingsURL, map[string]interface{}{
+		"allowed_codecs":    []string{"opus", "VP8"},
+		"max_video_bitrate": 500,
+		"simulcast_enabled": false,
+	}, ownerToken)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"allowed_codecs":["opus","VP8"]`)
+	suite.Contains(w.Body.String(), `"simulcast_enabled":false`)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	joined := suite.readMessage(ownerConn, "client_joined")
+
+	suite.sendMessage(ownerConn, "offer", map[string]interface{}{
+		"messageId": "offer-1",
+		"clientId":  joined["clientId"],
+		"value":     map[string]interface{}{"type": "offer", "sdp": suite.loadSDP("chrome_offer.sdp")},
+	})
+	offer := suite.readMessage(memberConn, "offer")
+
+	description, err := sdp.Parse(offer["value"].(map[string]interface{})["sdp"].(string))
+	suite.Require().NoError(err)
+	video := suite.section(description, "video")
+	suite.NotContains(codecNames(video), "H264")
+	suite.Equal(uint64(500), bandwidth(video, "AS"))
+
+	suite.sendMessage(ownerConn, "offer", map[string]interface{}{
+		"messageId": "offer-2",
+		"clientId":  joined["clientId"],
+		"value":     map[string]interface{}{"type": "offer", "sdp": "v=0"},
+	})
+	suite.Equal("invalid_sdp", suite.readMessage(ownerConn, "error")["code"])
+}
@@ -0,0 +1,297 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+	"github.com/serozhenka/shary/internal/sfu"
+	"github.com/stretchr/testify/suite"
+)
+
+type SFUTestSuite struct {
+	TestSuite
+}
+
+func TestSFUTestSuite(t *testing.T) {
+	suite.Run(t, new(SFUTestSuite))
+}
+
+// sfuTestPeer stands in for a browser negotiating with the SFU over the
+// meeting's WebSocket
+type sfuTestPeer struct {
+	t          *testing.T
+	conn       *websocket.Conn
+	writeMu    sync.Mutex
+	publisher  *webrtc.PeerConnection
+	subscriber *webrtc.PeerConnection
+	pending    map[string][]webrtc.ICECandidateInit // Candidates received ahead of the description
+
+	tracks    chan *webrtc.TrackRemote
+	published chan map[string]interface{}
+	layers    chan map[string]interface{}
+}
+
+func newSFUTestPeer(t *testing.T, conn *websocket.Conn) *sfuTestPeer {
+	peer := &sfuTestPeer{
+		t:         t,
+		conn:      conn,
+		pending:   make(map[string][]webrtc.ICECandidateInit),
+		tracks:    make(chan *webrtc.TrackRemote, 4),
+		published: make(chan map[string]interface{}, 4),
+		layers:    make(chan map[string]interface{}, 4),
+	}
+
+	peer.publisher = peer.newConnection(sfu.PublisherID)
+	peer.subscriber = peer.newConnection(sfu.SubscriberID)
+	peer.subscriber.OnTrack(func(track *webrtc.TrackRemote, _ *webrtc.RTPReceiver) {
+		peer.tracks <- track
+	})
+
+	go peer.pump()
+	return peer
+}
+
+func (p *sfuTestPeer) newConnection(target string) *webrtc.PeerConnection {
+	connection, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	p.t.Cleanup(func() { connection.Close() })
+
+	connection.OnICECandidate(func(candidate *webrtc.ICECandidate) {
+		if candidate != nil {
+			p.send("iceCandidate", map[string]interface{}{"clientId": target, "value": candidate.ToJSON()})
+		}
+	})
+	return connection
+}
+
+func (p *sfuTestPeer) send(messageType string, payload interface{}) {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+
+	p.conn.WriteJSON(map[string]interface{}{"type": messageType, "payload": payload})
+}
+
+// testFrames are sent by published tracks, the VP8 one is a keyframe
+// header carrying a 640x480 frame size and the Opus one is silence
+var testFrames = map[string][]byte{
+	webrtc.MimeTypeVP8:  {0x10, 0x02, 0x00, 0x9d, 0x01, 0x2a, 0x80, 0x02, 0xe0, 0x01},
+	webrtc.MimeTypeOpus: {0xf8, 0xff, 0xfe},
+}
+
+var testTrackIDs = map[string]string{
+	webrtc.MimeTypeVP8:  "camera",
+	webrtc.MimeTypeOpus: "microphone",
+}
+
+// publish offers a VP8 track to the SFU and keeps sending frames on it
+func (p *sfuTestPeer) publish(streamID string) {
+	p.publishTracks(streamID, webrtc.MimeTypeVP8)
+}
+
+// publishTracks offers a track per codec to the SFU in a single negotiation
+// and keeps sending frames on them
+func (p *sfuTestPeer) publishTracks(streamID string, mimeTypes ...string) {
+	frames := make(map[string][]byte)
+	for _, mimeType := range mimeTypes {
+		frames[mimeType] = testFrames[mimeType]
+	}
+	p.publishFrames(streamID, frames)
+}
+
+// publishFrames offers a track per codec to the SFU and keeps sending the
+// codec's frame on it
+func (p *sfuTestPeer) publishFrames(streamID string, frames map[string][]byte) {
+	tracks := make(map[string]*webrtc.TrackLocalStaticSample)
+	for mimeType := range frames {
+		track, err := webrtc.NewTrackLocalStaticSample(
+			webrtc.RTPCodecCapability{MimeType: mimeType},
+			testTrackIDs[mimeType],
+			streamID,
+		)
+		if err != nil {
+			p.t.Fatal(err)
+		}
+		if _, err := p.publisher.AddTrack(track); err != nil {
+			p.t.Fatal(err)
+		}
+		tracks[mimeType] = track
+	}
+
+	offer, err := p.publisher.CreateOffer(nil)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if err := p.publisher.SetLocalDescription(offer); err != nil {
+		p.t.Fatal(err)
+	}
+	p.send("offer", map[string]interface{}{"clientId": sfu.PublisherID, "value": offer})
+
+	done := make(chan struct{})
+	p.t.Cleanup(func() { close(done) })
+	go func() {
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				for mimeType, track := range tracks {
+					track.WriteSample(media.Sample{Data: frames[mimeType], Duration: 20 * time.Millisecond})
+				}
+			}
+		}
+	}()
+}
+
+// pump answers the SFU's negotiation messages until the connection closes
+func (p *sfuTestPeer) pump() {
+	for {
+		var message struct {
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := p.conn.ReadJSON(&message); err != nil {
+			return
+		}
+
+		var signal struct {
+			ClientId string          `json:"clientId"`
+			Value    json.RawMessage `json:"value"`
+		}
+		json.Unmarshal(message.Payload, &signal)
+
+		switch message.Type {
+		case "answer":
+			var answer webrtc.SessionDescription
+			json.Unmarshal(signal.Value, &answer)
+			p.accept(sfu.PublisherID, p.publisher, answer)
+		case "offer":
+			var offer webrtc.SessionDescription
+			json.Unmarshal(signal.Value, &offer)
+			p.accept(sfu.SubscriberID, p.subscriber, offer)
+
+			answer, err := p.subscriber.CreateAnswer(nil)
+			if err != nil {
+				p.t.Log(err)
+				return
+			}
+			p.subscriber.SetLocalDescription(answer)
+			p.send("answer", map[string]interface{}{"clientId": sfu.SubscriberID, "value": answer})
+		case "iceCandidate":
+			var candidate webrtc.ICECandidateInit
+			json.Unmarshal(signal.Value, &candidate)
+
+			connection := p.publisher
+			if signal.ClientId == sfu.SubscriberID {
+				connection = p.subscriber
+			}
+			if connection.RemoteDescription() == nil {
+				p.pending[signal.ClientId] = append(p.pending[signal.ClientId], candidate)
+			} else {
+				connection.AddICECandidate(candidate)
+			}
+		case "trackPublished":
+			var payload map[string]interface{}
+			json.Unmarshal(message.Payload, &payload)
+			p.published <- payload
+		case "layerPreference":
+			var payload map[string]interface{}
+			json.Unmarshal(message.Payload, &payload)
+			p.layers <- payload
+		}
+	}
+}
+
+func (p *sfuTestPeer) accept(target string, connection *webrtc.PeerConnection, description webrtc.SessionDescription) {
+	if err := connection.SetRemoteDescription(description); err != nil {
+		p.t.Log(err)
+		return
+	}
+
+	for _, candidate := range p.pending[target] {
+		connection.AddICECandidate(candidate)
+	}
+	delete(p.pending, target)
+}
+
+func (suite *TestSuite) enableSFU(roomID uint, token string) {
+	w, err := suite.makeRequest("PATCH", fmt.Sprintf("/rooms/%d/settings", roomID), map[string]interface{}{
+		"sfu_enabled": true,
+	}, token)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Equal(true, response["data"].(map[string]interface{})["sfu_enabled"])
+}
+
+// Test: Media published to the SFU is forwarded to the other participants
+func (suite *SFUTestSuite) TestForwardsPublishedTracks() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+	suite.enableSFU(room.ID, ownerToken)
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, init := suite.joinMeeting(ownerToken, room.ID)
+	suite.Equal(true, init["sfu"])
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	suite.readMessage(ownerConn, "client_joined")
+
+	publisher := newSFUTestPeer(suite.T(), ownerConn)
+	subscriber := newSFUTestPeer(suite.T(), memberConn)
+	publisher.publish("owner-camera")
+
+	select {
+	case published := <-subscriber.published:
+		suite.Equal("owner-camera", published["streamId"])
+		suite.Equal("video", published["kind"])
+	case <-time.After(10 * time.Second):
+		suite.FailNow("track was not announced")
+	}
+
+	var track *webrtc.TrackRemote
+	select {
+	case track = <-subscriber.tracks:
+	case <-time.After(10 * time.Second):
+		suite.FailNow("track was not forwarded")
+	}
+	suite.Equal("owner-camera", track.StreamID())
+	suite.Equal(webrtc.MimeTypeVP8, track.Codec().MimeType)
+
+	track.SetReadDeadline(time.Now().Add(5 * time.Second))
+	packet, _, err := track.ReadRTP()
+	suite.Require().NoError(err)
+	suite.NotEmpty(packet.Payload)
+}
+
+// Test: Rooms without the SFU keep relaying signaling between peers
+func (suite *SFUTestSuite) TestMeshRoomsRejectServerSignaling() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	ownerConn, init := suite.joinMeeting(ownerToken, room.ID)
+	suite.NotContains(init, "sfu")
+
+	suite.sendMessage(ownerConn, "offer", map[string]interface{}{
+		"clientId": sfu.PublisherID,
+		"value":    map[string]interface{}{"type": "offer", "sdp": "v=0"},
+	})
+	suite.Equal("sfu", suite.readMessage(ownerConn, "error")["code"])
+}
@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/serozhenka/shary/internal/speaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type SpeakerTestSuite struct {
+	TestSuite
+}
+
+func TestSpeakerTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeakerTestSuite))
+}
+
+// reportInterval is how often the simulated clients send their audio level
+const reportInterval = 100 * time.Millisecond
+
+type speakerChange struct {
+	speaker string
+	after   time.Duration
+}
+
+// speak has the given speakers report their levels for the duration and
+// returns the dominant speaker changes, with when they happened
+func speak(detector *speaker.Detector, start time.Time, duration time.Duration, levels map[string]float64) []speakerChange {
+	var changes []speakerChange
+	for elapsed := time.Duration(0); elapsed < duration; elapsed += reportInterval {
+		for id, level := range levels {
+			if dominant, changed := detector.Update(id, level, start.Add(elapsed)); changed {
+				changes = append(changes, speakerChange{speaker: dominant, after: elapsed})
+			}
+		}
+	}
+	return changes
+}
+
+// Test: The dominant speaker changes only once a challenger is clearly louder for a while
+func (suite *SpeakerTestSuite) TestDetectorHysteresis() {
+	start := time.Now()
+
+	tests := []struct {
+		name    string
+		phases  []map[string]float64 // Each lasts 2 seconds
+		changes []speakerChange
+	}{
+		{
+			name:    "first speaker takes over right away",
+			phases:  []map[string]float64{{"alice": 0.3}},
+			changes: []speakerChange{{"alice", 0}},
+		},
+		{
+			name:   "noise isn't speech",
+			phases: []map[string]float64{{"alice": 0.01, "bob": 0.005}},
+		},
+		{
+			name: "louder challenger takes over after the switch delay",
+			phases: []map[string]float64{
+				{"alice": 0.3},
+				{"alice": 0.1, "bob": 0.5},
+			},
+			changes: []speakerChange{{"alice", 0}, {"bob", 3500 * time.Millisecond}},
+		},
+		{
+			name: "slightly louder challenger doesn't take over",
+			phases: []map[string]float64{
+				{"alice": 0.3},
+				{"alice": 0.3, "bob": 0.35},
+			},
+			changes: []speakerChange{{"alice", 0}},
+		},
+		{
+			name: "silent speaker stays dominant until someone else speaks",
+			phases: []map[string]float64{
+				{"alice": 0.3},
+				{"alice": 0},
+				{"alice": 0, "bob": 0.2},
+			},
+			changes: []speakerChange{{"alice", 0}, {"bob", 5500 * time.Millisecond}},
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			detector := speaker.NewDetector(speaker.DefaultConfig)
+
+			var changes []speakerChange
+			for i, levels := range tt.phases {
+				offset := time.Duration(i) * 2 * time.Second
+				for _, change := range speak(detector, start.Add(offset), 2*time.Second, levels) {
+					changes = append(changes, speakerChange{change.speaker, offset + change.after})
+				}
+			}
+
+			suite.Equal(tt.changes, changes)
+		})
+	}
+}
+
+// Test: Brief interjections don't steal the dominant speaker
+func (suite *SpeakerTestSuite) TestDetectorIgnoresInterjections() {
+	detector := speaker.NewDetector(speaker.DefaultConfig)
+	start := time.Now()
+
+	speak(detector, start, 2*time.Second, map[string]float64{"alice": 0.3})
+	changes := speak(detector, start.Add(2*time.Second), 500*time.Millisecond, map[string]float64{"alice": 0.05, "bob": 0.8})
+	suite.Empty(changes)
+	changes = speak(detector, start.Add(2500*time.Millisecond), 2*time.Second, map[string]float64{"alice": 0.3, "bob": 0})
+	suite.Empty(changes)
+	suite.Equal("alice", detector.Dominant())
+}
+
+// Test: Removing the dominant speaker hands over to the loudest remaining one
+func (suite *SpeakerTestSuite) TestDetectorRemove() {
+	detector := speaker.NewDetector(speaker.DefaultConfig)
+	start := time.Now()
+
+	speak(detector, start, time.Second, map[string]float64{"alice": 0.5})
+	speak(detector, start.Add(time.Second), time.Second, map[string]float64{"alice": 0.5, "bob": 0.2, "carol": 0.1})
+	suite.Equal("alice", detector.Dominant())
+
+	now := start.Add(2 * time.Second)
+	dominant, changed := detector.Remove("carol", now)
+	suite.False(changed)
+	suite.Equal("alice", dominant)
+
+	dominant, changed = detector.Remove("alice", now)
+	suite.True(changed)
+	suite.Equal("bob", dominant)
+
+	dominant, changed = detector.Remove("bob", now)
+	suite.True(changed)
+	suite.Empty(dominant)
+}
+
+// Test: Meetings broadcast the active speaker computed from audio levels
+func (suite *SpeakerTestSuite) TestBroadcastsActiveSpeaker() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, init := suite.joinMeeting(ownerToken, room.ID)
+	suite.NotContains(init, "activeSpeakerId")
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	memberClientID := suite.readMessage(ownerConn, "client_joined")["clientId"]
+
+	suite.sendMessage(memberConn, "audioLevel", map[string]interface{}{"level": 2})
+	suite.Equal("invalid_level", suite.readMessage(memberConn, "error")["code"])
+
+	suite.sendMessage(memberConn, "audioLevel", map[string]interface{}{"level": 0.4})
+	suite.Equal(memberClientID, suite.readMessage(ownerConn, "activeSpeakerChanged")["clientId"])
+	suite.Equal(memberClientID, suite.readMessage(memberConn, "activeSpeakerChanged")["clientId"])
+
+	_, init = suite.joinMeeting(ownerToken, room.ID)
+	suite.Equal(memberClientID, init["activeSpeakerId"])
+
+	memberConn.Close()
+	suite.Equal("", suite.readMessage(ownerConn, "activeSpeakerChanged")["clientId"])
+}
+
+// Test: Levels of muted microphones are ignored until they're unmuted
+func (suite *SpeakerTestSuite) TestIgnoresMutedMicrophones() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	ownerConn, _ := suite.joinMeeting(ownerToken, room.ID)
+	memberConn, _ := suite.joinMeeting(memberToken, room.ID)
+	memberClientID := suite.readMessage(ownerConn, "client_joined")["clientId"]
+
+	suite.sendMessage(memberConn, "trackMuted", map[string]interface{}{"trackKind": "audio"})
+	suite.sendMessage(memberConn, "audioLevel", map[string]interface{}{"level": 0.4})
+	suite.sendMessage(memberConn, "trackUnmuted", map[string]interface{}{"trackKind": "audio"})
+	types, _ := suite.messageTypesUntil(ownerConn, "trackUnmuted")
+	suite.NotContains(types, "activeSpeakerChanged")
+
+	suite.sendMessage(memberConn, "audioLevel", map[string]interface{}{"level": 0.4})
+	suite.Equal(memberClientID, suite.readMessage(ownerConn, "activeSpeakerChanged")["clientId"])
+}
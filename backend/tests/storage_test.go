@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/serozhenka/shary/internal/storage"
+	"github.com/stretchr/testify/suite"
+)
+
+type StorageTestSuite struct {
+	suite.Suite
+}
+
+func TestStorageTestSuite(t *testing.T) {
+	suite.Run(t, new(StorageTestSuite))
+}
+
+// fakeS3 is a minimal stand-in for an S3-compatible server
+type fakeS3 struct {
+	objects map[string][]byte
+	mu      sync.Mutex
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=access/") ||
+		!strings.Contains(auth, "/us-east-1/s3/aws4_request") ||
+		r.Header.Get("X-Amz-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = body
+	case http.MethodGet:
+		body, exists := f.objects[r.URL.Path]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (suite *StorageTestSuite) testBlobStore(store storage.BlobStore) {
+	ctx := context.Background()
+
+	err := store.Put(ctx, "rooms/1/file", strings.NewReader("hello"), 5, "text/plain")
+	suite.Require().NoError(err)
+
+	body, err := store.Get(ctx, "rooms/1/file")
+	suite.Require().NoError(err)
+	content, err := io.ReadAll(body)
+	body.Close()
+	suite.NoError(err)
+	suite.Equal("hello", string(content))
+
+	suite.NoError(store.Delete(ctx, "rooms/1/file"))
+
+	_, err = store.Get(ctx, "rooms/1/file")
+	suite.ErrorIs(err, storage.ErrBlobNotFound)
+}
+
+// Test: The local store round-trips blobs and keeps keys inside its root
+func (suite *StorageTestSuite) TestLocalStore() {
+	store, err := storage.NewLocalStore(suite.T().TempDir())
+	suite.Require().NoError(err)
+
+	suite.testBlobStore(store)
+
+	err = store.Put(context.Background(), "../escape", strings.NewReader("x"), 1, "text/plain")
+	suite.Error(err)
+}
+
+// Test: The S3 store round-trips blobs through an S3-compatible server
+func (suite *StorageTestSuite) TestS3Store() {
+	fake := &fakeS3{objects: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store := storage.NewS3Store(storage.S3Config{
+		Endpoint:  server.URL,
+		Region:    "us-east-1",
+		Bucket:    "shary",
+		AccessKey: "access",
+		SecretKey: "secret",
+	})
+
+	suite.testBlobStore(store)
+
+	// Objects are addressed path-style
+	err := store.Put(context.Background(), "rooms/2/file", strings.NewReader("hi"), 2, "text/plain")
+	suite.Require().NoError(err)
+	suite.Contains(fake.objects, "/shary/rooms/2/file")
+}
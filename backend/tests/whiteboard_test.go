@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/serozhenka/shary/internal/whiteboard"
+	"github.com/stretchr/testify/suite"
+)
+
+type WhiteboardTestSuite struct {
+	TestSuite
+}
+
+func TestWhiteboardTestSuite(t *testing.T) {
+	suite.Run(t, new(WhiteboardTestSuite))
+}
+
+func stroke(elementID string, points ...whiteboard.Point) whiteboard.Op {
+	return whiteboard.Op{Type: whiteboard.OpStroke, ElementId: elementID, Points: points, Color: "#000", Size: 4}
+}
+
+// Test: Boards number ops and keep only the elements still drawn
+func (suite *WhiteboardTestSuite) TestAppliesOps() {
+	board := &whiteboard.Board{}
+
+	op, err := board.Apply(stroke("a", whiteboard.Point{X: 10, Y: 10}, whiteboard.Point{X: 20, Y: 20}))
+	suite.Require().NoError(err)
+	suite.Equal(uint64(1), op.Seq)
+
+	// Fields the type doesn't use are dropped
+	op, err = board.Apply(whiteboard.Op{
+		Type:       whiteboard.OpShape,
+		ElementId:  "b",
+		Shape:      whiteboard.ShapeRect,
+		Points:     []whiteboard.Point{{X: 0, Y: 0}, {X: 100, Y: 50}},
+		Color:      "#ff0000",
+		Fill:       "#00ff00",
+		Size:       2,
+		ElementIds: []string{"a"},
+	})
+	suite.Require().NoError(err)
+	suite.Equal(uint64(2), op.Seq)
+	suite.Nil(op.ElementIds)
+
+	invalid := []whiteboard.Op{
+		{Type: "text"},
+		stroke("a", whiteboard.Point{X: 1, Y: 1}),
+		stroke("c"),
+		stroke("c", whiteboard.Point{X: -1, Y: 1}),
+		stroke("c", whiteboard.Point{X: 1, Y: whiteboard.Height + 1}),
+		{Type: whiteboard.OpStroke, ElementId: "c", Points: []whiteboard.Point{{X: 1, Y: 1}}, Color: "red", Size: 4},
+		{Type: whiteboard.OpStroke, ElementId: "c", Points: []whiteboard.Point{{X: 1, Y: 1}}, Color: "#000", Size: 0},
+		{Type: whiteboard.OpShape, ElementId: "c", Shape: "star", Points: []whiteboard.Point{{X: 1, Y: 1}, {X: 2, Y: 2}}, Color: "#000", Size: 1},
+		{Type: whiteboard.OpShape, ElementId: "c", Shape: whiteboard.ShapeLine, Points: []whiteboard.Point{{X: 1, Y: 1}}, Color: "#000", Size: 1},
+		{Type: whiteboard.OpErase},
+	}
+	for _, op := range invalid {
+		_, err := board.Apply(op)
+		suite.ErrorIs(err, whiteboard.ErrInvalidOp, "%+v", op)
+	}
+
+	op, err = board.Apply(whiteboard.Op{Type: whiteboard.OpErase, ElementIds: []string{"a", "missing"}})
+	suite.Require().NoError(err)
+	suite.Equal(uint64(3), op.Seq)
+
+	snapshot := board.Snapshot()
+	suite.Equal(uint64(3), snapshot.Seq)
+	suite.Require().Len(snapshot.Ops, 1)
+	suite.Equal("b", snapshot.Ops[0].ElementId)
+
+	_, err = board.Apply(whiteboard.Op{Type: whiteboard.OpClear})
+	suite.Require().NoError(err)
+	suite.True(board.Empty())
+	suite.Equal(uint64(4), board.Snapshot().Seq)
+}
+
+// Test: Boards cap the points drawn on them, not only those of each stroke
+func (suite *WhiteboardTestSuite) TestLimitsBoardPoints() {
+	board := &whiteboard.Board{}
+	points := make([]whiteboard.Point, 5000)
+
+	var err error
+	drawn := 0
+	for drawn < 100 {
+		if _, err = board.Apply(stroke(fmt.Sprint(drawn), points...)); err != nil {
+			break
+		}
+		drawn++
+	}
+	suite.ErrorIs(err, whiteboard.ErrBoardFull)
+	suite.Greater(drawn, 1)
+
+	// Erasing makes room again
+	_, err = board.Apply(whiteboard.Op{Type: whiteboard.OpErase, ElementIds: []string{"0"}})
+	suite.Require().NoError(err)
+	_, err = board.Apply(stroke("again", points...))
+	suite.NoError(err)
+
+	_, err = board.Apply(whiteboard.Op{Type: whiteboard.OpClear})
+	suite.Require().NoError(err)
+	for i := range drawn {
+		_, err = board.Apply(stroke(fmt.Sprint("cleared-", i), points...))
+		suite.Require().NoError(err)
+	}
+}
+
+// Test: Snapshots are drawn as SVG
+func (suite *WhiteboardTestSuite) TestDrawsSVG() {
+	board := &whiteboard.Board{}
+	_, err := board.Apply(stroke("a", whiteboard.Point{X: 10, Y: 10}, whiteboard.Point{X: 20.5, Y: 30}))
+	suite.Require().NoError(err)
+	_, err = board.Apply(whiteboard.Op{
+		Type:      whiteboard.OpShape,
+		ElementId: "b",
+		Shape:     whiteboard.ShapeEllipse,
+		Points:    []whiteboard.Point{{X: 100, Y: 100}, {X: 300, Y: 200}},
+		Color:     "#123456",
+		Size:      2,
+	})
+	suite.Require().NoError(err)
+
+	svg := string(board.Snapshot().SVG())
+	suite.Contains(svg, `viewBox="0 0 1920 1080"`)
+	suite.Contains(svg, `<path d="M 10 10 L 20.5 30" fill="none" stroke="#000" stroke-width="4"`)
+	suite.Contains(svg, `<ellipse cx="200" cy="150" rx="100" ry="50" fill="none" stroke="#123456"`)
+}
+
+// Test: Ops are shared with everyone, late joiners get the board and it's
+// exported once the session ends
+func (suite *WhiteboardTestSuite) TestSharesAndExportsBoard() {
+	owner := suite.createTestUser("owner", "owner@example.com", "password123")
+	ownerToken := suite.loginTestUser(owner.Email, "password123")
+	room := suite.createTestRoom(owner.ID, "Test Room")
+
+	member := suite.createTestUser("member", "member@example.com", "password123")
+	memberToken := suite.loginTestUser(member.Email, "password123")
+	suite.addUserToRoom(room.ID, member.Email)
+
+	outsider := suite.createTestUser("outsider", "outsider@example.com", "password123")
+	outsiderToken := suite.loginTestUser(outsider.Email, "password123")
+
+	ownerConn, init := suite.joinMeeting(ownerToken, room.ID)
+	suite.Equal(float64(0), init["whiteboard"].(map[string]interface{})["seq"])
+
+	suite.sendMessage(ownerConn, "wbOp", map[string]interface{}{"type": "stroke", "elementId": "a"})
+	suite.Equal("invalid_op", suite.readMessage(ownerConn, "error")["code"])
+
+	suite.sendMessage(ownerConn, "wbOp", stroke("a", whiteboard.Point{X: 10, Y: 10}, whiteboard.Point{X: 20, Y: 20}))
+	op := suite.readMessage(ownerConn, "wbOp")
+	suite.Equal(float64(1), op["seq"])
+	suite.Equal("a", op["elementId"])
+	suite.NotEmpty(op["clientId"])
+
+	suite.sendMessage(ownerConn, "wbOp", stroke("b", whiteboard.Point{X: 50, Y: 50}))
+	suite.readMessage(ownerConn, "wbOp")
+	suite.sendMessage(ownerConn, "wbOp", map[string]interface{}{"type": "erase", "elementIds": []string{"b"}})
+	suite.Equal(float64(3), suite.readMessage(ownerConn, "wbOp")["seq"])
+
+	memberConn, init := suite.joinMeeting(memberToken, room.ID)
+	snapshot := init["whiteboard"].(map[string]interface{})
+	suite.Equal(float64(3), snapshot["seq"])
+	suite.Require().Len(snapshot["ops"], 1)
+	suite.Equal("a", snapshot["ops"].([]interface{})[0].(map[string]interface{})["elementId"])
+	suite.readMessage(ownerConn, "client_joined")
+
+	suite.sendMessage(memberConn, "wbOp", map[string]interface{}{
+		"type":      "shape",
+		"elementId": "c",
+		"shape":     "rect",
+		"points":    []map[string]interface{}{{"x": 100, "y": 100}, {"x": 200, "y": 150}},
+		"color":     "#ff0000",
+		"size":      2,
+	})
+	suite.Equal(float64(4), suite.readMessage(ownerConn, "wbOp")["seq"])
+	suite.Equal(float64(4), suite.readMessage(memberConn, "wbOp")["seq"])
+
+	memberConn.Close()
+	suite.readMessage(ownerConn, "client_left")
+	ownerConn.Close()
+
+	var whiteboards []map[string]interface{}
+	suite.Eventually(func() bool {
+		w, err := suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/sessions", room.ID), nil, memberToken)
+		suite.Require().NoError(err)
+
+		var response struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+		if len(response.Data) != 1 || response.Data[0]["ended_at"] == nil {
+			return false
+		}
+
+		w, err = suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/sessions/%v/whiteboards", room.ID, response.Data[0]["id"]), nil, memberToken)
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, w.Code)
+
+		var boards struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &boards))
+		whiteboards = boards.Data
+		return len(whiteboards) == 1
+	}, 2*time.Second, 20*time.Millisecond)
+
+	suite.Equal("Test Room", whiteboards[0]["name"])
+	suite.Equal(float64(2), whiteboards[0]["elements"])
+	suite.NotContains(whiteboards[0], "snapshot")
+
+	exportURL := fmt.Sprintf("/rooms/%d/whiteboards/%v.svg", room.ID, whiteboards[0]["id"])
+	w, err := suite.makeRequest("GET", exportURL, nil, memberToken)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Header().Get("Content-Type"), "image/svg+xml")
+	suite.Contains(w.Body.String(), `<path d="M 10 10 L 20 20"`)
+	suite.Contains(w.Body.String(), `<rect x="100" y="100" width="100" height="50"`)
+
+	w, err = suite.makeRequest("GET", fmt.Sprintf("/rooms/%d/whiteboards/%v", room.ID, whiteboards[0]["id"]), nil, memberToken)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, w.Code)
+
+	w, err = suite.makeRequest("GET", exportURL, nil, outsiderToken)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, w.Code)
+}
@@ -20,6 +20,7 @@ import {
   OutboundScreenShareStartedMessage,
   OutboundScreenShareStoppedMessage,
   OutboundTrackMutedMessage,
+  OutboundTrackUnmutedMessage,
 } from "../messages/outbound";
 import { RoomModel } from "../models/RoomModel";
 import { ChatMessage, Peer } from "../peer";
@@ -381,6 +382,15 @@ function RoomPage() {
                 });
               }
 
+              const unmutedMessage: OutboundTrackUnmutedMessage = {
+                type: "trackUnmuted",
+                payload: { trackKind: "video" },
+              };
+
+              if (wsRef.current) {
+                wsRef.current.send(JSON.stringify(unmutedMessage));
+              }
+
               setVideoEnabled(true);
             }
           })
@@ -451,6 +461,12 @@ function RoomPage() {
                 localStreamRef.current.id,
                 "media"
               );
+
+              const unmutedMessage: OutboundTrackUnmutedMessage = {
+                type: "trackUnmuted",
+                payload: { trackKind: "audio" },
+              };
+              wsRef.current.send(JSON.stringify(unmutedMessage));
             }
           })
           .catch((err) => {